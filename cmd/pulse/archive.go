@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pulse/pm/internal/archive"
+	"github.com/pulse/pm/internal/db"
+	"github.com/pulse/pm/internal/importer"
+	"github.com/spf13/cobra"
+)
+
+func createExportCmd() *cobra.Command {
+	var dataDir string
+	var databaseURL string
+	var workspaceID string
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export a workspace to a portable JSON archive",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			database, err := db.Open(dataDir, databaseURL)
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			a, err := archive.Export(database, workspaceID)
+			if err != nil {
+				return err
+			}
+
+			var w io.Writer = os.Stdout
+			if output != "" && output != "-" {
+				f, err := os.Create(output)
+				if err != nil {
+					return fmt.Errorf("failed to create %s: %w", output, err)
+				}
+				defer f.Close()
+				w = f
+			}
+
+			if err := archive.Write(w, a); err != nil {
+				return fmt.Errorf("failed to write archive: %w", err)
+			}
+
+			if w != os.Stdout {
+				fmt.Fprintf(os.Stderr, "Exported %d issues, %d cycles, %d comments to %s\n",
+					len(a.Issues), len(a.Cycles), len(a.Comments), output)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dataDir, "data-dir", "./.pulse-data", "Data directory")
+	cmd.Flags().StringVar(&databaseURL, "database-url", os.Getenv("PULSE_DATABASE_URL"), "PostgreSQL URL (default: SQLite in --data-dir)")
+	cmd.Flags().StringVar(&workspaceID, "workspace", "default", "Workspace to export")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Archive file to write (default: stdout)")
+
+	return cmd
+}
+
+func createImportCmd() *cobra.Command {
+	var dataDir string
+	var databaseURL string
+	var opts archive.ImportOptions
+	var from string
+	var workspaceID string
+	var statusMapFile string
+	var statusMappings []string
+
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import a workspace archive or another tracker's export",
+		Long: `Import a workspace archive produced by "pulse export".
+
+By default the archive's IDs are kept and the import fails if they already
+exist. Use --remap-ids to assign fresh IDs, and --into to merge the
+archive into an existing workspace.
+
+With --from, import an export from another tracker instead:
+
+  linear       Linear CSV export
+  jira-csv     Jira "Export CSV (all fields)"
+  github-json  GitHub REST API or "gh issue list --json" output
+
+Statuses are mapped through a built-in table per source; override entries
+with --status "In QA=in_progress" or a JSON --status-map file. Imports are
+keyed on the external issue IDs, so re-running one updates existing issues.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			database, err := db.Open(dataDir, databaseURL)
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			if err := database.Migrate(); err != nil {
+				return fmt.Errorf("failed to run migrations: %w", err)
+			}
+
+			if from != "" {
+				return runTrackerImport(database, f, from, workspaceID, statusMapFile, statusMappings)
+			}
+
+			a, err := archive.Read(f)
+			if err != nil {
+				return err
+			}
+
+			result, err := archive.Import(database, a, opts)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Imported into workspace %s: %d issues, %d cycles, %d capacities, %d projects, %d milestones, %d relations, %d comments, %d events\n",
+				result.WorkspaceID, result.Issues, result.Cycles, result.Capacities, result.Projects, result.Milestones, result.Relations, result.Comments, result.Events)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dataDir, "data-dir", "./.pulse-data", "Data directory")
+	cmd.Flags().StringVar(&databaseURL, "database-url", os.Getenv("PULSE_DATABASE_URL"), "PostgreSQL URL (default: SQLite in --data-dir)")
+	cmd.Flags().BoolVar(&opts.RemapIDs, "remap-ids", false, "Assign fresh IDs to all imported entities")
+	cmd.Flags().StringVar(&opts.TargetWorkspaceID, "into", "", "Merge into an existing workspace instead of creating one")
+	cmd.Flags().StringVar(&from, "from", "", "Import another tracker's export: "+strings.Join(importer.Names(), ", "))
+	cmd.Flags().StringVar(&workspaceID, "workspace", "default", "Workspace to import tracker exports into")
+	cmd.Flags().StringVar(&statusMapFile, "status-map", "", "JSON file mapping source statuses to Pulse statuses")
+	cmd.Flags().StringArrayVar(&statusMappings, "status", nil, "Status mapping override as \"Source Status=pulse_status\" (repeatable)")
+
+	return cmd
+}
+
+func runTrackerImport(database *db.DB, r io.Reader, from, workspaceID, statusMapFile string, statusMappings []string) error {
+	src, err := importer.Lookup(from)
+	if err != nil {
+		return err
+	}
+
+	statusMap := make(map[string]string)
+	if statusMapFile != "" {
+		statusMap, err = importer.LoadStatusMap(statusMapFile)
+		if err != nil {
+			return err
+		}
+	}
+	for _, m := range statusMappings {
+		from, to, ok := strings.Cut(m, "=")
+		if !ok {
+			return fmt.Errorf("invalid --status %q: expected \"Source Status=pulse_status\"", m)
+		}
+		statusMap[strings.TrimSpace(from)] = strings.TrimSpace(to)
+	}
+
+	records, err := src.Parse(r)
+	if err != nil {
+		return err
+	}
+
+	result, err := importer.Run(database, src, records, importer.Options{
+		WorkspaceID: workspaceID,
+		StatusMap:   statusMap,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, w := range result.Warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
+	fmt.Printf("Imported %d records from %s: %d created, %d updated, %d cycles, %d users\n",
+		len(records), from, result.Created, result.Updated, result.Cycles, result.Users)
+	return nil
+}
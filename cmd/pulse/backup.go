@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/pulse/pm/internal/db"
+	"github.com/spf13/cobra"
+)
+
+func createBackupCmd() *cobra.Command {
+	var dataDir string
+	var output string
+	var retain int
+
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Back up the database while the server is running",
+		Long: `Write a consistent, integrity-checked copy of the SQLite database.
+
+Without --output the backup is stored as a timestamped snapshot in
+<data-dir>/snapshots and old snapshots beyond --retain are pruned.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			database, err := db.New(dataDir)
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			var info *db.BackupInfo
+			if output != "" {
+				info, err = database.Backup(output)
+			} else {
+				info, err = database.Snapshot(filepath.Join(dataDir, "snapshots"), retain)
+			}
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Backup written to %s (%d bytes, integrity ok)\n", info.Path, info.Size)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dataDir, "data-dir", "./.pulse-data", "Data directory")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Backup file to write")
+	cmd.Flags().IntVar(&retain, "retain", 7, "Number of snapshots to keep; 0 keeps all")
+
+	return cmd
+}
+
+func createRestoreCmd() *cobra.Command {
+	var dataDir string
+
+	cmd := &cobra.Command{
+		Use:   "restore <backup-file>",
+		Short: "Restore the database from a backup",
+		Long: `Verify a backup with PRAGMA integrity_check and copy it over the
+database in --data-dir using SQLite's online backup API.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			database, err := db.New(dataDir)
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			if err := database.Restore(args[0]); err != nil {
+				return err
+			}
+			if err := database.Migrate(); err != nil {
+				return fmt.Errorf("failed to migrate restored database: %w", err)
+			}
+
+			fmt.Printf("Restored %s from %s\n", database.Path(), args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dataDir, "data-dir", "./.pulse-data", "Data directory")
+
+	return cmd
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/pulse/pm/internal/git"
+	"github.com/spf13/cobra"
+)
+
+func createGitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "git",
+		Short: "Integrate the local git repository with Pulse",
+	}
+
+	hookCmd := &cobra.Command{
+		Use:   "hook",
+		Short: "Manage the prepare-commit-msg hook",
+		Long: `Manage the prepare-commit-msg hook. On branches named after an issue,
+such as fix/pul-42-login, the hook adds an "Issue: PUL-42" trailer to each
+commit message.`,
+	}
+
+	var force bool
+	installCmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install the hook in the current repository",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := git.InstallHook(".", force)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Installed %s\n", path)
+			return nil
+		},
+	}
+	installCmd.Flags().BoolVar(&force, "force", false, "Replace an existing prepare-commit-msg hook")
+
+	uninstallCmd := &cobra.Command{
+		Use:   "uninstall",
+		Short: "Remove the hook from the current repository",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := git.UninstallHook(".")
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Removed %s\n", path)
+			return nil
+		},
+	}
+
+	hookCmd.AddCommand(installCmd, uninstallCmd)
+	cmd.AddCommand(hookCmd)
+	return cmd
+}
@@ -0,0 +1,547 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pulse/pm/internal/client"
+	"github.com/pulse/pm/internal/db"
+	"github.com/pulse/pm/internal/git"
+	"github.com/pulse/pm/internal/report"
+	"github.com/spf13/cobra"
+)
+
+// clientFlags are the connection flags shared by the client commands.
+// Unset flags fall back to the environment and then the config file.
+type clientFlags struct {
+	server    string
+	token     string
+	workspace string
+	output    string
+	columns   string
+}
+
+func (f *clientFlags) register(cmd *cobra.Command) {
+	f.registerConnection(cmd)
+	cmd.PersistentFlags().StringVarP(&f.output, "output", "o", report.FormatTable, "Output format: table, csv, md or json")
+	cmd.PersistentFlags().StringVar(&f.columns, "columns", "", "Comma separated columns for table, csv and md output")
+}
+
+// registerConnection adds only the server, token and workspace flags.
+func (f *clientFlags) registerConnection(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&f.server, "server", "", "Pulse server URL (env PULSE_SERVER, default "+client.DefaultServer+")")
+	cmd.PersistentFlags().StringVar(&f.token, "token", "", "API token sent as a bearer token (env PULSE_TOKEN)")
+	cmd.PersistentFlags().StringVarP(&f.workspace, "workspace", "w", "", "Workspace ID (env PULSE_WORKSPACE, default \"default\")")
+}
+
+// connect resolves the client config and returns a client for it.
+func (f *clientFlags) connect() (*client.Client, *client.Config, error) {
+	cfg, err := client.LoadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	if f.server != "" {
+		cfg.Server = f.server
+	}
+	if f.token != "" {
+		cfg.Token = f.token
+	}
+	if f.workspace != "" {
+		cfg.Workspace = f.workspace
+	}
+	return client.New(cfg), cfg, nil
+}
+
+// printIssues writes issues in the selected output format.
+func (f *clientFlags) printIssues(issues []*db.Issue) error {
+	if f.output == report.FormatJSON {
+		if issues == nil {
+			issues = []*db.Issue{}
+		}
+		return writeJSON(os.Stdout, issues)
+	}
+	columns, err := checkReportFlags(f.output, f.columns)
+	if err != nil {
+		return err
+	}
+	return report.Write(os.Stdout, f.output, report.IssueTable(issues, columns))
+}
+
+// printIssue writes a single issue, as JSON or as a short summary line.
+func (f *clientFlags) printIssue(issue *db.Issue) error {
+	if f.output == report.FormatJSON {
+		return writeJSON(os.Stdout, issue)
+	}
+	fmt.Printf("%s  %s  [%s, %s]\n", issue.Key, issue.Title, issue.Status, db.PriorityName(issue.Priority))
+	return nil
+}
+
+// resolveAssignee expands "me" to the configured user.
+func resolveAssignee(assignee string, cfg *client.Config) (string, error) {
+	if assignee != "me" {
+		return assignee, nil
+	}
+	if cfg.User == "" {
+		return "", fmt.Errorf(`--assignee=me needs a user: set PULSE_USER or "user" in %s`, client.ConfigPath())
+	}
+	return cfg.User, nil
+}
+
+func createIssuesCmd() *cobra.Command {
+	flags := &clientFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "issues",
+		Short: "Manage issues on a running Pulse server",
+		Long: `Manage issues on a running Pulse server through its REST API.
+
+The server, token, workspace and user come from flags, then the
+PULSE_SERVER, PULSE_TOKEN, PULSE_WORKSPACE and PULSE_USER environment
+variables, then the JSON config file at ` + client.ConfigPath() + ` (or
+$PULSE_CONFIG), for example:
+
+  {"server": "http://pulse.internal:3002", "workspace": "default", "user": "user_42"}`,
+	}
+	flags.register(cmd)
+
+	cmd.AddCommand(
+		createIssuesCreateCmd(flags),
+		createIssuesListCmd(flags),
+		createIssuesShowCmd(flags),
+		createIssuesUpdateCmd(flags),
+		createIssuesMoveCmd(flags),
+		createIssuesCommentCmd(flags),
+		createIssuesDeleteCmd(flags),
+		createIssuesStartCmd(flags),
+	)
+	return cmd
+}
+
+func createIssuesCreateCmd(flags *clientFlags) *cobra.Command {
+	var issue db.Issue
+	var priority, start, due string
+	var linkBranch bool
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create an issue",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, cfg, err := flags.connect()
+			if err != nil {
+				return err
+			}
+
+			if strings.TrimSpace(issue.Title) == "" {
+				return fmt.Errorf("--title is required")
+			}
+			if issue.Status != "" && !db.ValidStatus(issue.Status) {
+				return fmt.Errorf("unknown status %q (use %s)", issue.Status, strings.Join(db.Statuses, ", "))
+			}
+			if issue.Priority, err = db.ParsePriority(priority); err != nil {
+				return err
+			}
+			if issue.AssigneeID, err = resolveAssignee(issue.AssigneeID, cfg); err != nil {
+				return err
+			}
+			if issue.StartDate, err = parseDay("--start", start); err != nil {
+				return err
+			}
+			if issue.DueDate, err = parseDay("--due", due); err != nil {
+				return err
+			}
+			if linkBranch {
+				if issue.Branch, err = git.CurrentBranch("."); err != nil {
+					return err
+				}
+			}
+			issue.WorkspaceID = cfg.Workspace
+
+			created, err := c.CreateIssue(&issue)
+			if err != nil {
+				return err
+			}
+			return flags.printIssue(created)
+		},
+	}
+
+	cmd.Flags().StringVar(&issue.Title, "title", "", "Issue title")
+	cmd.Flags().StringVar(&issue.Description, "description", "", "Issue description")
+	cmd.Flags().StringVar(&issue.Status, "status", "", "Initial status (default backlog)")
+	cmd.Flags().StringVar(&priority, "priority", "none", "Priority: urgent, high, medium, low or none")
+	cmd.Flags().StringVar(&issue.AssigneeID, "assignee", "", `Assignee ID, or "me"`)
+	cmd.Flags().StringSliceVar(&issue.Labels, "labels", nil, "Comma separated labels")
+	cmd.Flags().IntVar(&issue.Estimate, "estimate", 0, "Estimate in points")
+	cmd.Flags().StringVar(&issue.CycleID, "cycle", "", "Cycle ID")
+	cmd.Flags().StringVar(&issue.ParentID, "parent", "", "Parent issue ID")
+	cmd.Flags().StringVar(&issue.ProjectID, "project", "", "Project ID")
+	cmd.Flags().StringVar(&issue.MilestoneID, "milestone", "", "Milestone ID; also sets the project")
+	cmd.Flags().StringVar(&start, "start", "", "Start date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&due, "due", "", "Due date (YYYY-MM-DD)")
+	cmd.Flags().BoolVar(&linkBranch, "link-branch", false, "Link the issue to the current git branch")
+
+	return cmd
+}
+
+func createIssuesListCmd(flags *clientFlags) *cobra.Command {
+	var filter client.IssueFilter
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List issues",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, cfg, err := flags.connect()
+			if err != nil {
+				return err
+			}
+
+			if filter.AssigneeID, err = resolveAssignee(filter.AssigneeID, cfg); err != nil {
+				return err
+			}
+			filter.WorkspaceID = cfg.Workspace
+
+			issues, err := c.ListIssues(filter)
+			if err != nil {
+				return err
+			}
+			return flags.printIssues(issues)
+		},
+	}
+
+	cmd.Flags().StringVar(&filter.Status, "status", "", "Only issues with this status")
+	cmd.Flags().StringVar(&filter.AssigneeID, "assignee", "", `Only issues assigned to this ID, or "me"`)
+	cmd.Flags().StringVar(&filter.Label, "label", "", "Only issues with this label")
+	cmd.Flags().IntVar(&filter.Limit, "limit", 0, "Maximum number of issues to show")
+
+	return cmd
+}
+
+func createIssuesShowCmd(flags *clientFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <id>",
+		Short: "Show an issue and its comments",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, _, err := flags.connect()
+			if err != nil {
+				return err
+			}
+
+			issue, err := c.GetIssue(args[0])
+			if err != nil {
+				return err
+			}
+			comments, err := c.ListComments(issue.ID)
+			if err != nil {
+				return err
+			}
+			tree, err := c.IssueTree(issue.ID, 1)
+			if err != nil {
+				return err
+			}
+
+			if flags.output == report.FormatJSON {
+				return writeJSON(os.Stdout, struct {
+					*db.IssueNode
+					Comments []*db.Comment `json:"comments"`
+				}{tree, comments})
+			}
+
+			fmt.Printf("%s  %s\n%s\n\n", issue.Key, issue.ID, issue.Title)
+			t := &report.Table{
+				Headers: []string{"Field", "Value"},
+				Rows: [][]string{
+					{"Status", issue.Status},
+					{"Priority", db.PriorityName(issue.Priority)},
+					{"Assignee", issue.AssigneeID},
+					{"Estimate", fmt.Sprintf("%d", issue.Estimate)},
+					{"Labels", strings.Join(issue.Labels, ", ")},
+					{"Cycle", issue.CycleID},
+					{"Parent", issue.ParentID},
+					{"Project", issue.ProjectID},
+					{"Milestone", issue.MilestoneID},
+					{"Start", formatDay(issue.StartDate)},
+					{"Due", formatDay(issue.DueDate)},
+					{"Branch", issue.Branch},
+					{"Created", issue.CreatedAt.Local().Format("2006-01-02 15:04")},
+					{"Updated", issue.UpdatedAt.Local().Format("2006-01-02 15:04")},
+				},
+			}
+			if err := report.WriteText(os.Stdout, t); err != nil {
+				return err
+			}
+			if issue.Description != "" {
+				fmt.Printf("\n%s\n", issue.Description)
+			}
+			if len(tree.Children) > 0 {
+				r := tree.Rollup
+				fmt.Printf("\nSub-issues: %d of %d done, %d/%d points (%.0f%%)\n",
+					r.Completed, r.Descendants, r.CompletedEstimate, r.Estimate, r.Progress)
+				for _, child := range tree.Children {
+					fmt.Printf("  %-9s %-12s %s\n", child.Key, child.Status, child.Title)
+				}
+			}
+			for _, comment := range comments {
+				author := comment.AuthorID
+				if author == "" {
+					author = "anonymous"
+				}
+				fmt.Printf("\n--- %s, %s\n%s\n", author, comment.CreatedAt.Local().Format("2006-01-02 15:04"), comment.Body)
+			}
+			return nil
+		},
+	}
+}
+
+func createIssuesUpdateCmd(flags *clientFlags) *cobra.Command {
+	var title, description, status, priority, assignee, cycle, parent, project, milestone, start, due string
+	var labels []string
+	var estimate int
+
+	cmd := &cobra.Command{
+		Use:   "update <id>",
+		Short: "Change the fields of an issue",
+		Long:  "Change the fields of an issue. Only the flags given are updated.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, cfg, err := flags.connect()
+			if err != nil {
+				return err
+			}
+
+			fields := make(map[string]interface{})
+			set := cmd.Flags().Changed
+			if set("title") {
+				fields["title"] = title
+			}
+			if set("description") {
+				fields["description"] = description
+			}
+			if set("status") {
+				if !db.ValidStatus(status) {
+					return fmt.Errorf("unknown status %q (use %s)", status, strings.Join(db.Statuses, ", "))
+				}
+				fields["status"] = status
+			}
+			if set("priority") {
+				p, err := db.ParsePriority(priority)
+				if err != nil {
+					return err
+				}
+				fields["priority"] = p
+			}
+			if set("assignee") {
+				if fields["assignee_id"], err = resolveAssignee(assignee, cfg); err != nil {
+					return err
+				}
+			}
+			if set("labels") {
+				fields["labels"] = labels
+			}
+			if set("estimate") {
+				fields["estimate"] = estimate
+			}
+			if set("cycle") {
+				fields["cycle_id"] = cycle
+			}
+			if set("parent") {
+				fields["parent_id"] = parent
+			}
+			if set("project") {
+				fields["project_id"] = project
+			}
+			if set("milestone") {
+				fields["milestone_id"] = milestone
+			}
+			if set("start") {
+				if _, err := parseDay("--start", start); err != nil {
+					return err
+				}
+				fields["start_date"] = start
+			}
+			if set("due") {
+				if _, err := parseDay("--due", due); err != nil {
+					return err
+				}
+				fields["due_date"] = due
+			}
+			if len(fields) == 0 {
+				return fmt.Errorf("nothing to update: pass at least one field flag")
+			}
+
+			issue, err := c.UpdateIssue(args[0], fields)
+			if err != nil {
+				return err
+			}
+			return flags.printIssue(issue)
+		},
+	}
+
+	cmd.Flags().StringVar(&title, "title", "", "New title")
+	cmd.Flags().StringVar(&description, "description", "", "New description")
+	cmd.Flags().StringVar(&status, "status", "", "New status")
+	cmd.Flags().StringVar(&priority, "priority", "", "New priority: urgent, high, medium, low or none")
+	cmd.Flags().StringVar(&assignee, "assignee", "", `New assignee ID, "me", or "" to unassign`)
+	cmd.Flags().StringSliceVar(&labels, "labels", nil, "Replace the labels")
+	cmd.Flags().IntVar(&estimate, "estimate", 0, "New estimate in points")
+	cmd.Flags().StringVar(&cycle, "cycle", "", `New cycle ID, or "" to remove from its cycle`)
+	cmd.Flags().StringVar(&parent, "parent", "", `New parent issue ID, or "" for none`)
+	cmd.Flags().StringVar(&project, "project", "", `New project ID, or "" for none`)
+	cmd.Flags().StringVar(&milestone, "milestone", "", `New milestone ID, or "" for none`)
+	cmd.Flags().StringVar(&start, "start", "", `New start date (YYYY-MM-DD), or "" to clear`)
+	cmd.Flags().StringVar(&due, "due", "", `New due date (YYYY-MM-DD), or "" to clear`)
+
+	return cmd
+}
+
+func createIssuesMoveCmd(flags *clientFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "move <id> <status>",
+		Short: "Move an issue to another status",
+		Long:  "Move an issue to another status: " + strings.Join(db.Statuses, ", ") + ".",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !db.ValidStatus(args[1]) {
+				return fmt.Errorf("unknown status %q (use %s)", args[1], strings.Join(db.Statuses, ", "))
+			}
+
+			c, _, err := flags.connect()
+			if err != nil {
+				return err
+			}
+
+			issue, err := c.MoveIssue(args[0], args[1])
+			if err != nil {
+				return err
+			}
+			return flags.printIssue(issue)
+		},
+	}
+}
+
+func createIssuesCommentCmd(flags *clientFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "comment <id> <text>...",
+		Short: "Comment on an issue",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, cfg, err := flags.connect()
+			if err != nil {
+				return err
+			}
+
+			comment, err := c.AddComment(args[0], cfg.User, strings.Join(args[1:], " "))
+			if err != nil {
+				return err
+			}
+			if flags.output == report.FormatJSON {
+				return writeJSON(os.Stdout, comment)
+			}
+			fmt.Printf("Commented on %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+func createIssuesDeleteCmd(flags *clientFlags) *cobra.Command {
+	var cascade bool
+
+	cmd := &cobra.Command{
+		Use:   "delete <id>",
+		Short: "Delete an issue with its comments and relations",
+		Long: `Delete an issue with its comments and relations. Its sub-issues move up
+to the deleted issue's parent unless --cascade deletes them as well.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, _, err := flags.connect()
+			if err != nil {
+				return err
+			}
+
+			if err := c.DeleteIssue(args[0], cascade); err != nil {
+				return err
+			}
+			fmt.Printf("Deleted %s\n", args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&cascade, "cascade", false, "Also delete all sub-issues")
+	return cmd
+}
+
+func createIssuesStartCmd(flags *clientFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "start <key>",
+		Short: "Start work on an issue in a new git branch",
+		Long: `Start work on an issue: check out its branch in the local repository,
+creating one such as fix/pul-42-login-fails-on-mobile if the issue has none,
+then move the issue to in_progress, assign it to you and record the branch.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, cfg, err := flags.connect()
+			if err != nil {
+				return err
+			}
+			// Check for a user before switching branches, so a missing
+			// one leaves the working tree as it was.
+			user, err := resolveAssignee("me", cfg)
+			if err != nil {
+				return err
+			}
+
+			issue, err := c.GetIssue(args[0])
+			if err != nil {
+				return err
+			}
+
+			branch := issue.Branch
+			if branch == "" {
+				branch = git.BranchName(issue.Key, issue.Title, issue.Labels)
+			}
+			created, err := git.Checkout(".", branch)
+			if err != nil {
+				return err
+			}
+
+			fields := map[string]interface{}{"branch": branch, "assignee_id": user}
+			if issue.Status != "in_progress" {
+				fields["status"] = "in_progress"
+			}
+
+			issue, err = c.UpdateIssue(issue.ID, fields)
+			if err != nil {
+				return err
+			}
+
+			verb := "Switched to"
+			if created {
+				verb = "Created"
+			}
+			fmt.Printf("%s branch %s\n", verb, branch)
+			return flags.printIssue(issue)
+		},
+	}
+}
+
+// parseDay parses an optional YYYY-MM-DD flag value.
+func parseDay(flag, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid date %q (use YYYY-MM-DD)", flag, value)
+	}
+	return &t, nil
+}
+
+func formatDay(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02")
+}
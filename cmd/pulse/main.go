@@ -3,12 +3,16 @@ package main
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/pulse/pm/internal/server"
+	"github.com/pulse/pm/internal/telemetry"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
@@ -32,12 +36,40 @@ inspired by Linear. Manage issues, cycles, and team velocity.`,
 
 	var addr string
 	var dataDir string
+	var databaseURL string
+	var snapshotInterval time.Duration
+	var snapshotRetain int
+	var breachCheckInterval time.Duration
+	var autoCloseInterval time.Duration
+	var logLevel, logFormat string
+	var otlpEndpoint string
+	var adminToken string
 
 	startCmd := &cobra.Command{
 		Use:   "start",
 		Short: "Start the Pulse server",
 		Long:  `Start the Pulse web server for project management.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			logger, err := newLogger(logLevel, logFormat)
+			if err != nil {
+				return err
+			}
+			slog.SetDefault(logger)
+
+			var tracerProvider trace.TracerProvider
+			if otlpEndpoint != "" {
+				tp, err := telemetry.NewTracerProvider(context.Background(), "pulse", otlpEndpoint, logger)
+				if err != nil {
+					return err
+				}
+				defer func() {
+					shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+					defer cancel()
+					tp.Shutdown(shutdownCtx)
+				}()
+				tracerProvider = tp
+			}
+
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
 
@@ -50,7 +82,19 @@ inspired by Linear. Manage issues, cycles, and team velocity.`,
 			}()
 
 			// Start Pulse server with SQLite persistence
-			pulseServer, err := server.NewServer(addr, dataDir)
+			pulseServer, err := server.NewServer(server.Config{
+				Addr:             addr,
+				DataDir:          dataDir,
+				DatabaseURL:      databaseURL,
+				SnapshotInterval: snapshotInterval,
+				SnapshotRetain:   snapshotRetain,
+				AdminToken:       adminToken,
+
+				BreachCheckInterval: breachCheckInterval,
+				AutoCloseInterval:   autoCloseInterval,
+				Logger:              logger,
+				TracerProvider:      tracerProvider,
+			})
 			if err != nil {
 				return fmt.Errorf("failed to create pulse server: %w", err)
 			}
@@ -66,9 +110,28 @@ inspired by Linear. Manage issues, cycles, and team velocity.`,
 
 	startCmd.Flags().StringVar(&addr, "addr", "localhost:3002", "Address to listen on")
 	startCmd.Flags().StringVar(&dataDir, "data-dir", "./.pulse-data", "Data directory")
+	startCmd.Flags().StringVar(&databaseURL, "database-url", os.Getenv("PULSE_DATABASE_URL"), "PostgreSQL URL (default: SQLite in --data-dir)")
+	startCmd.Flags().DurationVar(&snapshotInterval, "snapshot-interval", 0, "Take a database snapshot at this interval (e.g. 6h); 0 disables")
+	startCmd.Flags().IntVar(&snapshotRetain, "snapshot-retain", 7, "Number of snapshots to keep")
+	startCmd.Flags().StringVar(&adminToken, "admin-token", os.Getenv("PULSE_ADMIN_TOKEN"), "Serve the /api/admin backup and restore routes to requests bearing this token; empty disables them")
+	startCmd.Flags().DurationVar(&breachCheckInterval, "breach-check-interval", server.DefaultBreachCheckInterval, "How often to record missed due dates and SLAs; negative disables")
+	startCmd.Flags().DurationVar(&autoCloseInterval, "auto-close-interval", server.DefaultAutoCloseInterval, "How often to cancel stale backlog issues in workspaces that set autoCloseDays; negative disables")
+	startCmd.Flags().StringVar(&logLevel, "log-level", "info", "Log level: debug, info, warn or error")
+	startCmd.Flags().StringVar(&logFormat, "log-format", "text", "Log format: text or json")
+	startCmd.Flags().StringVar(&otlpEndpoint, "otlp-endpoint", os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"), "Export traces to this OTLP/HTTP collector (e.g. http://localhost:4318); empty disables tracing")
 
 	rootCmd.AddCommand(startCmd)
 	rootCmd.AddCommand(createVersionCmd())
+	rootCmd.AddCommand(createMigrateCmd())
+	rootCmd.AddCommand(createBackupCmd())
+	rootCmd.AddCommand(createRestoreCmd())
+	rootCmd.AddCommand(createExportCmd())
+	rootCmd.AddCommand(createImportCmd())
+	rootCmd.AddCommand(createReportCmd())
+	rootCmd.AddCommand(createIssuesCmd())
+	rootCmd.AddCommand(createMetricsCmd())
+	rootCmd.AddCommand(createTUICmd())
+	rootCmd.AddCommand(createGitCmd())
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
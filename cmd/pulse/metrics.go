@@ -0,0 +1,336 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pulse/pm/internal/analytics"
+	"github.com/pulse/pm/internal/db"
+	"github.com/pulse/pm/internal/report"
+	"github.com/spf13/cobra"
+)
+
+// metricsFlags are shared by the metrics subcommands.
+type metricsFlags struct {
+	dataDir     string
+	databaseURL string
+	workspaceID string
+	json        bool
+}
+
+// open opens the database and brings its schema up to date, since the
+// metrics read history and columns that older data dirs lack.
+func (f *metricsFlags) open() (*db.DB, error) {
+	database, err := db.Open(f.dataDir, f.databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := database.Migrate(); err != nil {
+		database.Close()
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+	return database, nil
+}
+
+// load opens the database and reads the workspace's issues and history.
+func (f *metricsFlags) load() (*db.DB, []*db.Issue, *analytics.History, error) {
+	database, err := f.open()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	issues, err := db.NewIssueRepository(database).List(f.workspaceID, "", 0, 0)
+	if err != nil {
+		database.Close()
+		return nil, nil, nil, err
+	}
+	events, err := db.NewEventRepository(database).ListByWorkspace(f.workspaceID, time.Time{})
+	if err != nil {
+		database.Close()
+		return nil, nil, nil, err
+	}
+
+	return database, issues, analytics.NewHistory(events), nil
+}
+
+func createMetricsCmd() *cobra.Command {
+	flags := &metricsFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "metrics",
+		Short: "Show velocity, cycle time and burndown for a workspace",
+	}
+
+	cmd.PersistentFlags().StringVar(&flags.dataDir, "data-dir", "./.pulse-data", "Data directory")
+	cmd.PersistentFlags().StringVar(&flags.databaseURL, "database-url", os.Getenv("PULSE_DATABASE_URL"), "PostgreSQL URL (default: SQLite in --data-dir)")
+	cmd.PersistentFlags().StringVar(&flags.workspaceID, "workspace", "default", "Workspace ID")
+	cmd.PersistentFlags().BoolVar(&flags.json, "json", false, "Print JSON instead of tables")
+
+	cmd.AddCommand(
+		createMetricsVelocityCmd(flags),
+		createMetricsCycleTimeCmd(flags),
+		createMetricsBurndownCmd(flags),
+		createMetricsBreakdownCmd(flags),
+	)
+	return cmd
+}
+
+func createMetricsVelocityCmd(flags *metricsFlags) *cobra.Command {
+	var last int
+
+	cmd := &cobra.Command{
+		Use:   "velocity",
+		Short: "Points planned and completed per cycle",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			database, issues, _, err := flags.load()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			cycles, err := db.NewCycleRepository(database).List(flags.workspaceID)
+			if err != nil {
+				return err
+			}
+
+			velocity := analytics.CalculateVelocity(cycles, issues)
+			if last > 0 && len(velocity) > last {
+				velocity = velocity[len(velocity)-last:]
+			}
+
+			if flags.json {
+				return writeJSON(os.Stdout, velocity)
+			}
+			if len(velocity) == 0 {
+				fmt.Println("No cycles yet.")
+				return nil
+			}
+
+			t := &report.Table{Headers: []string{"Cycle", "Dates", "Planned", "Completed", "Rate", "Carryover"}}
+			completed := make([]float64, len(velocity))
+			var total int
+			for i, v := range velocity {
+				t.Rows = append(t.Rows, []string{
+					v.CycleName,
+					formatDates(v.StartDate, v.EndDate),
+					strconv.Itoa(v.PointsPlanned),
+					strconv.Itoa(v.PointsCompleted),
+					fmt.Sprintf("%.0f%%", v.CompletionRate),
+					strconv.Itoa(v.CarryoverPoints),
+				})
+				completed[i] = float64(v.PointsCompleted)
+				total += v.PointsCompleted
+			}
+			if err := report.WriteText(os.Stdout, t); err != nil {
+				return err
+			}
+
+			fmt.Printf("\nCompleted  %s  avg %.1f points/cycle\n", analytics.Sparkline(completed), float64(total)/float64(len(velocity)))
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&last, "last", 6, "Number of most recent cycles to show (0 for all)")
+	return cmd
+}
+
+func createMetricsCycleTimeCmd(flags *metricsFlags) *cobra.Command {
+	var days int
+	var last30 bool
+
+	cmd := &cobra.Command{
+		Use:   "cycle-time",
+		Short: "Time from starting an issue to finishing it",
+		Long: `Time from starting an issue to finishing it, measured from its first move
+to in_progress (or its creation, when that was not recorded) to completion.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			database, issues, history, err := flags.load()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			if last30 {
+				days = 30
+			}
+			var since time.Time
+			if days > 0 {
+				since = time.Now().AddDate(0, 0, -days)
+			}
+
+			m := analytics.CalculateCycleTime(issues, history, since)
+			if flags.json {
+				return writeJSON(os.Stdout, m)
+			}
+			if m.Count == 0 {
+				fmt.Println("No completed issues in this period.")
+				return nil
+			}
+
+			t := &report.Table{
+				Headers: []string{"Issues", "Average", "P50", "P90", "P99"},
+				Rows: [][]string{{
+					strconv.Itoa(m.Count),
+					formatHours(m.AverageHours),
+					formatHours(m.P50Hours),
+					formatHours(m.P90Hours),
+					formatHours(m.P99Hours),
+				}},
+			}
+			if err := report.WriteText(os.Stdout, t); err != nil {
+				return err
+			}
+
+			hours := make([]float64, len(m.Samples))
+			for i, s := range m.Samples {
+				hours[i] = s.Hours
+			}
+			fmt.Printf("\nBy completion  %s\n", analytics.Sparkline(hours))
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&days, "days", 0, "Only issues completed in the last N days (0 for all)")
+	cmd.Flags().BoolVar(&last30, "last-30-days", false, "Only issues completed in the last 30 days")
+	return cmd
+}
+
+func createMetricsBurndownCmd(flags *metricsFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "burndown [cycle-id]",
+		Short: "Remaining points per day of a cycle and its scope changes (default: the active cycle)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			database, issues, history, err := flags.load()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			cycles := db.NewCycleRepository(database)
+			var cycle *db.Cycle
+			if len(args) == 1 {
+				cycle, err = cycles.GetByID(args[0])
+			} else {
+				cycle, err = cycles.GetActive(flags.workspaceID)
+			}
+			if err != nil {
+				return err
+			}
+			if cycle == nil {
+				if len(args) == 1 {
+					return fmt.Errorf("cycle %s not found", args[0])
+				}
+				return fmt.Errorf("no active cycle in workspace %s; pass a cycle ID", flags.workspaceID)
+			}
+
+			b, err := analytics.CalculateBurndown(cycle, issues, history, time.Now())
+			if err != nil {
+				return err
+			}
+			if flags.json {
+				return writeJSON(os.Stdout, b)
+			}
+
+			t := &report.Table{Headers: []string{"Date", "Remaining", "Completed", "Scope", "Ideal"}}
+			remaining := make([]float64, len(b.Points))
+			for i, p := range b.Points {
+				t.Rows = append(t.Rows, []string{
+					p.Date.Format("Mon 01-02"),
+					strconv.Itoa(p.Remaining),
+					strconv.Itoa(p.Completed),
+					strconv.Itoa(p.Scope),
+					fmt.Sprintf("%.1f", p.Ideal),
+				})
+				remaining[i] = float64(p.Remaining)
+			}
+
+			fmt.Printf("%s  %s\n\n", b.CycleName, formatDates(&b.StartDate, &b.EndDate))
+			if err := report.WriteText(os.Stdout, t); err != nil {
+				return err
+			}
+			fmt.Printf("\nRemaining  %s\n", analytics.Sparkline(remaining))
+
+			if len(b.ScopeChanges) == 0 {
+				return nil
+			}
+			fmt.Printf("\nScope changes  +%d / -%d points since day one (%d)\n\n", b.PointsAdded, b.PointsRemoved, b.InitialScope)
+			changes := &report.Table{Headers: []string{"Date", "Change", "Points", "Key", "Title"}}
+			for _, c := range b.ScopeChanges {
+				changes.Rows = append(changes.Rows, []string{
+					c.Date.Local().Format("Mon 01-02"),
+					c.Type,
+					fmt.Sprintf("%+d", c.Points),
+					c.Key,
+					c.Title,
+				})
+			}
+			return report.WriteText(os.Stdout, changes)
+		},
+	}
+}
+
+func createMetricsBreakdownCmd(flags *metricsFlags) *cobra.Command {
+	var groupBy string
+	var days, cycles int
+
+	cmd := &cobra.Command{
+		Use:   "breakdown",
+		Short: "WIP, throughput, cycle time and velocity per assignee, label, priority or project",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !db.ValidGroupBy(groupBy) {
+				return fmt.Errorf("invalid --group-by %q (use %s)", groupBy, strings.Join(db.GroupBys, ", "))
+			}
+			if days <= 0 || cycles <= 0 {
+				return fmt.Errorf("--days and --cycles must be positive")
+			}
+
+			database, err := flags.open()
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			since := time.Now().UTC().AddDate(0, 0, -days)
+			groups, err := db.NewMetricsRepository(database).Breakdown(flags.workspaceID, groupBy, since, cycles)
+			if err != nil {
+				return err
+			}
+
+			if flags.json {
+				return writeJSON(os.Stdout, groups)
+			}
+			if len(groups) == 0 {
+				fmt.Println("No issues yet.")
+				return nil
+			}
+			return report.WriteText(os.Stdout, report.BreakdownTable(groupBy, groups))
+		},
+	}
+
+	cmd.Flags().StringVar(&groupBy, "group-by", db.GroupAssignee, "Group by assignee, label, priority or project")
+	cmd.Flags().IntVar(&days, "days", 28, "Throughput and cycle time over issues completed in the last N days")
+	cmd.Flags().IntVar(&cycles, "cycles", analytics.DefaultQualityCycles, "Velocity over the N most recent cycles")
+	return cmd
+}
+
+func formatDates(start, end *time.Time) string {
+	if start == nil || end == nil {
+		return ""
+	}
+	return start.Format("2006-01-02") + " – " + end.Format("2006-01-02")
+}
+
+// formatHours shows short durations in hours and longer ones in days.
+func formatHours(h float64) string {
+	if h < 48 {
+		return fmt.Sprintf("%.1fh", h)
+	}
+	return fmt.Sprintf("%.1fd", h/24)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/pulse/pm/internal/db"
+	"github.com/spf13/cobra"
+)
+
+func createMigrateCmd() *cobra.Command {
+	var dataDir string
+	var databaseURL string
+
+	migrateCmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Inspect and apply database schema migrations",
+	}
+	migrateCmd.PersistentFlags().StringVar(&dataDir, "data-dir", "./.pulse-data", "Data directory")
+	migrateCmd.PersistentFlags().StringVar(&databaseURL, "database-url", os.Getenv("PULSE_DATABASE_URL"), "PostgreSQL URL (default: SQLite in --data-dir)")
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show applied and pending migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			database, err := db.Open(dataDir, databaseURL)
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			statuses, err := database.MigrationStatuses()
+			if err != nil {
+				return err
+			}
+
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+			for _, st := range statuses {
+				state := "pending"
+				appliedAt := ""
+				if st.Applied {
+					state = "applied"
+					appliedAt = st.AppliedAt.Local().Format("2006-01-02 15:04:05")
+				}
+				if st.Modified {
+					state = "modified"
+				}
+				fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", st.Version, st.Name, state, appliedAt)
+			}
+			return tw.Flush()
+		},
+	}
+
+	upCmd := &cobra.Command{
+		Use:   "up [version]",
+		Short: "Apply pending migrations, optionally stopping at a version",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target := 0
+			if len(args) == 1 {
+				v, err := strconv.Atoi(args[0])
+				if err != nil {
+					return fmt.Errorf("invalid version %q", args[0])
+				}
+				target = v
+			}
+
+			database, err := db.Open(dataDir, databaseURL)
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			if err := database.MigrateUp(target); err != nil {
+				return err
+			}
+			fmt.Println("Migrations applied")
+			return nil
+		},
+	}
+
+	downToCmd := &cobra.Command{
+		Use:   "down-to <version>",
+		Short: "Revert migrations newer than version",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid version %q", args[0])
+			}
+
+			database, err := db.Open(dataDir, databaseURL)
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			if err := database.MigrateDownTo(target); err != nil {
+				return err
+			}
+			fmt.Printf("Reverted to version %d\n", target)
+			return nil
+		},
+	}
+
+	migrateCmd.AddCommand(statusCmd, upCmd, downToCmd)
+	return migrateCmd
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pulse/pm/internal/db"
+	"github.com/pulse/pm/internal/report"
+	"github.com/spf13/cobra"
+)
+
+func createReportCmd() *cobra.Command {
+	var dataDir string
+	var databaseURL string
+	var output string
+	var columns string
+
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Print issue lists and cycle summaries as tables, CSV, Markdown or JSON",
+	}
+
+	cmd.PersistentFlags().StringVar(&dataDir, "data-dir", "./.pulse-data", "Data directory")
+	cmd.PersistentFlags().StringVar(&databaseURL, "database-url", os.Getenv("PULSE_DATABASE_URL"), "PostgreSQL URL (default: SQLite in --data-dir)")
+	cmd.PersistentFlags().StringVarP(&output, "output", "o", report.FormatTable, "Output format: table, csv, md or json")
+	cmd.PersistentFlags().StringVar(&columns, "columns", "", "Comma separated issue columns (default: key,title,status,priority,assignee,estimate,labels)")
+
+	var workspaceID string
+	var status string
+	issuesCmd := &cobra.Command{
+		Use:   "issues",
+		Short: "List issues in a workspace",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cols, err := checkReportFlags(output, columns)
+			if err != nil {
+				return err
+			}
+
+			database, err := db.Open(dataDir, databaseURL)
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			issues, err := db.NewIssueRepository(database).List(workspaceID, status, 0, 0)
+			if err != nil {
+				return err
+			}
+
+			if output == report.FormatJSON {
+				return writeJSON(os.Stdout, issues)
+			}
+			return report.Write(os.Stdout, output, report.IssueTable(issues, cols))
+		},
+	}
+	issuesCmd.Flags().StringVar(&workspaceID, "workspace", "default", "Workspace to list")
+	issuesCmd.Flags().StringVar(&status, "status", "", "Only list issues with this status")
+
+	cycleCmd := &cobra.Command{
+		Use:   "cycle <id>",
+		Short: "Summarise a cycle and its issues",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cols, err := checkReportFlags(output, columns)
+			if err != nil {
+				return err
+			}
+
+			database, err := db.Open(dataDir, databaseURL)
+			if err != nil {
+				return err
+			}
+			defer database.Close()
+
+			cycle, err := db.NewCycleRepository(database).GetByID(args[0])
+			if err != nil {
+				return err
+			}
+			if cycle == nil {
+				return fmt.Errorf("cycle %s not found", args[0])
+			}
+
+			issues, err := db.NewIssueRepository(database).ListByCycle(cycle.ID)
+			if err != nil {
+				return err
+			}
+
+			rep := report.BuildCycleReport(cycle, issues)
+			if output == report.FormatJSON {
+				return writeJSON(os.Stdout, rep)
+			}
+			return rep.Write(os.Stdout, output, cols)
+		},
+	}
+
+	cmd.AddCommand(issuesCmd, cycleCmd)
+	return cmd
+}
+
+// checkReportFlags validates --output and parses --columns.
+func checkReportFlags(output, columns string) ([]string, error) {
+	if output != report.FormatJSON && !report.ValidFormat(output) {
+		return nil, fmt.Errorf("unsupported output %q (use table, csv, md or json)", output)
+	}
+	return report.ParseColumns(columns)
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/pulse/pm/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+func createTUICmd() *cobra.Command {
+	flags := &clientFlags{}
+
+	cmd := &cobra.Command{
+		Use:   "tui",
+		Short: "Open a full-screen issue board in the terminal",
+		Long: `Open a full-screen, keyboard-driven issue board in the terminal. It talks
+to a running Pulse server and updates live as issues change.
+
+  c          create issue              n / p    next / previous status
+  /          search                    space    assign to me (again to unassign)
+  j / k      move down / up            e        edit estimate
+  ← / →      previous / next column    l        add label
+  v          board or list view        enter    open detail
+  r          reload                    escape   close detail, clear search
+  q          quit
+
+Connection settings work as for "pulse issues".`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, cfg, err := flags.connect()
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			return tui.Run(ctx, tui.Options{
+				Client:      c,
+				WorkspaceID: cfg.Workspace,
+				User:        cfg.User,
+			})
+		},
+	}
+	flags.registerConnection(cmd)
+
+	return cmd
+}
@@ -0,0 +1,177 @@
+package analytics
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+// DefaultAgeBuckets are the bucket boundaries, in days, of an aging report.
+var DefaultAgeBuckets = []int{7, 30, 90, 180}
+
+// DefaultStaleDays is how long an open issue may sit in one status before
+// an aging report lists it as stale.
+const DefaultStaleDays = 30
+
+// AgeBucket counts open issues whose age falls in [MinDays, MaxDays).
+type AgeBucket struct {
+	Label   string `json:"label"`
+	MinDays int    `json:"min_days"`
+	MaxDays int    `json:"max_days,omitempty"` // zero means no upper bound
+	Count   int    `json:"count"`
+	Points  int    `json:"points"`
+}
+
+// AgingIssue is an open issue with how long it has been open and in its
+// current status.
+type AgingIssue struct {
+	IssueID    string `json:"issue_id"`
+	Key        string `json:"key"`
+	Title      string `json:"title"`
+	Status     string `json:"status"`
+	AgeDays    int    `json:"age_days"`
+	StatusDays int    `json:"status_days"`
+}
+
+// StatusAging is how long open issues have been in one status.
+type StatusAging struct {
+	Status      string       `json:"status"`
+	Count       int          `json:"count"`
+	AverageDays float64      `json:"average_days"`
+	OldestDays  int          `json:"oldest_days"`
+	Buckets     []*AgeBucket `json:"buckets"`
+}
+
+// AgingReport buckets the open issues of a workspace by age and by time
+// in their current status.
+type AgingReport struct {
+	Open      int            `json:"open"`
+	ByAge     []*AgeBucket   `json:"by_age"`
+	InStatus  []*AgeBucket   `json:"in_status"`
+	ByStatus  []*StatusAging `json:"by_status"`
+	StaleDays int            `json:"stale_days"`
+	Stale     []AgingIssue   `json:"stale"` // longest in status first
+}
+
+// StatusSince returns when an issue entered its current status: the last
+// recorded status change, or its creation time when there is none.
+func (h *History) StatusSince(issue *db.Issue) time.Time {
+	events := h.byIssue[issue.ID]
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].Field == db.FieldStatus {
+			return events[i].CreatedAt
+		}
+	}
+	return issue.CreatedAt
+}
+
+// CalculateAging reports on the open (not done or canceled) issues as of
+// now. bounds are ascending bucket boundaries in days; issues in their
+// current status for staleDays or more are listed as stale.
+func CalculateAging(issues []*db.Issue, history *History, bounds []int, staleDays int, now time.Time) *AgingReport {
+	r := &AgingReport{
+		ByAge:     newAgeBuckets(bounds),
+		InStatus:  newAgeBuckets(bounds),
+		ByStatus:  []*StatusAging{},
+		StaleDays: staleDays,
+		Stale:     []AgingIssue{},
+	}
+
+	byStatus := make(map[string]*StatusAging)
+	for _, issue := range issues {
+		if issue.Status == "done" || issue.Status == "canceled" {
+			continue
+		}
+		r.Open++
+
+		a := AgingIssue{
+			IssueID:    issue.ID,
+			Key:        issue.Key,
+			Title:      issue.Title,
+			Status:     issue.Status,
+			AgeDays:    daysBetween(issue.CreatedAt, now),
+			StatusDays: daysBetween(history.StatusSince(issue), now),
+		}
+		addToBucket(r.ByAge, a.AgeDays, issue.Estimate)
+		addToBucket(r.InStatus, a.StatusDays, issue.Estimate)
+
+		s, ok := byStatus[issue.Status]
+		if !ok {
+			s = &StatusAging{Status: issue.Status, Buckets: newAgeBuckets(bounds)}
+			byStatus[issue.Status] = s
+		}
+		s.Count++
+		s.AverageDays += float64(a.StatusDays)
+		if a.StatusDays > s.OldestDays {
+			s.OldestDays = a.StatusDays
+		}
+		addToBucket(s.Buckets, a.StatusDays, issue.Estimate)
+
+		if a.StatusDays >= staleDays {
+			r.Stale = append(r.Stale, a)
+		}
+	}
+
+	// Report statuses in workflow order.
+	for _, status := range db.Statuses {
+		if s, ok := byStatus[status]; ok {
+			s.AverageDays /= float64(s.Count)
+			r.ByStatus = append(r.ByStatus, s)
+		}
+	}
+	sort.SliceStable(r.Stale, func(i, j int) bool { return r.Stale[i].StatusDays > r.Stale[j].StatusDays })
+
+	return r
+}
+
+// StaleIssues returns the backlog issues with no activity for at least
+// days as of now. Activity is any change to the issue or a comment on it.
+func StaleIssues(issues []*db.Issue, comments []*db.Comment, days int, now time.Time) []*db.Issue {
+	lastActivity := make(map[string]time.Time, len(issues))
+	for _, issue := range issues {
+		lastActivity[issue.ID] = issue.UpdatedAt
+	}
+	for _, c := range comments {
+		if t, ok := lastActivity[c.IssueID]; ok && c.CreatedAt.After(t) {
+			lastActivity[c.IssueID] = c.CreatedAt
+		}
+	}
+
+	var stale []*db.Issue
+	for _, issue := range issues {
+		if issue.Status == "backlog" && daysBetween(lastActivity[issue.ID], now) >= days {
+			stale = append(stale, issue)
+		}
+	}
+	return stale
+}
+
+func newAgeBuckets(bounds []int) []*AgeBucket {
+	buckets := make([]*AgeBucket, 0, len(bounds)+1)
+	lower := 0
+	for _, upper := range bounds {
+		buckets = append(buckets, &AgeBucket{Label: fmt.Sprintf("%d-%dd", lower, upper), MinDays: lower, MaxDays: upper})
+		lower = upper
+	}
+	return append(buckets, &AgeBucket{Label: fmt.Sprintf("%dd+", lower), MinDays: lower})
+}
+
+func addToBucket(buckets []*AgeBucket, days, points int) {
+	for _, b := range buckets {
+		if b.MaxDays == 0 || days < b.MaxDays {
+			b.Count++
+			b.Points += points
+			return
+		}
+	}
+}
+
+// daysBetween returns the whole days elapsed from t to now.
+func daysBetween(t, now time.Time) int {
+	if now.Before(t) {
+		return 0
+	}
+	return int(now.Sub(t).Hours() / 24)
+}
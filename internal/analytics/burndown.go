@@ -0,0 +1,194 @@
+package analytics
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+// BurndownPoint is the remaining and completed work in a cycle at the end
+// of one day, in points and issues.
+type BurndownPoint struct {
+	Date            time.Time `json:"date"`
+	Remaining       int       `json:"remaining"`
+	Completed       int       `json:"completed"`
+	Scope           int       `json:"scope"`
+	RemainingIssues int       `json:"remaining_issues"`
+	CompletedIssues int       `json:"completed_issues"`
+	ScopeIssues     int       `json:"scope_issues"`
+	Ideal           float64   `json:"ideal"`
+}
+
+// Scope change types.
+const (
+	ScopeAdded    = "added"
+	ScopeRemoved  = "removed"
+	ScopeCanceled = "canceled"
+	ScopeEstimate = "estimate"
+)
+
+// ScopeChange is a change to a cycle's scope after its first day.
+type ScopeChange struct {
+	Date    time.Time `json:"date"`
+	Type    string    `json:"type"`
+	IssueID string    `json:"issue_id"`
+	Key     string    `json:"key"`
+	Title   string    `json:"title"`
+	// Points is the change in scope: positive when work was added.
+	Points int `json:"points"`
+}
+
+// Burndown tracks remaining and completed work over the days of a cycle,
+// with the changes to its scope along the way.
+type Burndown struct {
+	CycleID   string          `json:"cycle_id"`
+	CycleName string          `json:"cycle_name"`
+	StartDate time.Time       `json:"start_date"`
+	EndDate   time.Time       `json:"end_date"`
+	Points    []BurndownPoint `json:"points"`
+
+	InitialScope  int           `json:"initial_scope"`
+	PointsAdded   int           `json:"points_added"`
+	PointsRemoved int           `json:"points_removed"`
+	ScopeChanges  []ScopeChange `json:"scope_changes"`
+}
+
+// CalculateBurndown replays history to find, for each day of the cycle up
+// to now, the points and issues still open in the cycle, those completed,
+// and its total scope. issues must include every issue that may have been
+// in the cycle, not only those in it now. The ideal line runs from the
+// scope at the end of the first day to zero; later changes to the scope
+// are listed in ScopeChanges.
+func CalculateBurndown(cycle *db.Cycle, issues []*db.Issue, history *History, now time.Time) (*Burndown, error) {
+	if cycle.StartDate == nil || cycle.EndDate == nil {
+		return nil, fmt.Errorf("cycle %s has no start and end dates", cycle.ID)
+	}
+
+	start := startOfDay(*cycle.StartDate)
+	end := startOfDay(*cycle.EndDate)
+	b := &Burndown{
+		CycleID:   cycle.ID,
+		CycleName: cycle.Name,
+		StartDate: start,
+		EndDate:   end,
+		Points:    []BurndownPoint{},
+
+		ScopeChanges: []ScopeChange{},
+	}
+
+	days := int(end.Sub(start).Hours()/24) + 1
+	initialScope := 0
+	for day := 0; day < days; day++ {
+		date := start.AddDate(0, 0, day)
+		at := date.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		if day > 0 && date.After(now) {
+			break
+		}
+		if at.After(now) {
+			at = now
+		}
+
+		p := BurndownPoint{Date: date}
+		for _, issue := range issues {
+			state, ok := history.At(issue, at)
+			if !ok || state.CycleID != cycle.ID || state.Status == "canceled" {
+				continue
+			}
+			p.Scope += state.Estimate
+			p.ScopeIssues++
+			if state.Status == "done" {
+				p.Completed += state.Estimate
+				p.CompletedIssues++
+			} else {
+				p.Remaining += state.Estimate
+				p.RemainingIssues++
+			}
+		}
+		if day == 0 {
+			initialScope = p.Scope
+		}
+
+		p.Ideal = float64(initialScope)
+		if days > 1 {
+			p.Ideal = float64(initialScope) * float64(days-1-day) / float64(days-1)
+		}
+		b.Points = append(b.Points, p)
+	}
+
+	b.InitialScope = initialScope
+	b.ScopeChanges = scopeChanges(cycle.ID, issues, history, start.AddDate(0, 0, 1), end.AddDate(0, 0, 1))
+	for _, c := range b.ScopeChanges {
+		if c.Points > 0 {
+			b.PointsAdded += c.Points
+		} else {
+			b.PointsRemoved -= c.Points
+		}
+	}
+
+	return b, nil
+}
+
+// scopeChanges lists the changes to a cycle's scope in [from, to), oldest
+// first: issues created in or moved into the cycle, moved out of it or
+// canceled, and estimate changes to issues in it.
+func scopeChanges(cycleID string, issues []*db.Issue, history *History, from, to time.Time) []ScopeChange {
+	changes := []ScopeChange{}
+	within := func(t time.Time) bool { return !t.Before(from) && t.Before(to) }
+
+	for _, issue := range issues {
+		change := func(at time.Time, kind string, points int) {
+			changes = append(changes, ScopeChange{
+				Date:    at,
+				Type:    kind,
+				IssueID: issue.ID,
+				Key:     issue.Key,
+				Title:   issue.Title,
+				Points:  points,
+			})
+		}
+
+		if within(issue.CreatedAt) {
+			if state, ok := history.At(issue, issue.CreatedAt); ok && state.CycleID == cycleID && state.Status != "canceled" {
+				change(issue.CreatedAt, ScopeAdded, state.Estimate)
+			}
+		}
+
+		for _, e := range history.Events(issue.ID) {
+			if !within(e.CreatedAt) {
+				continue
+			}
+			// The state just before this change, and with it applied. An
+			// update records its changes at one instant, so an issue moved
+			// into the cycle with a new estimate counts once, as added.
+			before, _ := history.At(issue, e.CreatedAt.Add(-time.Nanosecond))
+			after, _ := history.At(issue, e.CreatedAt)
+
+			switch e.Field {
+			case db.FieldCycle:
+				if after.CycleID == cycleID && before.CycleID != cycleID && after.Status != "canceled" {
+					change(e.CreatedAt, ScopeAdded, after.Estimate)
+				} else if before.CycleID == cycleID && after.CycleID != cycleID && before.Status != "canceled" {
+					change(e.CreatedAt, ScopeRemoved, -before.Estimate)
+				}
+			case db.FieldStatus:
+				if before.CycleID == cycleID && after.CycleID == cycleID && after.Status == "canceled" && before.Status != "canceled" {
+					change(e.CreatedAt, ScopeCanceled, -before.Estimate)
+				}
+			case db.FieldEstimate:
+				if before.CycleID == cycleID && after.CycleID == cycleID && after.Status != "canceled" && after.Estimate != before.Estimate {
+					change(e.CreatedAt, ScopeEstimate, after.Estimate-before.Estimate)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Date.Before(changes[j].Date) })
+	return changes
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,272 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+// Capacity sources.
+const (
+	CapacityPoints  = "points"  // a fixed number of points was set
+	CapacityDays    = "days"    // available days were set via days off
+	CapacityDefault = "default" // nothing was set: every working day
+)
+
+// MemberLoad compares the work assigned to one member in a cycle with
+// their capacity.
+type MemberLoad struct {
+	MemberID      string  `json:"member_id"`
+	Source        string  `json:"source"`
+	AvailableDays int     `json:"available_days"`
+	Capacity      int     `json:"capacity"` // points
+	Assigned      int     `json:"assigned"` // points, done or not
+	Completed     int     `json:"completed"`
+	Issues        int     `json:"issues"`
+	Utilization   float64 `json:"utilization"` // assigned / capacity, 0-100+
+	Over          int     `json:"over"`        // points beyond capacity
+	Spare         int     `json:"spare"`       // points left before capacity
+}
+
+// CapacityPlan is the load on every member of a cycle.
+type CapacityPlan struct {
+	CycleID      string        `json:"cycle_id"`
+	CycleName    string        `json:"cycle_name"`
+	WorkingDays  int           `json:"working_days"`
+	PointsPerDay float64       `json:"points_per_day"`
+	Members      []*MemberLoad `json:"members"`
+	Capacity     int           `json:"capacity"`
+	Assigned     int           `json:"assigned"`
+	// Unassigned counts the cycle's open issues with no assignee.
+	UnassignedIssues int `json:"unassigned_issues"`
+	UnassignedPoints int `json:"unassigned_points"`
+}
+
+// WorkingDays counts the weekdays from start to end inclusive that are not
+// holidays (YYYY-MM-DD; invalid dates are ignored).
+func WorkingDays(start, end time.Time, holidays []string) int {
+	off := make(map[time.Time]bool, len(holidays))
+	for _, h := range holidays {
+		if t, err := time.Parse("2006-01-02", h); err == nil {
+			off[t] = true
+		}
+	}
+
+	days := 0
+	for d := utcDay(start); !d.After(utcDay(end)); d = d.AddDate(0, 0, 1) {
+		if d.Weekday() != time.Saturday && d.Weekday() != time.Sunday && !off[d] {
+			days++
+		}
+	}
+	return days
+}
+
+// PlanCapacity compares the estimates assigned to each member of a cycle
+// with their capacity. Members assigned work with no capacity set are
+// taken to be available every working day. Canceled issues are ignored.
+func PlanCapacity(cycle *db.Cycle, issues []*db.Issue, capacities []*db.Capacity, settings *db.WorkspaceSettings) (*CapacityPlan, error) {
+	if cycle.StartDate == nil || cycle.EndDate == nil {
+		return nil, fmt.Errorf("cycle %s has no start and end dates", cycle.ID)
+	}
+
+	p := &CapacityPlan{
+		CycleID:      cycle.ID,
+		CycleName:    cycle.Name,
+		WorkingDays:  WorkingDays(*cycle.StartDate, *cycle.EndDate, settings.Holidays),
+		PointsPerDay: settings.DailyPoints(),
+		Members:      []*MemberLoad{},
+	}
+
+	byMember := make(map[string]*MemberLoad)
+	member := func(id string) *MemberLoad {
+		m, ok := byMember[id]
+		if !ok {
+			m = &MemberLoad{MemberID: id, Source: CapacityDefault, AvailableDays: p.WorkingDays}
+			byMember[id] = m
+			p.Members = append(p.Members, m)
+		}
+		return m
+	}
+
+	for _, c := range capacities {
+		m := member(c.MemberID)
+		m.AvailableDays = p.WorkingDays - c.DaysOff
+		if m.AvailableDays < 0 {
+			m.AvailableDays = 0
+		}
+		m.Source = CapacityDays
+		if c.Points > 0 {
+			m.Source = CapacityPoints
+			m.Capacity = c.Points
+		}
+	}
+
+	for _, issue := range issues {
+		if issue.CycleID != cycle.ID || issue.Status == "canceled" {
+			continue
+		}
+		if issue.AssigneeID == "" {
+			if issue.Status != "done" {
+				p.UnassignedIssues++
+				p.UnassignedPoints += issue.Estimate
+			}
+			continue
+		}
+		m := member(issue.AssigneeID)
+		m.Issues++
+		m.Assigned += issue.Estimate
+		if issue.Status == "done" {
+			m.Completed += issue.Estimate
+		}
+	}
+
+	for _, m := range p.Members {
+		if m.Source != CapacityPoints {
+			m.Capacity = int(math.Floor(float64(m.AvailableDays) * p.PointsPerDay))
+		}
+		m.update()
+		p.Capacity += m.Capacity
+		p.Assigned += m.Assigned
+	}
+	sort.Slice(p.Members, func(i, j int) bool { return p.Members[i].MemberID < p.Members[j].MemberID })
+
+	return p, nil
+}
+
+func (m *MemberLoad) update() {
+	m.Over, m.Spare, m.Utilization = 0, 0, 0
+	if m.Assigned > m.Capacity {
+		m.Over = m.Assigned - m.Capacity
+	} else {
+		m.Spare = m.Capacity - m.Assigned
+	}
+	if m.Capacity > 0 {
+		m.Utilization = float64(m.Assigned) / float64(m.Capacity) * 100
+	}
+}
+
+// Balance suggestion actions.
+const (
+	ActionReassign = "reassign" // give to a member with spare capacity
+	ActionAssign   = "assign"   // give an unassigned issue to a member
+	ActionMove     = "move"     // take out of the cycle
+)
+
+// Suggestion proposes one change that brings a cycle within capacity.
+type Suggestion struct {
+	Action       string `json:"action"`
+	IssueID      string `json:"issue_id"`
+	Key          string `json:"key"`
+	Title        string `json:"title"`
+	Priority     int    `json:"priority"`
+	Estimate     int    `json:"estimate"`
+	FromAssignee string `json:"from_assignee,omitempty"`
+	ToAssignee   string `json:"to_assignee,omitempty"`
+	// ToCycleID is the cycle to move to; empty moves to the backlog.
+	ToCycleID string `json:"to_cycle_id,omitempty"`
+	Reason    string `json:"reason"`
+}
+
+// Balance proposes changes that fit a cycle's work to its capacity. Each
+// over-allocated member sheds open issues, least important first, to the
+// member with the most spare capacity that can take them, or out of the
+// cycle to nextCycleID (empty for the backlog) when nobody can. Then
+// unassigned open issues, most important first, go to members with room.
+// The plan is updated to reflect the suggestions.
+func Balance(plan *CapacityPlan, issues []*db.Issue, nextCycleID string) []Suggestion {
+	suggestions := []Suggestion{}
+
+	var open []*db.Issue
+	for _, issue := range issues {
+		if issue.CycleID == plan.CycleID && issue.Status != "done" && issue.Status != "canceled" {
+			open = append(open, issue)
+		}
+	}
+	// Most important first: urgent (1) through low (4), then none (0).
+	sort.SliceStable(open, func(i, j int) bool {
+		return priorityRank(open[i].Priority) < priorityRank(open[j].Priority)
+	})
+
+	roomiest := func(estimate int, except string) *MemberLoad {
+		var best *MemberLoad
+		for _, m := range plan.Members {
+			if m.MemberID != except && m.Spare >= estimate && (best == nil || m.Spare > best.Spare) {
+				best = m
+			}
+		}
+		return best
+	}
+	suggest := func(issue *db.Issue, action string) Suggestion {
+		return Suggestion{
+			Action:       action,
+			IssueID:      issue.ID,
+			Key:          issue.Key,
+			Title:        issue.Title,
+			Priority:     issue.Priority,
+			Estimate:     issue.Estimate,
+			FromAssignee: issue.AssigneeID,
+		}
+	}
+
+	for _, m := range plan.Members {
+		for i := len(open) - 1; i >= 0 && m.Over > 0; i-- {
+			issue := open[i]
+			if issue.AssigneeID != m.MemberID || issue.Estimate == 0 {
+				continue
+			}
+
+			s := suggest(issue, ActionMove)
+			if to := roomiest(issue.Estimate, m.MemberID); to != nil {
+				s.Action = ActionReassign
+				s.ToAssignee = to.MemberID
+				s.Reason = fmt.Sprintf("%s is %d points over capacity; %s has %d spare", m.MemberID, m.Over, to.MemberID, to.Spare)
+				to.Assigned += issue.Estimate
+				to.Issues++
+				to.update()
+			} else {
+				s.ToCycleID = nextCycleID
+				s.Reason = fmt.Sprintf("%s is %d points over capacity and nobody has room for %d points", m.MemberID, m.Over, issue.Estimate)
+				plan.Assigned -= issue.Estimate
+			}
+			m.Assigned -= issue.Estimate
+			m.Issues--
+			m.update()
+			suggestions = append(suggestions, s)
+			open = append(open[:i], open[i+1:]...)
+		}
+	}
+
+	for _, issue := range open {
+		if issue.AssigneeID != "" {
+			continue
+		}
+		to := roomiest(issue.Estimate, "")
+		if to == nil {
+			continue
+		}
+		s := suggest(issue, ActionAssign)
+		s.ToAssignee = to.MemberID
+		s.Reason = fmt.Sprintf("unassigned; %s has %d spare points", to.MemberID, to.Spare)
+		to.Assigned += issue.Estimate
+		to.Issues++
+		to.update()
+		plan.Assigned += issue.Estimate
+		plan.UnassignedIssues--
+		plan.UnassignedPoints -= issue.Estimate
+		suggestions = append(suggestions, s)
+	}
+
+	return suggestions
+}
+
+// priorityRank orders priorities from most to least important, with no
+// priority last.
+func priorityRank(priority int) int {
+	if priority == 0 {
+		return 5
+	}
+	return priority
+}
@@ -0,0 +1,155 @@
+package analytics
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", s)
+	if err != nil {
+		t, _ = time.Parse("2006-01-02", s)
+	}
+	return t
+}
+
+func TestWorkingDays(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		start, end string
+		holidays   []string
+		want       int
+	}{
+		{"one week", "2026-03-02", "2026-03-06", nil, 5},
+		{"weekends skipped", "2026-03-02", "2026-03-13", nil, 10},
+		{"weekday holiday", "2026-03-02", "2026-03-13", []string{"2026-03-04"}, 9},
+		{"weekend holiday and bad date", "2026-03-02", "2026-03-13", []string{"2026-03-07", "March 9"}, 10},
+		{"times of day ignored", "2026-03-02 18:00", "2026-03-02 01:00", nil, 1},
+		{"weekend only", "2026-03-07", "2026-03-08", nil, 0},
+		{"end before start", "2026-03-06", "2026-03-02", nil, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := WorkingDays(date(tc.start), date(tc.end), tc.holidays); got != tc.want {
+				t.Errorf("WorkingDays = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func capacityCycle() *db.Cycle {
+	start, end := date("2026-03-02"), date("2026-03-13")
+	return &db.Cycle{ID: "c1", Name: "Sprint", StartDate: &start, EndDate: &end}
+}
+
+func TestPlanCapacity(t *testing.T) {
+	issues := []*db.Issue{
+		{ID: "a1", CycleID: "c1", AssigneeID: "alice", Estimate: 5, Status: "todo"},
+		{ID: "a2", CycleID: "c1", AssigneeID: "alice", Estimate: 5, Status: "done"},
+		{ID: "b1", CycleID: "c1", AssigneeID: "bob", Estimate: 4, Status: "in_progress"},
+		{ID: "c1", CycleID: "c1", AssigneeID: "carol", Estimate: 2, Status: "todo"},
+		{ID: "c2", CycleID: "c1", AssigneeID: "carol", Estimate: 3, Status: "canceled"},
+		{ID: "u1", CycleID: "c1", Estimate: 3, Status: "todo"},
+		{ID: "u2", CycleID: "c1", Estimate: 1, Status: "done"},
+		{ID: "x1", CycleID: "other", AssigneeID: "alice", Estimate: 7, Status: "todo"},
+	}
+	capacities := []*db.Capacity{
+		{MemberID: "alice", Points: 8, DaysOff: 2},
+		{MemberID: "bob", DaysOff: 3},
+		{MemberID: "dave", DaysOff: 20},
+	}
+	settings := &db.WorkspaceSettings{PointsPerDay: 1.5, Holidays: []string{"2026-03-04"}}
+
+	plan, err := PlanCapacity(capacityCycle(), issues, capacities, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.WorkingDays != 9 || plan.PointsPerDay != 1.5 {
+		t.Errorf("%d working days at %v points, want 9 at 1.5", plan.WorkingDays, plan.PointsPerDay)
+	}
+	if plan.UnassignedIssues != 1 || plan.UnassignedPoints != 3 {
+		t.Errorf("unassigned = %d issues, %d points; want 1 and 3", plan.UnassignedIssues, plan.UnassignedPoints)
+	}
+
+	want := []MemberLoad{
+		// A points capacity wins over days off.
+		{MemberID: "alice", Source: CapacityPoints, AvailableDays: 7, Capacity: 8, Assigned: 10, Completed: 5, Issues: 2, Utilization: 125, Over: 2},
+		// Six days at 1.5 points.
+		{MemberID: "bob", Source: CapacityDays, AvailableDays: 6, Capacity: 9, Assigned: 4, Issues: 1, Utilization: 400.0 / 9, Spare: 5},
+		// Nothing set: every working day, rounded down.
+		{MemberID: "carol", Source: CapacityDefault, AvailableDays: 9, Capacity: 13, Assigned: 2, Issues: 1, Utilization: 200.0 / 13, Spare: 11},
+		{MemberID: "dave", Source: CapacityDays},
+	}
+	if len(plan.Members) != len(want) {
+		t.Fatalf("%d members, want %d", len(plan.Members), len(want))
+	}
+	for i, m := range plan.Members {
+		if *m != want[i] {
+			t.Errorf("member %d = %+v\nwant %+v", i, *m, want[i])
+		}
+	}
+	if plan.Capacity != 30 || plan.Assigned != 16 {
+		t.Errorf("plan capacity %d assigned %d, want 30 and 16", plan.Capacity, plan.Assigned)
+	}
+
+	if _, err := PlanCapacity(&db.Cycle{ID: "undated"}, nil, nil, settings); err == nil {
+		t.Error("planned a cycle without dates")
+	}
+}
+
+func TestBalance(t *testing.T) {
+	issues := []*db.Issue{
+		// alice is 2 over and sheds her least important issue first.
+		{ID: "a1", CycleID: "c1", AssigneeID: "alice", Priority: 1, Estimate: 2, Status: "todo"},
+		{ID: "a2", CycleID: "c1", AssigneeID: "alice", Priority: 4, Estimate: 2, Status: "todo"},
+		{ID: "a3", CycleID: "c1", AssigneeID: "alice", Priority: 0, Estimate: 2, Status: "in_progress"},
+		{ID: "a4", CycleID: "c1", AssigneeID: "alice", Priority: 0, Estimate: 0, Status: "todo"},
+		// carol is 2 over and nobody has room left for her issue.
+		{ID: "c1", CycleID: "c1", AssigneeID: "carol", Priority: 2, Estimate: 3, Status: "todo"},
+		// Unassigned work goes out most important first, to whoever has
+		// room once the overloads are shed.
+		{ID: "u1", CycleID: "c1", Priority: 4, Estimate: 1, Status: "todo"},
+		{ID: "u2", CycleID: "c1", Priority: 1, Estimate: 1, Status: "todo"},
+		{ID: "u3", CycleID: "c1", Priority: 2, Estimate: 5, Status: "todo"},
+	}
+	capacities := []*db.Capacity{
+		{MemberID: "alice", Points: 4},
+		{MemberID: "bob", Points: 3},
+		{MemberID: "carol", Points: 1},
+	}
+	plan, err := PlanCapacity(capacityCycle(), issues, capacities, &db.WorkspaceSettings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got [][3]string
+	for _, s := range Balance(plan, issues, "next") {
+		to := s.ToAssignee
+		if s.Action == ActionMove {
+			to = s.ToCycleID
+		}
+		got = append(got, [3]string{s.Action, s.IssueID, to})
+	}
+	want := [][3]string{
+		{ActionReassign, "a3", "bob"},
+		{ActionMove, "c1", "next"},
+		{ActionAssign, "u2", "bob"},
+		{ActionAssign, "u1", "carol"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("suggestions = %v\nwant %v", got, want)
+	}
+
+	loads := make(map[string]*MemberLoad)
+	for _, m := range plan.Members {
+		loads[m.MemberID] = m
+	}
+	if a, b, c := loads["alice"], loads["bob"], loads["carol"]; a.Over != 0 || b.Spare != 0 || c.Assigned != 1 {
+		t.Errorf("plan after balancing: alice %+v, bob %+v, carol %+v", *a, *b, *c)
+	}
+	if plan.UnassignedIssues != 1 || plan.UnassignedPoints != 5 {
+		t.Errorf("unassigned after balancing = %d issues, %d points; want 1 and 5", plan.UnassignedIssues, plan.UnassignedPoints)
+	}
+}
@@ -0,0 +1,90 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+// CycleTimeSample is the cycle time of one completed issue.
+type CycleTimeSample struct {
+	IssueID     string    `json:"issue_id"`
+	Title       string    `json:"title"`
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at"`
+	Hours       float64   `json:"hours"`
+}
+
+// CycleTimeMetrics summarises how long issues take from start to done.
+type CycleTimeMetrics struct {
+	Count        int               `json:"count"`
+	AverageHours float64           `json:"average_hours"`
+	P50Hours     float64           `json:"p50_hours"`
+	P90Hours     float64           `json:"p90_hours"`
+	P99Hours     float64           `json:"p99_hours"`
+	Samples      []CycleTimeSample `json:"samples"`
+}
+
+// CalculateCycleTime measures completed_at minus the time each done issue
+// first moved to in_progress, for issues completed at or after since.
+// Samples are ordered by completion time.
+func CalculateCycleTime(issues []*db.Issue, history *History, since time.Time) *CycleTimeMetrics {
+	m := &CycleTimeMetrics{Samples: []CycleTimeSample{}}
+
+	for _, issue := range issues {
+		if issue.Status != "done" || issue.CompletedAt == nil || issue.CompletedAt.Before(since) {
+			continue
+		}
+		started := history.StartedAt(issue)
+		hours := issue.CompletedAt.Sub(started).Hours()
+		if hours < 0 {
+			hours = 0
+		}
+		m.Samples = append(m.Samples, CycleTimeSample{
+			IssueID:     issue.ID,
+			Title:       issue.Title,
+			StartedAt:   started,
+			CompletedAt: *issue.CompletedAt,
+			Hours:       hours,
+		})
+	}
+
+	sort.Slice(m.Samples, func(i, j int) bool { return m.Samples[i].CompletedAt.Before(m.Samples[j].CompletedAt) })
+
+	m.Count = len(m.Samples)
+	if m.Count == 0 {
+		return m
+	}
+
+	hours := make([]float64, m.Count)
+	var total float64
+	for i, s := range m.Samples {
+		hours[i] = s.Hours
+		total += s.Hours
+	}
+	sort.Float64s(hours)
+
+	m.AverageHours = total / float64(m.Count)
+	m.P50Hours = Percentile(hours, 50)
+	m.P90Hours = Percentile(hours, 90)
+	m.P99Hours = Percentile(hours, 99)
+	return m
+}
+
+// Percentile returns the p-th percentile of sorted values using the
+// nearest-rank method.
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,145 @@
+package analytics
+
+import (
+	"time"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+// Throughput intervals.
+const (
+	IntervalDay  = "day"
+	IntervalWeek = "week"
+)
+
+// CFDPoint is the number of issues in each status at the end of one day.
+type CFDPoint struct {
+	Date   time.Time      `json:"date"`
+	Counts map[string]int `json:"counts"`
+	Total  int            `json:"total"`
+}
+
+// CFD is a cumulative flow diagram: daily issue counts per status.
+type CFD struct {
+	Start    time.Time  `json:"start"`
+	End      time.Time  `json:"end"`
+	Statuses []string   `json:"statuses"`
+	Days     []CFDPoint `json:"days"`
+}
+
+// CalculateCFD replays history to count the issues in each status at the
+// end of every day (UTC) from start to end, stopping at today.
+func CalculateCFD(issues []*db.Issue, history *History, start, end, now time.Time) *CFD {
+	cfd := &CFD{
+		Start:    utcDay(start),
+		End:      utcDay(end),
+		Statuses: db.Statuses,
+		Days:     []CFDPoint{},
+	}
+
+	for date := cfd.Start; !date.After(cfd.End) && !date.After(now); date = date.AddDate(0, 0, 1) {
+		at := date.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		if at.After(now) {
+			at = now
+		}
+
+		p := CFDPoint{Date: date, Counts: make(map[string]int, len(db.Statuses))}
+		for _, status := range db.Statuses {
+			p.Counts[status] = 0
+		}
+		for _, issue := range issues {
+			state, ok := history.At(issue, at)
+			if !ok {
+				continue
+			}
+			p.Counts[state.Status]++
+			p.Total++
+		}
+		cfd.Days = append(cfd.Days, p)
+	}
+
+	return cfd
+}
+
+// ThroughputPoint is the work completed in one day or week.
+type ThroughputPoint struct {
+	Start  time.Time `json:"start"`
+	Issues int       `json:"issues"`
+	Points int       `json:"points"`
+}
+
+// ThroughputSeries is completed work per period over a date range.
+type ThroughputSeries struct {
+	Interval      string            `json:"interval"`
+	Start         time.Time         `json:"start"`
+	End           time.Time         `json:"end"`
+	Periods       []ThroughputPoint `json:"periods"`
+	TotalIssues   int               `json:"total_issues"`
+	TotalPoints   int               `json:"total_points"`
+	AverageIssues float64           `json:"average_issues"` // per period
+	AveragePoints float64           `json:"average_points"`
+}
+
+// CalculateThroughput counts the issues and points completed in each day
+// or week (starting Monday, UTC) from start to end. Completions come from
+// status history: every move to done counts, with the estimate the issue
+// had at that moment. Issues created as done count at their completion
+// time.
+func CalculateThroughput(issues []*db.Issue, history *History, interval string, start, end time.Time) *ThroughputSeries {
+	first := utcDay(start)
+	if interval == IntervalWeek {
+		first = first.AddDate(0, 0, -((int(first.Weekday()) + 6) % 7))
+	}
+	step := func(t time.Time) time.Time {
+		if interval == IntervalWeek {
+			return t.AddDate(0, 0, 7)
+		}
+		return t.AddDate(0, 0, 1)
+	}
+
+	s := &ThroughputSeries{Interval: interval, Start: first, End: utcDay(end), Periods: []ThroughputPoint{}}
+	for t := first; !t.After(s.End); t = step(t) {
+		s.Periods = append(s.Periods, ThroughputPoint{Start: t})
+	}
+	if len(s.Periods) == 0 {
+		return s
+	}
+	limit := step(s.Periods[len(s.Periods)-1].Start)
+
+	add := func(issue *db.Issue, at time.Time) {
+		if at.Before(first) || !at.Before(limit) {
+			return
+		}
+		i := int(utcDay(at).Sub(first).Hours() / 24)
+		if interval == IntervalWeek {
+			i /= 7
+		}
+		points := issue.Estimate
+		if state, ok := history.At(issue, at); ok {
+			points = state.Estimate
+		}
+		s.Periods[i].Issues++
+		s.Periods[i].Points += points
+	}
+
+	for _, issue := range issues {
+		completions := 0
+		for _, e := range history.Events(issue.ID) {
+			if e.Field == db.FieldStatus && e.NewValue == "done" {
+				add(issue, e.CreatedAt)
+				completions++
+			}
+		}
+		if completions == 0 && issue.Status == "done" && issue.CompletedAt != nil {
+			add(issue, *issue.CompletedAt)
+		}
+	}
+
+	for _, p := range s.Periods {
+		s.TotalIssues += p.Issues
+		s.TotalPoints += p.Points
+	}
+	s.AverageIssues = float64(s.TotalIssues) / float64(len(s.Periods))
+	s.AveragePoints = float64(s.TotalPoints) / float64(len(s.Periods))
+	return s
+}
@@ -0,0 +1,160 @@
+package analytics
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+// Forecast defaults.
+const (
+	DefaultForecastSimulations = 10000
+	DefaultForecastHistory     = 12 // weeks of throughput to sample
+	DefaultForecastSeed        = 1
+	// MaxForecastWeeks caps a simulated delivery; runs that take longer
+	// are reported as not finishing.
+	MaxForecastWeeks = 520
+)
+
+// ForecastPercents are the confidence levels a forecast reports.
+var ForecastPercents = []int{50, 85, 95}
+
+// ErrNoThroughput is returned when there is no completed work to sample.
+var ErrNoThroughput = errors.New("no completed issues in the throughput history")
+
+// ForecastDate is the date by which the remaining work is done with the
+// given confidence.
+type ForecastDate struct {
+	Percent int        `json:"percent"`
+	Weeks   int        `json:"weeks"`
+	Date    *time.Time `json:"date"` // nil when beyond MaxForecastWeeks
+}
+
+// ForecastItems is how many items are done by the target date with the
+// given confidence.
+type ForecastItems struct {
+	Percent int `json:"percent"`
+	Items   int `json:"items"`
+}
+
+// Forecast is the outcome of a Monte Carlo simulation over historical
+// weekly throughput.
+type Forecast struct {
+	Remaining   int   `json:"remaining"`
+	Simulations int   `json:"simulations"`
+	Seed        int64 `json:"seed"`
+	// Throughput is the issues completed in each sampled week, oldest
+	// first.
+	Throughput []int `json:"throughput"`
+
+	Completion []ForecastDate `json:"completion"`
+
+	// Target and Items answer how much will be done by a date.
+	Target *time.Time      `json:"target,omitempty"`
+	Items  []ForecastItems `json:"items,omitempty"`
+}
+
+// WeeklyThroughput returns the issues completed in each of the last weeks
+// full weeks (Monday to Sunday, UTC) before the current week.
+func WeeklyThroughput(issues []*db.Issue, history *History, weeks int, now time.Time) []int {
+	monday := utcDay(now)
+	monday = monday.AddDate(0, 0, -((int(monday.Weekday()) + 6) % 7))
+	series := CalculateThroughput(issues, history, IntervalWeek, monday.AddDate(0, 0, -7*weeks), monday.AddDate(0, 0, -1))
+
+	samples := make([]int, len(series.Periods))
+	for i, p := range series.Periods {
+		samples[i] = p.Issues
+	}
+	return samples
+}
+
+// RunForecast simulates delivering remaining issues by repeatedly drawing
+// a random week from throughput, reporting the completion date at each of
+// ForecastPercents. When target is set it also reports how many issues
+// are done by then: the count reached in at least that share of runs, and
+// never more than remaining. The
+// same seed always gives the same forecast.
+func RunForecast(throughput []int, remaining, simulations int, seed int64, now time.Time, target *time.Time) (*Forecast, error) {
+	total := 0
+	for _, n := range throughput {
+		total += n
+	}
+	if total == 0 {
+		return nil, ErrNoThroughput
+	}
+
+	f := &Forecast{
+		Remaining:   remaining,
+		Simulations: simulations,
+		Seed:        seed,
+		Throughput:  throughput,
+		Completion:  []ForecastDate{},
+	}
+	rng := rand.New(rand.NewSource(seed))
+	draw := func() int { return throughput[rng.Intn(len(throughput))] }
+
+	weeks := make([]int, simulations)
+	for i := range weeks {
+		done, w := 0, 0
+		for done < remaining && w <= MaxForecastWeeks {
+			done += draw()
+			w++
+		}
+		weeks[i] = w
+	}
+	sort.Ints(weeks)
+
+	for _, p := range ForecastPercents {
+		d := ForecastDate{Percent: p, Weeks: weeks[percentileIndex(simulations, float64(p))]}
+		if d.Weeks <= MaxForecastWeeks {
+			date := utcDay(now).AddDate(0, 0, 7*d.Weeks)
+			d.Date = &date
+		}
+		f.Completion = append(f.Completion, d)
+	}
+
+	if target != nil {
+		f.Target = target
+		span := utcDay(*target).Sub(utcDay(now)).Hours() / 24 / 7
+		if span < 0 {
+			span = 0
+		}
+		full, partial := int(span), span-math.Floor(span)
+
+		items := make([]int, simulations)
+		for i := range items {
+			for w := 0; w < full; w++ {
+				items[i] += draw()
+			}
+			if partial > 0 {
+				items[i] += int(float64(draw()) * partial)
+			}
+			items[i] = min(items[i], remaining)
+		}
+		sort.Ints(items)
+
+		// At p% confidence, at least the count reached by p% of runs.
+		for _, p := range ForecastPercents {
+			f.Items = append(f.Items, ForecastItems{Percent: p, Items: items[percentileIndex(simulations, float64(100-p))]})
+		}
+	}
+
+	return f, nil
+}
+
+// percentileIndex returns the nearest-rank index of the p-th percentile in
+// n sorted values.
+func percentileIndex(n int, p float64) int {
+	rank := int(math.Ceil(p / 100 * float64(n)))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > n {
+		rank = n
+	}
+	return rank - 1
+}
@@ -0,0 +1,91 @@
+package analytics
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var forecastNow = time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+
+func TestRunForecastIsReproducible(t *testing.T) {
+	throughput := []int{3, 0, 7, 4, 2, 9, 5, 1, 6, 4, 3, 8}
+	target := forecastNow.AddDate(0, 0, 45)
+
+	first, err := RunForecast(throughput, 40, DefaultForecastSimulations, 42, forecastNow, &target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := RunForecast(throughput, 40, DefaultForecastSimulations, 42, forecastNow, &target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("same seed gave different forecasts:\n%+v\n%+v", first, second)
+	}
+}
+
+func TestRunForecastConstantThroughput(t *testing.T) {
+	target := forecastNow.AddDate(0, 0, 21)
+	f, err := RunForecast([]int{5, 5, 5, 5}, 20, 1000, DefaultForecastSeed, forecastNow, &target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := utcDay(forecastNow).AddDate(0, 0, 28)
+	for _, d := range f.Completion {
+		if d.Weeks != 4 || d.Date == nil || !d.Date.Equal(want) {
+			t.Errorf("%d%%: %d weeks, %v; want 4 weeks, %v", d.Percent, d.Weeks, d.Date, want)
+		}
+	}
+	for _, n := range f.Items {
+		if n.Items != 15 {
+			t.Errorf("%d%%: %d items by target, want 15", n.Percent, n.Items)
+		}
+	}
+}
+
+func TestRunForecastItemsCappedAtRemaining(t *testing.T) {
+	target := forecastNow.AddDate(0, 0, 21)
+	f, err := RunForecast([]int{5, 5, 5, 5}, 5, 1000, DefaultForecastSeed, forecastNow, &target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range f.Items {
+		if n.Items != 5 {
+			t.Errorf("%d%%: %d items by target, want all 5 remaining", n.Percent, n.Items)
+		}
+	}
+}
+
+// TestRunForecastCoinFlip samples weeks that finish everything or nothing
+// with equal odds, so the weeks needed follow a geometric distribution:
+// P(weeks <= k) = 1 - 2^-k.
+func TestRunForecastCoinFlip(t *testing.T) {
+	f, err := RunForecast([]int{0, 10}, 10, DefaultForecastSimulations, DefaultForecastSeed, forecastNow, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The median sits on the boundary between one and two weeks.
+	want := map[int][]int{50: {1, 2}, 85: {3}, 95: {5}}
+	for _, d := range f.Completion {
+		ok := false
+		for _, w := range want[d.Percent] {
+			ok = ok || d.Weeks == w
+		}
+		if !ok {
+			t.Errorf("%d%%: %d weeks, want one of %v", d.Percent, d.Weeks, want[d.Percent])
+		}
+	}
+	if f.Items != nil {
+		t.Errorf("items reported without a target: %v", f.Items)
+	}
+}
+
+func TestRunForecastWithoutThroughput(t *testing.T) {
+	if _, err := RunForecast([]int{0, 0, 0}, 10, 100, DefaultForecastSeed, forecastNow, nil); !errors.Is(err, ErrNoThroughput) {
+		t.Errorf("err = %v, want ErrNoThroughput", err)
+	}
+}
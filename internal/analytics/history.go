@@ -0,0 +1,105 @@
+// Package analytics derives delivery metrics such as velocity, cycle time
+// and burndown from issues and their recorded history.
+package analytics
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+// IssueState is the value of an issue's tracked fields at a point in time.
+type IssueState struct {
+	Status      string
+	Priority    int
+	AssigneeID  string
+	Estimate    int
+	CycleID     string
+	ParentID    string
+	ProjectID   string
+	MilestoneID string
+}
+
+// History replays issue events to reconstruct past issue states.
+type History struct {
+	byIssue map[string][]*db.IssueEvent
+}
+
+// NewHistory indexes events by issue. Events may be in any order.
+func NewHistory(events []*db.IssueEvent) *History {
+	h := &History{byIssue: make(map[string][]*db.IssueEvent)}
+	for _, e := range events {
+		h.byIssue[e.IssueID] = append(h.byIssue[e.IssueID], e)
+	}
+	for _, list := range h.byIssue {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	}
+	return h
+}
+
+// Events returns the recorded events for an issue, oldest first.
+func (h *History) Events(issueID string) []*db.IssueEvent {
+	return h.byIssue[issueID]
+}
+
+// At returns the state of issue at time t, working back from its current
+// state by undoing every event recorded after t. ok is false when the
+// issue did not exist yet. An unknown previous status counts as backlog.
+func (h *History) At(issue *db.Issue, t time.Time) (state IssueState, ok bool) {
+	if issue.CreatedAt.After(t) {
+		return IssueState{}, false
+	}
+
+	state = IssueState{
+		Status:      issue.Status,
+		Priority:    issue.Priority,
+		AssigneeID:  issue.AssigneeID,
+		Estimate:    issue.Estimate,
+		CycleID:     issue.CycleID,
+		ParentID:    issue.ParentID,
+		ProjectID:   issue.ProjectID,
+		MilestoneID: issue.MilestoneID,
+	}
+
+	events := h.byIssue[issue.ID]
+	for i := len(events) - 1; i >= 0 && events[i].CreatedAt.After(t); i-- {
+		e := events[i]
+		switch e.Field {
+		case db.FieldStatus:
+			state.Status = e.OldValue
+			if state.Status == "" {
+				state.Status = "backlog"
+			}
+		case db.FieldPriority:
+			state.Priority, _ = strconv.Atoi(e.OldValue)
+		case db.FieldAssignee:
+			state.AssigneeID = e.OldValue
+		case db.FieldEstimate:
+			state.Estimate, _ = strconv.Atoi(e.OldValue)
+		case db.FieldCycle:
+			state.CycleID = e.OldValue
+		case db.FieldParent:
+			state.ParentID = e.OldValue
+		case db.FieldProject:
+			state.ProjectID = e.OldValue
+		case db.FieldMilestone:
+			state.MilestoneID = e.OldValue
+		}
+	}
+
+	return state, true
+}
+
+// StartedAt returns when work on an issue started: the first time it
+// entered in_progress, or its creation time when no such move was
+// recorded.
+func (h *History) StartedAt(issue *db.Issue) time.Time {
+	for _, e := range h.byIssue[issue.ID] {
+		if e.Field == db.FieldStatus && e.NewValue == "in_progress" {
+			return e.CreatedAt
+		}
+	}
+	return issue.CreatedAt
+}
@@ -0,0 +1,115 @@
+package analytics
+
+import (
+	"math"
+	"time"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+// DefaultThroughputWindow is how far back CalculateProgress looks when
+// measuring recent throughput.
+const DefaultThroughputWindow = 28 * 24 * time.Hour
+
+// Progress summarises how much of a body of work is done and when the rest
+// is expected to land.
+type Progress struct {
+	Issues          int     `json:"issues"`
+	Completed       int     `json:"completed"`
+	Canceled        int     `json:"canceled"`
+	Points          int     `json:"points"`
+	CompletedPoints int     `json:"completed_points"`
+	IssuePercent    float64 `json:"issue_percent"` // 0-100
+	PointPercent    float64 `json:"point_percent"` // 0-100
+
+	// Throughput is the work completed per week over the window, in
+	// ThroughputUnit: points when the work is estimated, else issues.
+	Throughput     float64 `json:"throughput"`
+	ThroughputUnit string  `json:"throughput_unit"`
+	WindowDays     int     `json:"window_days"`
+
+	// ProjectedCompletion extrapolates the remaining work at the recent
+	// throughput. It is nil when nothing was completed in the window.
+	ProjectedCompletion *time.Time `json:"projected_completion"`
+	TargetDate          *time.Time `json:"target_date"`
+	// OnTrack reports whether the projection lands on or before the
+	// target date; nil when either is unknown.
+	OnTrack *bool `json:"on_track"`
+}
+
+// CalculateProgress measures issues against target, projecting completion
+// from the issues completed during the window before now. Canceled issues
+// are not counted as work.
+func CalculateProgress(issues []*db.Issue, target *time.Time, now time.Time, window time.Duration) *Progress {
+	p := &Progress{
+		TargetDate: target,
+		WindowDays: int(window.Hours() / 24),
+	}
+
+	var recentIssues, recentPoints int
+	var lastDone time.Time
+	since := now.Add(-window)
+
+	for _, issue := range issues {
+		if issue.Status == "canceled" {
+			p.Canceled++
+			continue
+		}
+		p.Issues++
+		p.Points += issue.Estimate
+		if issue.Status != "done" {
+			continue
+		}
+		p.Completed++
+		p.CompletedPoints += issue.Estimate
+		if issue.CompletedAt == nil {
+			continue
+		}
+		if issue.CompletedAt.After(lastDone) {
+			lastDone = *issue.CompletedAt
+		}
+		if !issue.CompletedAt.Before(since) && !issue.CompletedAt.After(now) {
+			recentIssues++
+			recentPoints += issue.Estimate
+		}
+	}
+
+	if p.Issues > 0 {
+		p.IssuePercent = float64(p.Completed) / float64(p.Issues) * 100
+	}
+	if p.Points > 0 {
+		p.PointPercent = float64(p.CompletedPoints) / float64(p.Points) * 100
+	}
+
+	remaining, recent := p.Issues-p.Completed, recentIssues
+	p.ThroughputUnit = "issues"
+	if p.Points > 0 {
+		remaining, recent = p.Points-p.CompletedPoints, recentPoints
+		p.ThroughputUnit = "points"
+	}
+
+	weeks := window.Hours() / (24 * 7)
+	if weeks > 0 {
+		p.Throughput = float64(recent) / weeks
+	}
+
+	switch {
+	case p.Issues == 0:
+		// Nothing to project.
+	case remaining <= 0:
+		if !lastDone.IsZero() {
+			p.ProjectedCompletion = &lastDone
+		}
+	case p.Throughput > 0:
+		days := math.Ceil(float64(remaining) / p.Throughput * 7)
+		projected := startOfDay(now).AddDate(0, 0, int(days))
+		p.ProjectedCompletion = &projected
+	}
+
+	if p.ProjectedCompletion != nil && target != nil {
+		onTrack := !startOfDay(*p.ProjectedCompletion).After(startOfDay(*target))
+		p.OnTrack = &onTrack
+	}
+
+	return p
+}
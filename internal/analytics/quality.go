@@ -0,0 +1,142 @@
+package analytics
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+// DefaultQualityCycles is how many recent cycles a quality report trends.
+const DefaultQualityCycles = 6
+
+// QualityMetrics measures defects among a set of issues. Canceled issues
+// are not counted.
+type QualityMetrics struct {
+	Label     string     `json:"label,omitempty"`
+	CycleID   string     `json:"cycle_id,omitempty"`
+	CycleName string     `json:"cycle_name,omitempty"`
+	StartDate *time.Time `json:"start_date,omitempty"`
+	EndDate   *time.Time `json:"end_date,omitempty"`
+
+	Issues   int     `json:"issues"`
+	BugCount int     `json:"bug_count"`
+	BugRate  float64 `json:"bug_rate"` // bugs per 100 issues
+	// Completed is how many issues were ever done; Reopened how many of
+	// them later moved out of done again.
+	Completed   int     `json:"completed"`
+	Reopened    int     `json:"reopened"`
+	ReopenRate  float64 `json:"reopen_rate"`  // 0-100
+	EscapedBugs int     `json:"escaped_bugs"` // found in production
+}
+
+// QualityReport is quality overall, per label, and per cycle for the most
+// recent cycles.
+type QualityReport struct {
+	BugLabel     string            `json:"bug_label"`
+	EscapedLabel string            `json:"escaped_label"`
+	Overall      *QualityMetrics   `json:"overall"`
+	ByLabel      []*QualityMetrics `json:"by_label"`
+	Trend        []*QualityMetrics `json:"trend"` // oldest cycle first
+}
+
+// Reopens returns how many times an issue moved from done back to another
+// status.
+func (h *History) Reopens(issueID string) int {
+	n := 0
+	for _, e := range h.byIssue[issueID] {
+		if e.Field == db.FieldStatus && e.OldValue == "done" && e.NewValue != "done" {
+			n++
+		}
+	}
+	return n
+}
+
+// CalculateQuality reports bug, reopen and escaped-bug rates. An issue is
+// a bug when it carries bugLabel and escaped when it also carries
+// escapedLabel. The trend covers the last lastCycles cycles by start date.
+func CalculateQuality(issues []*db.Issue, history *History, cycles []*db.Cycle, bugLabel, escapedLabel string, lastCycles int) *QualityReport {
+	r := &QualityReport{
+		BugLabel:     bugLabel,
+		EscapedLabel: escapedLabel,
+		Overall:      &QualityMetrics{},
+		ByLabel:      []*QualityMetrics{},
+		Trend:        []*QualityMetrics{},
+	}
+
+	sorted := append([]*db.Cycle{}, cycles...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return cycleStart(sorted[i].StartDate).Before(cycleStart(sorted[j].StartDate))
+	})
+	if lastCycles > 0 && len(sorted) > lastCycles {
+		sorted = sorted[len(sorted)-lastCycles:]
+	}
+	byCycle := make(map[string]*QualityMetrics, len(sorted))
+	for _, c := range sorted {
+		q := &QualityMetrics{CycleID: c.ID, CycleName: c.Name, StartDate: c.StartDate, EndDate: c.EndDate}
+		byCycle[c.ID] = q
+		r.Trend = append(r.Trend, q)
+	}
+
+	byLabel := make(map[string]*QualityMetrics)
+	for _, issue := range issues {
+		if issue.Status == "canceled" {
+			continue
+		}
+
+		rows := []*QualityMetrics{r.Overall}
+		if q, ok := byCycle[issue.CycleID]; ok {
+			rows = append(rows, q)
+		}
+		for _, label := range issue.Labels {
+			q, ok := byLabel[label]
+			if !ok {
+				q = &QualityMetrics{Label: label}
+				byLabel[label] = q
+				r.ByLabel = append(r.ByLabel, q)
+			}
+			rows = append(rows, q)
+		}
+
+		bug := hasLabel(issue, bugLabel)
+		escaped := bug && hasLabel(issue, escapedLabel)
+		reopens := history.Reopens(issue.ID)
+		completed := issue.Status == "done" || reopens > 0
+		for _, q := range rows {
+			q.Issues++
+			if bug {
+				q.BugCount++
+			}
+			if escaped {
+				q.EscapedBugs++
+			}
+			if completed {
+				q.Completed++
+			}
+			if reopens > 0 {
+				q.Reopened++
+			}
+		}
+	}
+
+	for _, q := range append(append([]*QualityMetrics{r.Overall}, r.ByLabel...), r.Trend...) {
+		if q.Issues > 0 {
+			q.BugRate = float64(q.BugCount) / float64(q.Issues) * 100
+		}
+		if q.Completed > 0 {
+			q.ReopenRate = float64(q.Reopened) / float64(q.Completed) * 100
+		}
+	}
+	sort.Slice(r.ByLabel, func(i, j int) bool { return r.ByLabel[i].Label < r.ByLabel[j].Label })
+
+	return r
+}
+
+func hasLabel(issue *db.Issue, label string) bool {
+	for _, l := range issue.Labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
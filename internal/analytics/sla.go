@@ -0,0 +1,203 @@
+package analytics
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+// Deadline sources.
+const (
+	DeadlineSLA     = "sla"
+	DeadlineDueDate = "due_date"
+)
+
+// SLA states of an issue.
+const (
+	SLANone     = "none"     // no due date or SLA target
+	SLAOnTrack  = "on_track" // open, deadline comfortably ahead
+	SLAAtRisk   = "at_risk"  // open, less than AtRiskFraction of the time left
+	SLAOverdue  = "overdue"  // open, deadline passed
+	SLAMet      = "met"      // done by the deadline
+	SLABreached = "breached" // done after the deadline
+)
+
+// AtRiskFraction is the share of the time between creation and deadline
+// below which an open issue counts as at risk.
+const AtRiskFraction = 0.25
+
+// Deadline is a point by which an issue must be resolved.
+type Deadline struct {
+	Source string    `json:"source"`
+	At     time.Time `json:"at"`
+}
+
+// IssueDeadlines returns the deadlines that apply to an issue: the end of
+// its due date, and its creation time plus the SLA target for its
+// priority.
+func IssueDeadlines(issue *db.Issue, settings *db.WorkspaceSettings) []Deadline {
+	var deadlines []Deadline
+	if target := settings.SLATarget(issue.Priority); target > 0 {
+		deadlines = append(deadlines, Deadline{Source: DeadlineSLA, At: issue.CreatedAt.Add(target)})
+	}
+	if issue.DueDate != nil {
+		deadlines = append(deadlines, Deadline{Source: DeadlineDueDate, At: dueEnd(*issue.DueDate)})
+	}
+	return deadlines
+}
+
+// dueEnd returns the end of a due date: an issue due on a day may be
+// resolved at any time that day (UTC).
+func dueEnd(due time.Time) time.Time {
+	return utcDay(due).AddDate(0, 0, 1)
+}
+
+// SLAState classifies an issue against its earliest deadline as of now.
+// Canceled issues have no state.
+func SLAState(issue *db.Issue, settings *db.WorkspaceSettings, now time.Time) string {
+	deadlines := IssueDeadlines(issue, settings)
+	if len(deadlines) == 0 || issue.Status == "canceled" {
+		return SLANone
+	}
+
+	deadline := deadlines[0].At
+	for _, d := range deadlines[1:] {
+		if d.At.Before(deadline) {
+			deadline = d.At
+		}
+	}
+
+	if issue.Status == "done" {
+		if issue.CompletedAt != nil && issue.CompletedAt.After(deadline) {
+			return SLABreached
+		}
+		return SLAMet
+	}
+	if now.After(deadline) {
+		return SLAOverdue
+	}
+	allowed := deadline.Sub(issue.CreatedAt)
+	if deadline.Sub(now) < time.Duration(float64(allowed)*AtRiskFraction) {
+		return SLAAtRisk
+	}
+	return SLAOnTrack
+}
+
+// Breach is a deadline an issue missed.
+type Breach struct {
+	IssueID     string
+	WorkspaceID string
+	Deadline
+}
+
+// FindBreaches returns every deadline missed as of now: by open issues
+// past it, and by issues completed after it.
+func FindBreaches(issues []*db.Issue, settings *db.WorkspaceSettings, now time.Time) []Breach {
+	var breaches []Breach
+	for _, issue := range issues {
+		if issue.Status == "canceled" {
+			continue
+		}
+		for _, d := range IssueDeadlines(issue, settings) {
+			resolved := now
+			if issue.Status == "done" && issue.CompletedAt != nil {
+				resolved = *issue.CompletedAt
+			}
+			if resolved.After(d.At) {
+				breaches = append(breaches, Breach{IssueID: issue.ID, WorkspaceID: issue.WorkspaceID, Deadline: d})
+			}
+		}
+	}
+	return breaches
+}
+
+// SLAAttainment counts how issues fared against their deadlines.
+type SLAAttainment struct {
+	Label    string `json:"label,omitempty"`
+	Resolved int    `json:"resolved"` // completed in the window with a deadline
+	Met      int    `json:"met"`
+	Breached int    `json:"breached"`
+	// Attainment is the share of resolved issues that met their
+	// deadline, 0-100.
+	Attainment float64 `json:"attainment"`
+	Open       int     `json:"open"` // open issues with a deadline
+	AtRisk     int     `json:"at_risk"`
+	Overdue    int     `json:"overdue"`
+}
+
+// SLAReport is SLA attainment overall and for each label.
+type SLAReport struct {
+	Since   time.Time        `json:"since"`
+	Targets map[string]int   `json:"targets"` // hours by priority name
+	Overall *SLAAttainment   `json:"overall"`
+	ByLabel []*SLAAttainment `json:"by_label"`
+}
+
+// CalculateSLAAttainment measures issues resolved since the given time,
+// and currently open issues, against their deadlines. Issues count once
+// for each of their labels.
+func CalculateSLAAttainment(issues []*db.Issue, settings *db.WorkspaceSettings, since, now time.Time) *SLAReport {
+	r := &SLAReport{
+		Since:   since,
+		Targets: settings.SLAHours,
+		Overall: &SLAAttainment{},
+		ByLabel: []*SLAAttainment{},
+	}
+	if r.Targets == nil {
+		r.Targets = map[string]int{}
+	}
+
+	byLabel := make(map[string]*SLAAttainment)
+	for _, issue := range issues {
+		state := SLAState(issue, settings, now)
+		if state == SLANone {
+			continue
+		}
+		if (state == SLAMet || state == SLABreached) && (issue.CompletedAt == nil || issue.CompletedAt.Before(since)) {
+			continue
+		}
+
+		rows := []*SLAAttainment{r.Overall}
+		for _, label := range issue.Labels {
+			row, ok := byLabel[label]
+			if !ok {
+				row = &SLAAttainment{Label: label}
+				byLabel[label] = row
+				r.ByLabel = append(r.ByLabel, row)
+			}
+			rows = append(rows, row)
+		}
+		for _, row := range rows {
+			row.count(state)
+		}
+	}
+
+	for _, row := range append(r.ByLabel, r.Overall) {
+		if row.Resolved > 0 {
+			row.Attainment = float64(row.Met) / float64(row.Resolved) * 100
+		}
+	}
+	sort.Slice(r.ByLabel, func(i, j int) bool { return r.ByLabel[i].Label < r.ByLabel[j].Label })
+
+	return r
+}
+
+func (a *SLAAttainment) count(state string) {
+	switch state {
+	case SLAMet:
+		a.Resolved++
+		a.Met++
+	case SLABreached:
+		a.Resolved++
+		a.Breached++
+	case SLAAtRisk:
+		a.Open++
+		a.AtRisk++
+	case SLAOverdue:
+		a.Open++
+		a.Overdue++
+	default:
+		a.Open++
+	}
+}
@@ -0,0 +1,387 @@
+package analytics
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+// Timeline item kinds.
+const (
+	KindIssue     = "issue"
+	KindProject   = "project"
+	KindMilestone = "milestone"
+)
+
+// TimelineItem is one bar on the timeline. Start and Due are the dates set
+// by the team; the Earliest and Latest fields are the schedule computed
+// from dependencies with the critical path method.
+type TimelineItem struct {
+	ID          string     `json:"id"`
+	Kind        string     `json:"kind"`
+	Key         string     `json:"key,omitempty"`
+	Title       string     `json:"title"`
+	Status      string     `json:"status"`
+	AssigneeID  string     `json:"assignee_id,omitempty"`
+	ProjectID   string     `json:"project_id,omitempty"`
+	MilestoneID string     `json:"milestone_id,omitempty"`
+	Start       *time.Time `json:"start"`
+	Due         *time.Time `json:"due"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+
+	DurationDays   int       `json:"duration_days"`
+	EarliestStart  time.Time `json:"earliest_start"`
+	EarliestFinish time.Time `json:"earliest_finish"`
+	LatestStart    time.Time `json:"latest_start"`
+	LatestFinish   time.Time `json:"latest_finish"`
+	// SlackDays is how far the item can slip without delaying the end of
+	// the schedule. Critical items have none.
+	SlackDays int  `json:"slack_days"`
+	Critical  bool `json:"critical"`
+
+	// BlockedBy lists the issues that must finish first.
+	BlockedBy []string `json:"blocked_by"`
+	// Late is set when the item is scheduled to finish after the end of
+	// its due date.
+	Late bool `json:"late"`
+	// Conflicts lists the dependencies that finish after the item is due.
+	Conflicts []string `json:"conflicts"`
+}
+
+// TimelineEdge is a dependency: From must finish before To can start.
+type TimelineEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Timeline is the scheduled view of a set of issues with their projects
+// and milestones.
+type Timeline struct {
+	Start        time.Time       `json:"start"`
+	End          time.Time       `json:"end"`
+	Items        []*TimelineItem `json:"items"`
+	Edges        []TimelineEdge  `json:"edges"`
+	CriticalPath []string        `json:"critical_path"`
+}
+
+// PointDays is the number of days one estimate point is assumed to take
+// when an issue has no start and due date to size it.
+const PointDays = 1
+
+// BuildTimeline schedules issues as of now. Open issues start no earlier
+// than today, their start date and the finish of every open issue
+// blocking them. An issue with both a start and a due date lasts that
+// long; otherwise its duration comes from its estimate at PointDays per
+// point, with a minimum of one day. Done issues keep their actual dates
+// and do not hold up their dependents; canceled issues and relations
+// other than blocks are ignored.
+//
+// Projects and milestones span their issues and are late when their
+// issues are scheduled to finish after the target date.
+func BuildTimeline(issues []*db.Issue, relations []*db.Relation, projects []*db.Project, milestones []*db.Milestone, now time.Time) *Timeline {
+	today := utcDay(now)
+	t := &Timeline{Items: []*TimelineItem{}, Edges: []TimelineEdge{}, CriticalPath: []string{}}
+
+	byID := make(map[string]*TimelineItem)
+	var open []*TimelineItem
+	for _, issue := range issues {
+		if issue.Status == "canceled" {
+			continue
+		}
+		item := &TimelineItem{
+			ID:           issue.ID,
+			Kind:         KindIssue,
+			Key:          issue.Key,
+			Title:        issue.Title,
+			Status:       issue.Status,
+			AssigneeID:   issue.AssigneeID,
+			ProjectID:    issue.ProjectID,
+			MilestoneID:  issue.MilestoneID,
+			Start:        issue.StartDate,
+			Due:          issue.DueDate,
+			CompletedAt:  issue.CompletedAt,
+			DurationDays: issueDuration(issue),
+			BlockedBy:    []string{},
+			Conflicts:    []string{},
+		}
+		byID[issue.ID] = item
+		t.Items = append(t.Items, item)
+		if issue.Status != "done" {
+			open = append(open, item)
+		}
+	}
+
+	successors := make(map[string][]string)
+	for _, rel := range relations {
+		if rel.Type != db.RelationBlocks {
+			continue
+		}
+		from, to := byID[rel.IssueID], byID[rel.RelatedIssueID]
+		if from == nil || to == nil {
+			continue
+		}
+		t.Edges = append(t.Edges, TimelineEdge{From: from.ID, To: to.ID})
+		to.BlockedBy = append(to.BlockedBy, from.ID)
+		successors[from.ID] = append(successors[from.ID], to.ID)
+	}
+
+	// Done issues are fixed at their actual dates.
+	for _, item := range t.Items {
+		if item.Status != "done" {
+			continue
+		}
+		finish := today
+		if item.CompletedAt != nil {
+			finish = utcDay(*item.CompletedAt)
+		}
+		start := finish.AddDate(0, 0, -item.DurationDays)
+		if item.Start != nil {
+			start = utcDay(*item.Start)
+		}
+		item.EarliestStart, item.LatestStart = start, start
+		item.EarliestFinish, item.LatestFinish = finish, finish
+	}
+
+	order := topoSort(open, byID, successors)
+
+	// Forward pass: earliest dates.
+	for _, item := range order {
+		start := today
+		if item.Start != nil && utcDay(*item.Start).After(start) {
+			start = utcDay(*item.Start)
+		}
+		for _, id := range item.BlockedBy {
+			blocker := byID[id]
+			if blocker.Status != "done" && blocker.EarliestFinish.After(start) {
+				start = blocker.EarliestFinish
+			}
+		}
+		item.EarliestStart = start
+		item.EarliestFinish = start.AddDate(0, 0, item.DurationDays)
+	}
+
+	end := today
+	for _, item := range open {
+		if item.EarliestFinish.After(end) {
+			end = item.EarliestFinish
+		}
+	}
+
+	// Backward pass: latest dates that still meet the end of the schedule.
+	for i := len(order) - 1; i >= 0; i-- {
+		item := order[i]
+		finish := end
+		for _, id := range successors[item.ID] {
+			next := byID[id]
+			if next.Status != "done" && next.LatestStart.Before(finish) {
+				finish = next.LatestStart
+			}
+		}
+		item.LatestFinish = finish
+		item.LatestStart = finish.AddDate(0, 0, -item.DurationDays)
+		item.SlackDays = int(item.LatestStart.Sub(item.EarliestStart).Hours() / 24)
+		item.Critical = item.SlackDays == 0
+	}
+
+	for _, item := range t.Items {
+		if item.Due == nil {
+			continue
+		}
+		due := dueEnd(*item.Due)
+		item.Late = item.EarliestFinish.After(due)
+		for _, id := range item.BlockedBy {
+			if byID[id].EarliestFinish.After(due) {
+				item.Conflicts = append(item.Conflicts, id)
+			}
+		}
+	}
+
+	t.CriticalPath = criticalPath(order, byID)
+
+	t.Items = append(t.Items, groupItems(t.Items, projects, milestones)...)
+
+	t.Start, t.End = today, end
+	for _, item := range t.Items {
+		// Empty groups without a target date have no dates to place.
+		if item.EarliestStart.IsZero() {
+			continue
+		}
+		if item.EarliestStart.Before(t.Start) {
+			t.Start = item.EarliestStart
+		}
+		if item.EarliestFinish.After(t.End) {
+			t.End = item.EarliestFinish
+		}
+	}
+
+	return t
+}
+
+// utcDay truncates t to midnight UTC, the resolution of the schedule.
+func utcDay(t time.Time) time.Time {
+	return startOfDay(t.UTC())
+}
+
+// issueDuration returns the scheduled length of an issue in days.
+func issueDuration(issue *db.Issue) int {
+	if issue.StartDate != nil && issue.DueDate != nil {
+		days := int(utcDay(*issue.DueDate).Sub(utcDay(*issue.StartDate)).Hours() / 24)
+		if days >= 1 {
+			return days
+		}
+		return 1
+	}
+	if issue.Estimate > 0 {
+		return issue.Estimate * PointDays
+	}
+	return 1
+}
+
+// topoSort orders open items so every item comes after its open blockers.
+// Items caught in a dependency loop, which validation normally prevents,
+// are appended in their original order.
+func topoSort(open []*TimelineItem, byID map[string]*TimelineItem, successors map[string][]string) []*TimelineItem {
+	indegree := make(map[string]int, len(open))
+	for _, item := range open {
+		for _, id := range item.BlockedBy {
+			if byID[id].Status != "done" {
+				indegree[item.ID]++
+			}
+		}
+	}
+
+	var queue, order []*TimelineItem
+	for _, item := range open {
+		if indegree[item.ID] == 0 {
+			queue = append(queue, item)
+		}
+	}
+	placed := make(map[string]bool, len(open))
+	for len(queue) > 0 {
+		item := queue[0]
+		queue = queue[1:]
+		order = append(order, item)
+		placed[item.ID] = true
+		for _, id := range successors[item.ID] {
+			next := byID[id]
+			if next.Status == "done" {
+				continue
+			}
+			indegree[id]--
+			if indegree[id] == 0 {
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	for _, item := range open {
+		if !placed[item.ID] {
+			order = append(order, item)
+		}
+	}
+	return order
+}
+
+// criticalPath follows the longest chain of critical items, from the one
+// finishing last back through the blocker that drives each start.
+func criticalPath(order []*TimelineItem, byID map[string]*TimelineItem) []string {
+	var last *TimelineItem
+	for _, item := range order {
+		if item.Critical && (last == nil || item.EarliestFinish.After(last.EarliestFinish)) {
+			last = item
+		}
+	}
+
+	path := []string{}
+	seen := make(map[string]bool)
+	for item := last; item != nil && !seen[item.ID]; {
+		seen[item.ID] = true
+		path = append(path, item.ID)
+
+		var driver *TimelineItem
+		for _, id := range item.BlockedBy {
+			blocker := byID[id]
+			if blocker.Status != "done" && blocker.Critical && blocker.EarliestFinish.Equal(item.EarliestStart) {
+				driver = blocker
+				break
+			}
+		}
+		item = driver
+	}
+
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// groupItems builds project and milestone items spanning their issues.
+func groupItems(issues []*TimelineItem, projects []*db.Project, milestones []*db.Milestone) []*TimelineItem {
+	var groups []*TimelineItem
+	members := make(map[string][]*TimelineItem)
+	for _, item := range issues {
+		if item.ProjectID != "" {
+			members[item.ProjectID] = append(members[item.ProjectID], item)
+		}
+		if item.MilestoneID != "" {
+			members[item.MilestoneID] = append(members[item.MilestoneID], item)
+		}
+	}
+
+	for _, p := range projects {
+		groups = append(groups, spanItem(&TimelineItem{
+			ID:     p.ID,
+			Kind:   KindProject,
+			Title:  p.Name,
+			Status: p.Status,
+			Due:    p.TargetDate,
+		}, members[p.ID]))
+	}
+	for _, m := range milestones {
+		groups = append(groups, spanItem(&TimelineItem{
+			ID:        m.ID,
+			Kind:      KindMilestone,
+			Title:     m.Name,
+			ProjectID: m.ProjectID,
+			Due:       m.TargetDate,
+		}, members[m.ID]))
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].EarliestStart.Before(groups[j].EarliestStart) })
+	return groups
+}
+
+// spanItem sets a group's dates from its members and lists the members
+// that finish after the group is due. An empty group sits at its due
+// date.
+func spanItem(g *TimelineItem, members []*TimelineItem) *TimelineItem {
+	g.BlockedBy = []string{}
+	g.Conflicts = []string{}
+
+	for i, item := range members {
+		if i == 0 || item.EarliestStart.Before(g.EarliestStart) {
+			g.EarliestStart = item.EarliestStart
+		}
+		if item.EarliestFinish.After(g.EarliestFinish) {
+			g.EarliestFinish = item.EarliestFinish
+		}
+		if g.Due != nil && item.Status != "done" && item.EarliestFinish.After(dueEnd(*g.Due)) {
+			g.Conflicts = append(g.Conflicts, item.ID)
+		}
+	}
+	if len(members) == 0 && g.Due != nil {
+		g.EarliestStart = utcDay(*g.Due)
+		g.EarliestFinish = g.EarliestStart
+	}
+	if !g.EarliestStart.IsZero() {
+		start := g.EarliestStart
+		g.Start = &start
+	}
+	g.LatestStart, g.LatestFinish = g.EarliestStart, g.EarliestFinish
+	g.DurationDays = int(g.EarliestFinish.Sub(g.EarliestStart).Hours() / 24)
+	if g.Due != nil {
+		g.Late = g.EarliestFinish.After(dueEnd(*g.Due))
+	}
+	return g
+}
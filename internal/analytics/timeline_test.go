@@ -0,0 +1,107 @@
+package analytics
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+func TestBuildTimeline(t *testing.T) {
+	now := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+	day := func(n int) time.Time { return utcDay(now).AddDate(0, 0, n) }
+	ptr := func(t time.Time) *time.Time { return &t }
+
+	issues := []*db.Issue{
+		{ID: "a", Status: "todo", Estimate: 3, ProjectID: "p"},
+		{ID: "b", Status: "in_progress", Estimate: 2, ProjectID: "p"},
+		{ID: "c", Status: "todo"},
+		{ID: "d", Status: "todo", DueDate: ptr(day(1))},
+		{ID: "e", Status: "done", Estimate: 2, CompletedAt: ptr(day(-2))},
+		{ID: "x", Status: "canceled", Estimate: 8},
+	}
+	relations := []*db.Relation{
+		{IssueID: "a", RelatedIssueID: "b", Type: db.RelationBlocks},
+		{IssueID: "a", RelatedIssueID: "d", Type: db.RelationBlocks},
+		// Done and canceled blockers do not hold anything up.
+		{IssueID: "e", RelatedIssueID: "b", Type: db.RelationBlocks},
+		{IssueID: "x", RelatedIssueID: "c", Type: db.RelationBlocks},
+		{IssueID: "c", RelatedIssueID: "d", Type: db.RelationRelates},
+	}
+	projects := []*db.Project{
+		{ID: "p", Name: "Scheduled", TargetDate: ptr(day(3))},
+		{ID: "empty", Name: "Undated and empty"},
+	}
+	milestones := []*db.Milestone{{ID: "m", Name: "Later", TargetDate: ptr(day(10))}}
+
+	tl := BuildTimeline(issues, relations, projects, milestones, now)
+
+	items := make(map[string]*TimelineItem)
+	for _, item := range tl.Items {
+		items[item.ID] = item
+	}
+	if items["x"] != nil {
+		t.Error("canceled issue is on the timeline")
+	}
+
+	for _, want := range []struct {
+		id                 string
+		start, finish      int
+		latestStart, slack int
+		critical, late     bool
+	}{
+		{"a", 0, 3, 0, 0, true, false},
+		{"b", 3, 5, 3, 0, true, false},
+		{"c", 0, 1, 4, 4, false, false},
+		{"d", 3, 4, 4, 1, false, true},
+		{"e", -4, -2, -4, 0, false, false},
+	} {
+		item := items[want.id]
+		if item == nil {
+			t.Errorf("%s: missing", want.id)
+			continue
+		}
+		if !item.EarliestStart.Equal(day(want.start)) || !item.EarliestFinish.Equal(day(want.finish)) {
+			t.Errorf("%s: earliest %s to %s, want %s to %s", want.id,
+				item.EarliestStart.Format(time.DateOnly), item.EarliestFinish.Format(time.DateOnly),
+				day(want.start).Format(time.DateOnly), day(want.finish).Format(time.DateOnly))
+		}
+		if want.id != "e" && (!item.LatestStart.Equal(day(want.latestStart)) || item.SlackDays != want.slack) {
+			t.Errorf("%s: latest start %s with %d days slack, want %s with %d",
+				want.id, item.LatestStart.Format(time.DateOnly), item.SlackDays, day(want.latestStart).Format(time.DateOnly), want.slack)
+		}
+		if item.Critical != want.critical || item.Late != want.late {
+			t.Errorf("%s: critical %v late %v, want %v and %v", want.id, item.Critical, item.Late, want.critical, want.late)
+		}
+	}
+
+	if !reflect.DeepEqual(tl.CriticalPath, []string{"a", "b"}) {
+		t.Errorf("critical path = %v, want [a b]", tl.CriticalPath)
+	}
+	if !reflect.DeepEqual(items["d"].Conflicts, []string{"a"}) {
+		t.Errorf("d conflicts = %v, want [a]", items["d"].Conflicts)
+	}
+	if len(tl.Edges) != 3 {
+		t.Errorf("%d edges, want the 3 blocks relations between scheduled issues", len(tl.Edges))
+	}
+
+	// The project spans its issues and b finishes after its target.
+	p := items["p"]
+	if !p.EarliestStart.Equal(day(0)) || !p.EarliestFinish.Equal(day(5)) || !p.Late || !reflect.DeepEqual(p.Conflicts, []string{"b"}) {
+		t.Errorf("project p = %s to %s, late %v, conflicts %v",
+			p.EarliestStart.Format(time.DateOnly), p.EarliestFinish.Format(time.DateOnly), p.Late, p.Conflicts)
+	}
+	if m := items["m"]; !m.EarliestStart.Equal(day(10)) || m.Late {
+		t.Errorf("empty milestone sits at %s, late %v; want its target date", m.EarliestStart.Format(time.DateOnly), m.Late)
+	}
+	if empty := items["empty"]; empty.Start != nil || !empty.EarliestStart.IsZero() {
+		t.Errorf("empty undated project has dates: %+v", empty)
+	}
+
+	// The undated empty project does not drag the start back to year one.
+	if !tl.Start.Equal(day(-4)) || !tl.End.Equal(day(10)) {
+		t.Errorf("timeline spans %s to %s, want %s to %s",
+			tl.Start.Format(time.DateOnly), tl.End.Format(time.DateOnly), day(-4).Format(time.DateOnly), day(10).Format(time.DateOnly))
+	}
+}
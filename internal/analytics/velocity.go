@@ -0,0 +1,63 @@
+package analytics
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+// VelocityMetrics summarises the points planned and delivered in a cycle.
+type VelocityMetrics struct {
+	CycleID         string     `json:"cycle_id"`
+	CycleName       string     `json:"cycle_name"`
+	StartDate       *time.Time `json:"start_date"`
+	EndDate         *time.Time `json:"end_date"`
+	PointsPlanned   int        `json:"points_planned"`
+	PointsCompleted int        `json:"points_completed"`
+	CompletionRate  float64    `json:"completion_rate"` // 0-100
+	CarryoverPoints int        `json:"carryover_points"`
+}
+
+// CalculateVelocity returns the velocity of each cycle, oldest first.
+// Canceled issues are not counted as planned work.
+func CalculateVelocity(cycles []*db.Cycle, issues []*db.Issue) []*VelocityMetrics {
+	byCycle := make(map[string]*VelocityMetrics, len(cycles))
+	result := make([]*VelocityMetrics, 0, len(cycles))
+	for _, c := range cycles {
+		v := &VelocityMetrics{CycleID: c.ID, CycleName: c.Name, StartDate: c.StartDate, EndDate: c.EndDate}
+		byCycle[c.ID] = v
+		result = append(result, v)
+	}
+
+	for _, issue := range issues {
+		v, ok := byCycle[issue.CycleID]
+		if !ok || issue.Status == "canceled" {
+			continue
+		}
+		v.PointsPlanned += issue.Estimate
+		if issue.Status == "done" {
+			v.PointsCompleted += issue.Estimate
+		}
+	}
+
+	for _, v := range result {
+		v.CarryoverPoints = v.PointsPlanned - v.PointsCompleted
+		if v.PointsPlanned > 0 {
+			v.CompletionRate = float64(v.PointsCompleted) / float64(v.PointsPlanned) * 100
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return cycleStart(result[i].StartDate).Before(cycleStart(result[j].StartDate))
+	})
+	return result
+}
+
+// cycleStart orders undated cycles after dated ones.
+func cycleStart(start *time.Time) time.Time {
+	if start == nil {
+		return time.Unix(1<<62, 0)
+	}
+	return *start
+}
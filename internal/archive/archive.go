@@ -0,0 +1,479 @@
+// Package archive exports and imports whole workspaces in a portable,
+// versioned JSON format so teams can move between Pulse servers.
+package archive
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+// FormatVersion is the archive format written by Export. Import accepts
+// archives up to this version. Version 2 added issue history.
+const FormatVersion = 2
+
+// Archive is a self-contained snapshot of one workspace.
+type Archive struct {
+	Version    int             `json:"version"`
+	ExportedAt time.Time       `json:"exported_at"`
+	Workspace  *db.Workspace   `json:"workspace"`
+	Cycles     []*db.Cycle     `json:"cycles"`
+	Capacities []*db.Capacity  `json:"capacities"`
+	Projects   []*db.Project   `json:"projects"`
+	Milestones []*db.Milestone `json:"milestones"`
+	Issues     []*db.Issue     `json:"issues"`
+	Labels     []string        `json:"labels"`
+	Relations  []*db.Relation  `json:"relations"`
+	Comments   []*db.Comment   `json:"comments"`
+	// Events is the issue history that velocity, cycle time, flow and SLA
+	// reports are computed from. Version 1 archives have none.
+	Events []*db.IssueEvent `json:"events"`
+}
+
+// ImportOptions controls how an archive is loaded.
+type ImportOptions struct {
+	// RemapIDs gives every imported entity a fresh ID so the archive can be
+	// loaded into an instance that already holds the same data.
+	RemapIDs bool
+	// TargetWorkspaceID merges the archive into an existing workspace
+	// instead of creating the archived one.
+	TargetWorkspaceID string
+}
+
+// ImportResult summarises an import.
+type ImportResult struct {
+	WorkspaceID string `json:"workspace_id"`
+	Cycles      int    `json:"cycles"`
+	Capacities  int    `json:"capacities"`
+	Projects    int    `json:"projects"`
+	Milestones  int    `json:"milestones"`
+	Issues      int    `json:"issues"`
+	Relations   int    `json:"relations"`
+	Comments    int    `json:"comments"`
+	Events      int    `json:"events"`
+}
+
+// Export reads a workspace and everything in it.
+func Export(database *db.DB, workspaceID string) (*Archive, error) {
+	ws, err := db.NewWorkspaceRepository(database).GetByID(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	if ws == nil {
+		return nil, fmt.Errorf("workspace %s not found", workspaceID)
+	}
+
+	cycles, err := db.NewCycleRepository(database).List(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	capacities, err := db.NewCapacityRepository(database).ListByWorkspace(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	projects, err := db.NewProjectRepository(database).List(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	milestones, err := db.NewMilestoneRepository(database).ListByWorkspace(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	issues, err := db.NewIssueRepository(database).List(workspaceID, "", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	relations, err := db.NewRelationRepository(database).ListByWorkspace(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := db.NewCommentRepository(database).ListByWorkspace(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	history, err := db.NewEventRepository(database).ListByWorkspace(workspaceID, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	// History of deleted issues is kept in the database but has nothing
+	// to attach to in an archive.
+	exported := make(map[string]bool, len(issues))
+	for _, issue := range issues {
+		exported[issue.ID] = true
+	}
+	events := make([]*db.IssueEvent, 0, len(history))
+	for _, e := range history {
+		if exported[e.IssueID] {
+			events = append(events, e)
+		}
+	}
+
+	labelSet := make(map[string]bool)
+	for _, issue := range issues {
+		for _, l := range issue.Labels {
+			labelSet[l] = true
+		}
+	}
+	labels := make([]string, 0, len(labelSet))
+	for l := range labelSet {
+		labels = append(labels, l)
+	}
+	sort.Strings(labels)
+
+	return &Archive{
+		Version:    FormatVersion,
+		ExportedAt: time.Now().UTC(),
+		Workspace:  ws,
+		Cycles:     nonNil(cycles),
+		Capacities: nonNil(capacities),
+		Projects:   nonNil(projects),
+		Milestones: nonNil(milestones),
+		Issues:     nonNil(issues),
+		Labels:     labels,
+		Relations:  nonNil(relations),
+		Comments:   nonNil(comments),
+		Events:     events,
+	}, nil
+}
+
+// Write encodes an archive as indented JSON.
+func Write(w io.Writer, a *Archive) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(a)
+}
+
+// Read decodes and validates an archive.
+func Read(r io.Reader) (*Archive, error) {
+	var a Archive
+	if err := json.NewDecoder(r).Decode(&a); err != nil {
+		return nil, fmt.Errorf("failed to decode archive: %w", err)
+	}
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// Validate checks the archive version and that every reference between
+// entities resolves within the archive.
+func (a *Archive) Validate() error {
+	if a.Version < 1 || a.Version > FormatVersion {
+		return fmt.Errorf("unsupported archive version %d (this build reads up to %d)", a.Version, FormatVersion)
+	}
+	if a.Workspace == nil || a.Workspace.ID == "" {
+		return fmt.Errorf("archive has no workspace")
+	}
+
+	cycles := make(map[string]bool, len(a.Cycles))
+	for _, c := range a.Cycles {
+		if cycles[c.ID] {
+			return fmt.Errorf("duplicate cycle %s", c.ID)
+		}
+		cycles[c.ID] = true
+	}
+	for _, c := range a.Capacities {
+		if !cycles[c.CycleID] {
+			return fmt.Errorf("capacity of %s references unknown cycle %s", c.MemberID, c.CycleID)
+		}
+	}
+
+	projects := make(map[string]bool, len(a.Projects))
+	for _, p := range a.Projects {
+		if projects[p.ID] {
+			return fmt.Errorf("duplicate project %s", p.ID)
+		}
+		projects[p.ID] = true
+	}
+
+	milestones := make(map[string]bool, len(a.Milestones))
+	for _, m := range a.Milestones {
+		if milestones[m.ID] {
+			return fmt.Errorf("duplicate milestone %s", m.ID)
+		}
+		if !projects[m.ProjectID] {
+			return fmt.Errorf("milestone %s references unknown project %s", m.ID, m.ProjectID)
+		}
+		milestones[m.ID] = true
+	}
+
+	issues := make(map[string]bool, len(a.Issues))
+	for _, issue := range a.Issues {
+		if issues[issue.ID] {
+			return fmt.Errorf("duplicate issue %s", issue.ID)
+		}
+		issues[issue.ID] = true
+	}
+
+	for _, issue := range a.Issues {
+		if issue.CycleID != "" && !cycles[issue.CycleID] {
+			return fmt.Errorf("issue %s references unknown cycle %s", issue.ID, issue.CycleID)
+		}
+		if issue.ParentID != "" && !issues[issue.ParentID] {
+			return fmt.Errorf("issue %s references unknown parent %s", issue.ID, issue.ParentID)
+		}
+		if issue.ProjectID != "" && !projects[issue.ProjectID] {
+			return fmt.Errorf("issue %s references unknown project %s", issue.ID, issue.ProjectID)
+		}
+		if issue.MilestoneID != "" && !milestones[issue.MilestoneID] {
+			return fmt.Errorf("issue %s references unknown milestone %s", issue.ID, issue.MilestoneID)
+		}
+	}
+	for _, rel := range a.Relations {
+		if !issues[rel.IssueID] || !issues[rel.RelatedIssueID] {
+			return fmt.Errorf("relation %s references an issue outside the archive", rel.ID)
+		}
+	}
+	for _, c := range a.Comments {
+		if !issues[c.IssueID] {
+			return fmt.Errorf("comment %s references unknown issue %s", c.ID, c.IssueID)
+		}
+	}
+	for _, e := range a.Events {
+		if !issues[e.IssueID] {
+			return fmt.Errorf("event %s references unknown issue %s", e.ID, e.IssueID)
+		}
+	}
+
+	return nil
+}
+
+// Import loads an archive in a single transaction. Without RemapIDs the
+// original IDs are kept and the import fails if any of them already exist.
+func Import(database *db.DB, a *Archive, opts ImportOptions) (*ImportResult, error) {
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+
+	ids := newIDMap(opts.RemapIDs)
+	result := &ImportResult{}
+
+	err := database.InTx(func(tx *db.Tx) error {
+		workspaces := db.NewWorkspaceRepository(tx)
+
+		if opts.TargetWorkspaceID != "" {
+			ws, err := workspaces.GetByID(opts.TargetWorkspaceID)
+			if err != nil {
+				return err
+			}
+			if ws == nil {
+				return fmt.Errorf("target workspace %s not found", opts.TargetWorkspaceID)
+			}
+			ids.set("ws", a.Workspace.ID, ws.ID)
+		} else {
+			ws := *a.Workspace
+			ws.ID = ids.get("ws", ws.ID)
+			if err := workspaces.Insert(&ws); err != nil {
+				return err
+			}
+		}
+		result.WorkspaceID = ids.get("ws", a.Workspace.ID)
+
+		cycles := db.NewCycleRepository(tx)
+		for _, c := range a.Cycles {
+			cycle := *c
+			cycle.ID = ids.get("cycle", c.ID)
+			cycle.WorkspaceID = result.WorkspaceID
+			if err := cycles.Insert(&cycle); err != nil {
+				return err
+			}
+			result.Cycles++
+		}
+
+		capacities := db.NewCapacityRepository(tx)
+		for _, c := range a.Capacities {
+			capacity := *c
+			capacity.CycleID = ids.get("cycle", c.CycleID)
+			if err := capacities.Insert(&capacity); err != nil {
+				return err
+			}
+			result.Capacities++
+		}
+
+		projects := db.NewProjectRepository(tx)
+		for _, p := range a.Projects {
+			project := *p
+			project.ID = ids.get("project", p.ID)
+			project.WorkspaceID = result.WorkspaceID
+			if err := projects.Insert(&project); err != nil {
+				return err
+			}
+			result.Projects++
+		}
+
+		milestones := db.NewMilestoneRepository(tx)
+		for _, m := range a.Milestones {
+			milestone := *m
+			milestone.ID = ids.get("milestone", m.ID)
+			milestone.ProjectID = ids.get("project", m.ProjectID)
+			if err := milestones.Insert(&milestone); err != nil {
+				return err
+			}
+			result.Milestones++
+		}
+
+		issues := db.NewIssueRepository(tx)
+		for _, i := range parentsFirst(a.Issues) {
+			issue := *i
+			issue.ID = ids.get("issue", i.ID)
+			issue.WorkspaceID = result.WorkspaceID
+			if i.CycleID != "" {
+				issue.CycleID = ids.get("cycle", i.CycleID)
+			}
+			if i.ParentID != "" {
+				issue.ParentID = ids.get("issue", i.ParentID)
+			}
+			if i.ProjectID != "" {
+				issue.ProjectID = ids.get("project", i.ProjectID)
+			}
+			if i.MilestoneID != "" {
+				issue.MilestoneID = ids.get("milestone", i.MilestoneID)
+			}
+			if opts.TargetWorkspaceID != "" {
+				// Renumber so merged issues cannot clash with existing ones.
+				issue.Number = 0
+			}
+			if err := issues.Insert(&issue); err != nil {
+				return err
+			}
+			result.Issues++
+		}
+
+		relations := db.NewRelationRepository(tx)
+		for _, r := range a.Relations {
+			rel := *r
+			rel.ID = ids.get("rel", r.ID)
+			rel.IssueID = ids.get("issue", r.IssueID)
+			rel.RelatedIssueID = ids.get("issue", r.RelatedIssueID)
+			if err := relations.Insert(&rel); err != nil {
+				return err
+			}
+			result.Relations++
+		}
+
+		comments := db.NewCommentRepository(tx)
+		for _, c := range a.Comments {
+			comment := *c
+			comment.ID = ids.get("comment", c.ID)
+			comment.IssueID = ids.get("issue", c.IssueID)
+			if err := comments.Insert(&comment); err != nil {
+				return err
+			}
+			result.Comments++
+		}
+
+		events := db.NewEventRepository(tx)
+		for _, e := range a.Events {
+			event := *e
+			event.ID = ids.get("event", e.ID)
+			event.IssueID = ids.get("issue", e.IssueID)
+			event.WorkspaceID = result.WorkspaceID
+			if kind, ok := referenceFields[e.Field]; ok {
+				event.OldValue = ids.lookup(kind, e.OldValue)
+				event.NewValue = ids.lookup(kind, e.NewValue)
+			}
+			if err := events.Record(&event); err != nil {
+				return err
+			}
+			result.Events++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("import failed: %w", err)
+	}
+
+	return result, nil
+}
+
+// referenceFields maps the history fields whose values are entity IDs to
+// the kind of entity they reference.
+var referenceFields = map[string]string{
+	db.FieldCycle:     "cycle",
+	db.FieldParent:    "issue",
+	db.FieldProject:   "project",
+	db.FieldMilestone: "milestone",
+}
+
+// parentsFirst orders issues so that every parent comes before its
+// sub-issues, keeping the archived order otherwise.
+func parentsFirst(issues []*db.Issue) []*db.Issue {
+	byID := make(map[string]*db.Issue, len(issues))
+	for _, issue := range issues {
+		byID[issue.ID] = issue
+	}
+
+	ordered := make([]*db.Issue, 0, len(issues))
+	placed := make(map[string]bool, len(issues))
+	var place func(issue *db.Issue)
+	place = func(issue *db.Issue) {
+		if placed[issue.ID] {
+			return
+		}
+		// Mark before recursing so a circular hierarchy cannot loop.
+		placed[issue.ID] = true
+		if parent, ok := byID[issue.ParentID]; ok {
+			place(parent)
+		}
+		ordered = append(ordered, issue)
+	}
+	for _, issue := range issues {
+		place(issue)
+	}
+	return ordered
+}
+
+// idMap translates archived IDs to the IDs used on import.
+type idMap struct {
+	remap bool
+	seq   int64
+	ids   map[string]string
+}
+
+func newIDMap(remap bool) *idMap {
+	return &idMap{remap: remap, seq: time.Now().UnixNano(), ids: make(map[string]string)}
+}
+
+func (m *idMap) set(kind, oldID, newID string) {
+	m.ids[kind+":"+oldID] = newID
+}
+
+// get returns the import ID for oldID, allocating one on first use. IDs
+// follow the server's <kind>_<nanos> convention.
+func (m *idMap) get(kind, oldID string) string {
+	key := kind + ":" + oldID
+	if id, ok := m.ids[key]; ok {
+		return id
+	}
+	id := oldID
+	if m.remap {
+		m.seq++
+		id = fmt.Sprintf("%s_%d", kind, m.seq)
+	}
+	m.ids[key] = id
+	return id
+}
+
+// lookup returns the import ID of an entity already imported, or oldID
+// when the archive does not hold it, such as a cycle since deleted.
+func (m *idMap) lookup(kind, oldID string) string {
+	if id, ok := m.ids[kind+":"+oldID]; ok {
+		return id
+	}
+	return oldID
+}
+
+// nonNil turns a nil slice into an empty one so archives always encode
+// lists as [] rather than null.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
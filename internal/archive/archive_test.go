@@ -0,0 +1,185 @@
+package archive
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+func openDB(t *testing.T) *db.DB {
+	t.Helper()
+	database, err := db.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if err := database.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	return database
+}
+
+// seed creates a workspace whose sub-issue sorts ahead of its parent on
+// export, with history on both.
+func seed(t *testing.T, database *db.DB) (workspaceID, parentID, childID, cycleID string) {
+	t.Helper()
+	ws := &db.Workspace{ID: "ws_1", Name: "Source"}
+	if err := db.NewWorkspaceRepository(database).Create(ws); err != nil {
+		t.Fatal(err)
+	}
+	cycle := &db.Cycle{ID: "cycle_1", WorkspaceID: ws.ID, Name: "Sprint 1", Status: "active"}
+	if err := db.NewCycleRepository(database).Create(cycle); err != nil {
+		t.Fatal(err)
+	}
+
+	issues := db.NewIssueRepository(database)
+	parent := &db.Issue{ID: "issue_parent", WorkspaceID: ws.ID, Title: "Parent", Status: "todo", Priority: 4}
+	if err := issues.Create(parent); err != nil {
+		t.Fatal(err)
+	}
+	child := &db.Issue{ID: "issue_child", WorkspaceID: ws.ID, Title: "Child", Status: "todo", Priority: 1, ParentID: parent.ID}
+	if err := issues.Create(child); err != nil {
+		t.Fatal(err)
+	}
+
+	child.CycleID = cycle.ID
+	child.Status = "in_progress"
+	if err := issues.Update(child); err != nil {
+		t.Fatal(err)
+	}
+	if err := issues.UpdateStatus(child.ID, "done"); err != nil {
+		t.Fatal(err)
+	}
+	return ws.ID, parent.ID, child.ID, cycle.ID
+}
+
+func roundTrip(t *testing.T, a *Archive) *Archive {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Write(&buf, a); err != nil {
+		t.Fatal(err)
+	}
+	read, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return read
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	src := openDB(t)
+	workspaceID, parentID, childID, _ := seed(t, src)
+
+	a, err := Export(src, workspaceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Issues[0].ID != childID {
+		t.Fatalf("expected the sub-issue to be exported first, got %s", a.Issues[0].ID)
+	}
+	if len(a.Events) == 0 {
+		t.Fatal("export has no issue history")
+	}
+
+	dst := openDB(t)
+	result, err := Import(dst, roundTrip(t, a), ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Issues != 2 || result.Events != len(a.Events) {
+		t.Errorf("imported %d issues and %d events, want 2 and %d", result.Issues, result.Events, len(a.Events))
+	}
+
+	child, err := db.NewIssueRepository(dst).GetByID(childID)
+	if err != nil || child == nil {
+		t.Fatalf("child not imported: %v", err)
+	}
+	if child.ParentID != parentID {
+		t.Errorf("child parent = %q, want %q", child.ParentID, parentID)
+	}
+
+	want, err := db.NewEventRepository(src).ListByIssue(childID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := db.NewEventRepository(dst).ListByIssue(childID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("child history has %d events, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Field != want[i].Field || got[i].NewValue != want[i].NewValue || !got[i].CreatedAt.Equal(want[i].CreatedAt) {
+			t.Errorf("event %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestImportRemapsHistory(t *testing.T) {
+	database := openDB(t)
+	workspaceID, _, childID, cycleID := seed(t, database)
+
+	a, err := Export(database, workspaceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := Import(database, a, ImportOptions{RemapIDs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cycles, err := db.NewCycleRepository(database).List(result.WorkspaceID)
+	if err != nil || len(cycles) != 1 {
+		t.Fatalf("imported cycles = %v, %v", cycles, err)
+	}
+	events, err := db.NewEventRepository(database).ListByField(result.WorkspaceID, db.FieldCycle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("imported %d cycle events, want 1", len(events))
+	}
+	e := events[0]
+	if e.IssueID == childID || e.NewValue != cycles[0].ID || e.NewValue == cycleID {
+		t.Errorf("cycle event not remapped: %+v (new cycle %s)", e, cycles[0].ID)
+	}
+}
+
+func TestImportVersion1(t *testing.T) {
+	database := openDB(t)
+	workspaceID, _, _, _ := seed(t, database)
+
+	a, err := Export(database, workspaceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Version = 1
+	a.Events = nil
+
+	result, err := Import(openDB(t), roundTrip(t, a), ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Issues != 2 || result.Events != 0 {
+		t.Errorf("imported %d issues and %d events", result.Issues, result.Events)
+	}
+}
+
+func TestParentsFirst(t *testing.T) {
+	issues := []*db.Issue{
+		{ID: "c", ParentID: "b"},
+		{ID: "b", ParentID: "a"},
+		{ID: "d"},
+		{ID: "a"},
+	}
+	var order []string
+	for _, issue := range parentsFirst(issues) {
+		order = append(order, issue.ID)
+	}
+	if got := strings.Join(order, ","); got != "a,b,c,d" {
+		t.Errorf("order = %s, want a,b,c,d", got)
+	}
+}
@@ -0,0 +1,270 @@
+// Package client talks to a running Pulse server over its REST API.
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+// DefaultServer is used when no server is configured.
+const DefaultServer = "http://localhost:3002"
+
+// Config holds the connection settings for the CLI client.
+type Config struct {
+	// Server is the base URL of the Pulse server.
+	Server string `json:"server"`
+	// Token is sent as a bearer token, for servers behind an
+	// authenticating proxy.
+	Token string `json:"token"`
+	// Workspace is the default workspace for commands.
+	Workspace string `json:"workspace"`
+	// User is the assignee ID that "me" refers to.
+	User string `json:"user"`
+}
+
+// ConfigPath returns the location of the client config file,
+// $PULSE_CONFIG or pulse/config.json in the user config directory.
+func ConfigPath() string {
+	if path := os.Getenv("PULSE_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "pulse", "config.json")
+}
+
+// LoadConfig reads the config file, then applies the PULSE_SERVER,
+// PULSE_TOKEN, PULSE_WORKSPACE and PULSE_USER environment variables on
+// top of it. A missing config file is not an error.
+func LoadConfig() (*Config, error) {
+	cfg := &Config{}
+
+	if path := ConfigPath(); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read config %s: %w", path, err)
+		}
+		if err == nil {
+			if err := json.Unmarshal(data, cfg); err != nil {
+				return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+			}
+		}
+	}
+
+	for env, field := range map[string]*string{
+		"PULSE_SERVER":    &cfg.Server,
+		"PULSE_TOKEN":     &cfg.Token,
+		"PULSE_WORKSPACE": &cfg.Workspace,
+		"PULSE_USER":      &cfg.User,
+	} {
+		if v := os.Getenv(env); v != "" {
+			*field = v
+		}
+	}
+
+	if cfg.Server == "" {
+		cfg.Server = DefaultServer
+	}
+	if cfg.Workspace == "" {
+		cfg.Workspace = "default"
+	}
+	return cfg, nil
+}
+
+// Client is a REST client for one Pulse server.
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// New creates a client for the server in cfg.
+func New(cfg *Config) *Client {
+	return &Client{
+		baseURL: strings.TrimSuffix(cfg.Server, "/"),
+		token:   cfg.Token,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// IssueFilter narrows ListIssues.
+type IssueFilter struct {
+	WorkspaceID string
+	Status      string
+	AssigneeID  string
+	Label       string
+	Limit       int
+}
+
+// ListIssues returns the issues in a workspace matching the filter.
+func (c *Client) ListIssues(f IssueFilter) ([]*db.Issue, error) {
+	q := url.Values{}
+	q.Set("workspace_id", f.WorkspaceID)
+	if f.Status != "" {
+		q.Set("status", f.Status)
+	}
+
+	var issues []*db.Issue
+	if err := c.do(http.MethodGet, "/api/issues?"+q.Encode(), nil, &issues); err != nil {
+		return nil, err
+	}
+
+	// The list endpoint only filters by status; narrow the rest here.
+	var result []*db.Issue
+	for _, issue := range issues {
+		if f.AssigneeID != "" && issue.AssigneeID != f.AssigneeID {
+			continue
+		}
+		if f.Label != "" && !hasLabel(issue, f.Label) {
+			continue
+		}
+		result = append(result, issue)
+		if f.Limit > 0 && len(result) == f.Limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// GetIssue fetches a single issue.
+func (c *Client) GetIssue(id string) (*db.Issue, error) {
+	var issue db.Issue
+	if err := c.do(http.MethodGet, "/api/issues/"+url.PathEscape(id), nil, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// CreateIssue creates an issue and returns it as stored by the server.
+func (c *Client) CreateIssue(issue *db.Issue) (*db.Issue, error) {
+	var created db.Issue
+	if err := c.do(http.MethodPost, "/api/issues", issue, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// UpdateIssue applies the given fields to an issue. Keys use the JSON
+// names of db.Issue.
+func (c *Client) UpdateIssue(id string, fields map[string]interface{}) (*db.Issue, error) {
+	var issue db.Issue
+	if err := c.do(http.MethodPut, "/api/issues/"+url.PathEscape(id), fields, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// MoveIssue changes an issue's status.
+func (c *Client) MoveIssue(id, status string) (*db.Issue, error) {
+	var issue db.Issue
+	body := map[string]string{"status": status}
+	if err := c.do(http.MethodPatch, "/api/issues/"+url.PathEscape(id), body, &issue); err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// DeleteIssue deletes an issue with its comments and relations. Its
+// sub-issues are deleted too when cascade is set, and otherwise move up to
+// the issue's parent.
+func (c *Client) DeleteIssue(id string, cascade bool) error {
+	path := "/api/issues/" + url.PathEscape(id)
+	if cascade {
+		path += "?children=cascade"
+	}
+	return c.do(http.MethodDelete, path, nil, nil)
+}
+
+// IssueTree fetches an issue with its sub-issues nested depth levels deep
+// (zero for all) and their rolled-up progress.
+func (c *Client) IssueTree(id string, depth int) (*db.IssueNode, error) {
+	var node db.IssueNode
+	path := fmt.Sprintf("/api/issues/%s/tree?depth=%d", url.PathEscape(id), depth)
+	if err := c.do(http.MethodGet, path, nil, &node); err != nil {
+		return nil, err
+	}
+	return &node, nil
+}
+
+// ListComments returns the comments on an issue, oldest first.
+func (c *Client) ListComments(issueID string) ([]*db.Comment, error) {
+	var comments []*db.Comment
+	if err := c.do(http.MethodGet, "/api/issues/"+url.PathEscape(issueID)+"/comments", nil, &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// AddComment posts a comment on an issue.
+func (c *Client) AddComment(issueID, authorID, body string) (*db.Comment, error) {
+	var comment db.Comment
+	req := map[string]string{"author_id": authorID, "body": body}
+	if err := c.do(http.MethodPost, "/api/issues/"+url.PathEscape(issueID)+"/comments", req, &comment); err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// do sends a JSON request and decodes the JSON response into out, which
+// may be nil. Non-2xx responses become errors carrying the server message.
+func (c *Client) do(method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", c.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+func hasLabel(issue *db.Issue, label string) bool {
+	for _, l := range issue.Labels {
+		if strings.EqualFold(l, label) {
+			return true
+		}
+	}
+	return false
+}
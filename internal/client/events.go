@@ -0,0 +1,82 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+// Event is a change notification from the server's event stream.
+type Event struct {
+	Type        string      `json:"type"`
+	WorkspaceID string      `json:"workspace_id"`
+	IssueID     string      `json:"issue_id"`
+	Issue       *db.Issue   `json:"issue,omitempty"`
+	Comment     *db.Comment `json:"comment,omitempty"`
+	Time        time.Time   `json:"time"`
+}
+
+// Subscribe streams events for a workspace to fn until ctx is cancelled
+// or the connection drops. It returns once the stream ends; callers
+// wanting a permanent feed should call it again after a delay.
+func (c *Client) Subscribe(ctx context.Context, workspaceID string, fn func(Event)) error {
+	q := url.Values{}
+	q.Set("workspace_id", workspaceID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/events?"+q.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	// The stream is long-lived, so it cannot share the request timeout.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", c.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("event stream: %s", resp.Status)
+	}
+
+	var data strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if data.Len() > 0 {
+				var e Event
+				if err := json.Unmarshal([]byte(data.String()), &e); err == nil {
+					fn(e)
+				}
+				data.Reset()
+			}
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("event stream closed")
+}
@@ -0,0 +1,246 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	sqlite3 "github.com/mattn/go-sqlite3"
+)
+
+// snapshotPrefix and snapshotLayout name the files written by Snapshot.
+const (
+	snapshotPrefix = "pulse-"
+	snapshotLayout = "20060102T150405.000Z"
+)
+
+// BackupInfo describes a backup file on disk.
+type BackupInfo struct {
+	Name      string    `json:"name"`
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Backup writes a consistent copy of the live database to dest using
+// VACUUM INTO, which is safe while other connections are writing, and then
+// verifies the copy with PRAGMA integrity_check. dest must not exist.
+func (db *DB) Backup(dest string) (*BackupInfo, error) {
+	if db.dialect != SQLite {
+		return nil, fmt.Errorf("online backups are only supported for SQLite; use pg_dump for PostgreSQL")
+	}
+
+	if _, err := os.Stat(dest); err == nil {
+		return nil, fmt.Errorf("backup destination %s already exists", dest)
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create backup dir: %w", err)
+	}
+
+	if _, err := db.Exec(`VACUUM INTO ?`, dest); err != nil {
+		return nil, fmt.Errorf("failed to back up database: %w", err)
+	}
+
+	if err := VerifyBackup(dest); err != nil {
+		os.Remove(dest)
+		return nil, err
+	}
+
+	fi, err := os.Stat(dest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat backup: %w", err)
+	}
+
+	return &BackupInfo{
+		Name:      filepath.Base(dest),
+		Path:      dest,
+		Size:      fi.Size(),
+		CreatedAt: fi.ModTime(),
+	}, nil
+}
+
+// VerifyBackup runs PRAGMA integrity_check against a backup file.
+func VerifyBackup(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("backup not found: %w", err)
+	}
+
+	conn, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer conn.Close()
+
+	rows, err := conn.Query(`PRAGMA integrity_check`)
+	if err != nil {
+		return fmt.Errorf("failed to check backup integrity: %w", err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return fmt.Errorf("failed to check backup integrity: %w", err)
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to check backup integrity: %w", err)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("backup %s failed integrity check: %s", path, strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// Restore replaces the contents of the live database with the backup at
+// src using SQLite's online backup API. The backup is verified first, and
+// the copy happens page by page under SQLite's own locking, so it is safe
+// while the server is running.
+func (db *DB) Restore(src string) error {
+	if db.dialect != SQLite {
+		return fmt.Errorf("online restore is only supported for SQLite; use pg_restore for PostgreSQL")
+	}
+
+	if err := VerifyBackup(src); err != nil {
+		return err
+	}
+
+	srcDB, err := sql.Open("sqlite3", "file:"+src+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer srcDB.Close()
+
+	ctx := context.Background()
+	srcConn, err := srcDB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer srcConn.Close()
+
+	destConn, err := db.DB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire database connection: %w", err)
+	}
+	defer destConn.Close()
+
+	return destConn.Raw(func(destRaw interface{}) error {
+		return srcConn.Raw(func(srcRaw interface{}) error {
+			dest, ok := destRaw.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected driver connection %T", destRaw)
+			}
+			source, ok := srcRaw.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected driver connection %T", srcRaw)
+			}
+
+			backup, err := dest.Backup("main", source, "main")
+			if err != nil {
+				return fmt.Errorf("failed to start restore: %w", err)
+			}
+
+			for {
+				done, err := backup.Step(256)
+				if err != nil {
+					backup.Close()
+					return fmt.Errorf("failed to restore database: %w", err)
+				}
+				if done {
+					break
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			if err := backup.Close(); err != nil {
+				return fmt.Errorf("failed to finish restore: %w", err)
+			}
+			return nil
+		})
+	})
+}
+
+// Snapshot writes a timestamped backup into dir and then prunes the oldest
+// snapshots so that at most retain remain. A retain of zero keeps them all.
+func (db *DB) Snapshot(dir string, retain int) (*BackupInfo, error) {
+	name := snapshotPrefix + time.Now().UTC().Format(snapshotLayout) + ".db"
+
+	info, err := db.Backup(filepath.Join(dir, name))
+	if err != nil {
+		return nil, err
+	}
+
+	if retain > 0 {
+		if err := PruneSnapshots(dir, retain); err != nil {
+			return info, err
+		}
+	}
+
+	return info, nil
+}
+
+// ListSnapshots returns the snapshots in dir, newest first.
+func ListSnapshots(dir string) ([]*BackupInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	var snapshots []*BackupInfo
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, snapshotPrefix) || !strings.HasSuffix(name, ".db") {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, snapshotPrefix), ".db")
+		createdAt, err := time.Parse(snapshotLayout, stamp)
+		if err != nil {
+			continue
+		}
+		fi, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat snapshot: %w", err)
+		}
+		snapshots = append(snapshots, &BackupInfo{
+			Name:      name,
+			Path:      filepath.Join(dir, name),
+			Size:      fi.Size(),
+			CreatedAt: createdAt,
+		})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt)
+	})
+
+	return snapshots, nil
+}
+
+// PruneSnapshots deletes all but the newest retain snapshots in dir.
+func PruneSnapshots(dir string, retain int) error {
+	snapshots, err := ListSnapshots(dir)
+	if err != nil {
+		return err
+	}
+
+	for i := retain; i < len(snapshots); i++ {
+		if err := os.Remove(snapshots[i].Path); err != nil {
+			return fmt.Errorf("failed to remove old snapshot: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package db_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	database := openSQLite(t)
+	s := newStores(database)
+	ws := createWorkspace(t, s, "")
+	kept := createIssue(t, s, &db.Issue{WorkspaceID: ws.ID, Title: "Kept", Status: "todo"})
+
+	dest := filepath.Join(t.TempDir(), "backups", "pulse.db")
+	info, err := database.Backup(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Path != dest || info.Name != "pulse.db" || info.Size == 0 {
+		t.Errorf("backup info = %+v", info)
+	}
+	if err := db.VerifyBackup(dest); err != nil {
+		t.Errorf("verify backup: %v", err)
+	}
+	if _, err := database.Backup(dest); err == nil {
+		t.Error("backup over an existing file succeeded")
+	}
+
+	// Changes after the backup are undone by restoring it.
+	lost := createIssue(t, s, &db.Issue{WorkspaceID: ws.ID, Title: "Lost", Status: "todo"})
+	if err := s.issues.Delete(kept.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.Restore(dest); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.issues.GetByID(kept.ID); err != nil || got == nil {
+		t.Errorf("issue deleted after the backup was not restored: %v", err)
+	}
+	if got, err := s.issues.GetByID(lost.ID); err != nil || got != nil {
+		t.Errorf("issue created after the backup survived the restore: %v", err)
+	}
+}
+
+func TestVerifyBackupRejectsBadFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := db.VerifyBackup(filepath.Join(dir, "missing.db")); err == nil {
+		t.Error("verified a missing backup")
+	}
+
+	garbage := filepath.Join(dir, "garbage.db")
+	if err := os.WriteFile(garbage, []byte(strings.Repeat("not a database ", 512)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.VerifyBackup(garbage); err == nil {
+		t.Error("verified a file that is not a database")
+	}
+
+	database := openSQLite(t)
+	if err := database.Restore(garbage); err == nil {
+		t.Error("restored a file that is not a database")
+	}
+	if _, err := newStores(database).workspaces.List(); err != nil {
+		t.Errorf("database unusable after a rejected restore: %v", err)
+	}
+}
+
+func TestSnapshotsArePruned(t *testing.T) {
+	database := openSQLite(t)
+	dir := t.TempDir()
+
+	var names []string
+	for range 4 {
+		info, err := database.Snapshot(dir, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, info.Name)
+		// Snapshot names have millisecond resolution.
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	// Files that are not snapshots are neither listed nor pruned.
+	other := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(other, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshots, err := db.ListSnapshots(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 2 || snapshots[0].Name != names[3] || snapshots[1].Name != names[2] {
+		t.Fatalf("snapshots after pruning = %v, want the newest two of %v", snapshotNames(snapshots), names)
+	}
+
+	if err := db.PruneSnapshots(dir, 1); err != nil {
+		t.Fatal(err)
+	}
+	snapshots, err = db.ListSnapshots(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 1 || snapshots[0].Name != names[3] {
+		t.Errorf("snapshots = %v, want only %s", snapshotNames(snapshots), names[3])
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("pruning removed a file that is not a snapshot: %v", err)
+	}
+}
+
+func TestListSnapshotsMissingDir(t *testing.T) {
+	snapshots, err := db.ListSnapshots(filepath.Join(t.TempDir(), "none"))
+	if err != nil || snapshots != nil {
+		t.Errorf("ListSnapshots = %v, %v; want nil, nil", snapshots, err)
+	}
+}
+
+func snapshotNames(snapshots []*db.BackupInfo) []string {
+	var names []string
+	for _, s := range snapshots {
+		names = append(names, s.Name)
+	}
+	return names
+}
@@ -0,0 +1,126 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+// Capacity is how much work one member can take on in a cycle: a fixed
+// number of points, or when Points is zero, the cycle's working days less
+// DaysOff at the workspace's points per day.
+type Capacity struct {
+	CycleID   string    `json:"cycle_id"`
+	MemberID  string    `json:"member_id"`
+	Points    int       `json:"points"`
+	DaysOff   int       `json:"days_off"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// capacityColumns lists the capacity columns in the order scanCapacity
+// reads them.
+const capacityColumns = `cycle_id, member_id, points, days_off, updated_at`
+
+// scanCapacity reads a capacity selected with capacityColumns.
+func scanCapacity(row rowScanner) (*Capacity, error) {
+	var c Capacity
+	err := row.Scan(
+		&c.CycleID,
+		&c.MemberID,
+		&c.Points,
+		&c.DaysOff,
+		&c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// CapacityRepository handles cycle capacity database operations.
+type CapacityRepository struct {
+	db Querier
+}
+
+// NewCapacityRepository creates a new capacity repository. Pass a *Tx to
+// run its operations inside a transaction.
+func NewCapacityRepository(db Querier) *CapacityRepository {
+	return &CapacityRepository{db: db}
+}
+
+// Set stores a member's capacity for a cycle, replacing any previous one.
+func (r *CapacityRepository) Set(c *Capacity) error {
+	c.UpdatedAt = time.Now()
+	return inTx(r.db, func(tx *Tx) error {
+		if err := NewCapacityRepository(tx).Delete(c.CycleID, c.MemberID); err != nil {
+			return err
+		}
+		return NewCapacityRepository(tx).Insert(c)
+	})
+}
+
+// Insert stores a capacity exactly as given, preserving its timestamp.
+func (r *CapacityRepository) Insert(c *Capacity) error {
+	query := `
+		INSERT INTO cycle_capacities (cycle_id, member_id, points, days_off, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.Exec(query,
+		c.CycleID,
+		c.MemberID,
+		c.Points,
+		c.DaysOff,
+		c.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set capacity: %w", err)
+	}
+
+	return nil
+}
+
+// ListByCycle retrieves the capacities set for a cycle.
+func (r *CapacityRepository) ListByCycle(cycleID string) ([]*Capacity, error) {
+	return r.list(`SELECT `+capacityColumns+` FROM cycle_capacities WHERE cycle_id = ? ORDER BY member_id`, cycleID)
+}
+
+// ListByWorkspace retrieves the capacities of every cycle in a workspace.
+func (r *CapacityRepository) ListByWorkspace(workspaceID string) ([]*Capacity, error) {
+	query := `
+		SELECT ` + capacityColumns + ` FROM cycle_capacities
+		WHERE cycle_id IN (SELECT id FROM cycles WHERE workspace_id = ?)
+		ORDER BY cycle_id, member_id
+	`
+	return r.list(query, workspaceID)
+}
+
+func (r *CapacityRepository) list(query string, args ...interface{}) ([]*Capacity, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list capacities: %w", err)
+	}
+	defer rows.Close()
+
+	var capacities []*Capacity
+	for rows.Next() {
+		c, err := scanCapacity(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan capacity: %w", err)
+		}
+		capacities = append(capacities, c)
+	}
+
+	return capacities, rows.Err()
+}
+
+// Delete removes a member's capacity for a cycle.
+func (r *CapacityRepository) Delete(cycleID, memberID string) error {
+	query := `DELETE FROM cycle_capacities WHERE cycle_id = ? AND member_id = ?`
+
+	_, err := r.db.Exec(query, cycleID, memberID)
+	if err != nil {
+		return fmt.Errorf("failed to delete capacity: %w", err)
+	}
+
+	return nil
+}
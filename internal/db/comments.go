@@ -0,0 +1,142 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Comment is a discussion entry on an issue.
+type Comment struct {
+	ID        string    `json:"id"`
+	IssueID   string    `json:"issue_id"`
+	AuthorID  string    `json:"author_id"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// commentColumns lists the comment columns in the order scanComment reads
+// them.
+const commentColumns = `id, issue_id, author_id, body, created_at, updated_at`
+
+// scanComment reads a comment selected with commentColumns.
+func scanComment(row rowScanner) (*Comment, error) {
+	var c Comment
+	err := row.Scan(
+		&c.ID,
+		&c.IssueID,
+		&c.AuthorID,
+		&c.Body,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// CommentRepository handles comment database operations.
+type CommentRepository struct {
+	db Querier
+}
+
+// NewCommentRepository creates a new comment repository. Pass a *Tx to run
+// its operations inside a transaction.
+func NewCommentRepository(db Querier) *CommentRepository {
+	return &CommentRepository{db: db}
+}
+
+// Create inserts a new comment.
+func (r *CommentRepository) Create(c *Comment) error {
+	now := time.Now()
+	c.CreatedAt = now
+	c.UpdatedAt = now
+
+	return r.Insert(c)
+}
+
+// Insert stores a comment exactly as given, preserving its timestamps.
+func (r *CommentRepository) Insert(c *Comment) error {
+	query := `
+		INSERT INTO comments (id, issue_id, author_id, body, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.Exec(query,
+		c.ID,
+		c.IssueID,
+		c.AuthorID,
+		c.Body,
+		c.CreatedAt,
+		c.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create comment: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a comment by ID.
+func (r *CommentRepository) GetByID(id string) (*Comment, error) {
+	query := `SELECT ` + commentColumns + ` FROM comments WHERE id = ?`
+
+	c, err := scanComment(r.db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comment: %w", err)
+	}
+
+	return c, nil
+}
+
+// ListByIssue retrieves the comments on an issue, oldest first.
+func (r *CommentRepository) ListByIssue(issueID string) ([]*Comment, error) {
+	query := `SELECT ` + commentColumns + ` FROM comments WHERE issue_id = ? ORDER BY created_at ASC`
+	return r.list(query, issueID)
+}
+
+// ListByWorkspace retrieves every comment on issues in a workspace.
+func (r *CommentRepository) ListByWorkspace(workspaceID string) ([]*Comment, error) {
+	query := `
+		SELECT ` + commentColumns + ` FROM comments
+		WHERE issue_id IN (SELECT id FROM issues WHERE workspace_id = ?)
+		ORDER BY created_at ASC
+	`
+	return r.list(query, workspaceID)
+}
+
+func (r *CommentRepository) list(query string, args ...interface{}) ([]*Comment, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list comments: %w", err)
+	}
+	defer rows.Close()
+
+	var comments []*Comment
+	for rows.Next() {
+		c, err := scanComment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan comment: %w", err)
+		}
+		comments = append(comments, c)
+	}
+
+	return comments, rows.Err()
+}
+
+// Delete removes a comment by ID.
+func (r *CommentRepository) Delete(id string) error {
+	query := `DELETE FROM comments WHERE id = ?`
+
+	_, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+
+	return nil
+}
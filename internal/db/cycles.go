@@ -17,21 +17,56 @@ type Cycle struct {
 	CreatedAt   time.Time  `json:"created_at"`
 }
 
+// cycleColumns lists the cycle columns in the order scanCycle reads them.
+const cycleColumns = `id, workspace_id, name, start_date, end_date, status, created_at`
+
+// scanCycle reads a cycle selected with cycleColumns.
+func scanCycle(row rowScanner) (*Cycle, error) {
+	var cycle Cycle
+	var startDate, endDate sql.NullTime
+
+	err := row.Scan(
+		&cycle.ID,
+		&cycle.WorkspaceID,
+		&cycle.Name,
+		&startDate,
+		&endDate,
+		&cycle.Status,
+		&cycle.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if startDate.Valid {
+		cycle.StartDate = &startDate.Time
+	}
+	if endDate.Valid {
+		cycle.EndDate = &endDate.Time
+	}
+
+	return &cycle, nil
+}
+
 // CycleRepository handles cycle database operations.
 type CycleRepository struct {
-	db *DB
+	db Querier
 }
 
-// NewCycleRepository creates a new cycle repository.
-func NewCycleRepository(db *DB) *CycleRepository {
+// NewCycleRepository creates a new cycle repository. Pass a *Tx to run its
+// operations inside a transaction.
+func NewCycleRepository(db Querier) *CycleRepository {
 	return &CycleRepository{db: db}
 }
 
 // Create inserts a new cycle.
 func (r *CycleRepository) Create(cycle *Cycle) error {
-	now := time.Now()
-	cycle.CreatedAt = now
+	cycle.CreatedAt = time.Now()
+	return r.Insert(cycle)
+}
 
+// Insert stores a cycle exactly as given, preserving its timestamp.
+func (r *CycleRepository) Insert(cycle *Cycle) error {
 	query := `
 		INSERT INTO cycles (id, workspace_id, name, start_date, end_date, status, created_at)
 		VALUES (?, ?, ?, ?, ?, ?, ?)
@@ -55,20 +90,9 @@ func (r *CycleRepository) Create(cycle *Cycle) error {
 
 // GetByID retrieves a cycle by ID.
 func (r *CycleRepository) GetByID(id string) (*Cycle, error) {
-	query := `SELECT * FROM cycles WHERE id = ?`
+	query := `SELECT ` + cycleColumns + ` FROM cycles WHERE id = ?`
 
-	var cycle Cycle
-	var startDate, endDate sql.NullTime
-
-	err := r.db.QueryRow(query, id).Scan(
-		&cycle.ID,
-		&cycle.WorkspaceID,
-		&cycle.Name,
-		&startDate,
-		&endDate,
-		&cycle.Status,
-		&cycle.CreatedAt,
-	)
+	cycle, err := scanCycle(r.db.QueryRow(query, id))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -76,19 +100,12 @@ func (r *CycleRepository) GetByID(id string) (*Cycle, error) {
 		return nil, fmt.Errorf("failed to get cycle: %w", err)
 	}
 
-	if startDate.Valid {
-		cycle.StartDate = &startDate.Time
-	}
-	if endDate.Valid {
-		cycle.EndDate = &endDate.Time
-	}
-
-	return &cycle, nil
+	return cycle, nil
 }
 
 // List retrieves all cycles for a workspace.
 func (r *CycleRepository) List(workspaceID string) ([]*Cycle, error) {
-	query := `SELECT * FROM cycles WHERE workspace_id = ? ORDER BY created_at DESC`
+	query := `SELECT ` + cycleColumns + ` FROM cycles WHERE workspace_id = ? ORDER BY created_at DESC`
 
 	rows, err := r.db.Query(query, workspaceID)
 	if err != nil {
@@ -98,33 +115,14 @@ func (r *CycleRepository) List(workspaceID string) ([]*Cycle, error) {
 
 	var cycles []*Cycle
 	for rows.Next() {
-		var cycle Cycle
-		var startDate, endDate sql.NullTime
-
-		err := rows.Scan(
-			&cycle.ID,
-			&cycle.WorkspaceID,
-			&cycle.Name,
-			&startDate,
-			&endDate,
-			&cycle.Status,
-			&cycle.CreatedAt,
-		)
+		cycle, err := scanCycle(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan cycle: %w", err)
 		}
-
-		if startDate.Valid {
-			cycle.StartDate = &startDate.Time
-		}
-		if endDate.Valid {
-			cycle.EndDate = &endDate.Time
-		}
-
-		cycles = append(cycles, &cycle)
+		cycles = append(cycles, cycle)
 	}
 
-	return cycles, nil
+	return cycles, rows.Err()
 }
 
 // Update updates an existing cycle.
@@ -152,34 +150,24 @@ func (r *CycleRepository) Update(cycle *Cycle) error {
 	return nil
 }
 
-// Delete removes a cycle by ID.
+// Delete removes a cycle by ID along with its member capacities.
 func (r *CycleRepository) Delete(id string) error {
-	query := `DELETE FROM cycles WHERE id = ?`
-
-	_, err := r.db.Exec(query, id)
-	if err != nil {
-		return fmt.Errorf("failed to delete cycle: %w", err)
-	}
-
-	return nil
+	return inTx(r.db, func(tx *Tx) error {
+		if _, err := tx.Exec(`DELETE FROM cycle_capacities WHERE cycle_id = ?`, id); err != nil {
+			return fmt.Errorf("failed to delete cycle capacity: %w", err)
+		}
+		if _, err := tx.Exec(`DELETE FROM cycles WHERE id = ?`, id); err != nil {
+			return fmt.Errorf("failed to delete cycle: %w", err)
+		}
+		return nil
+	})
 }
 
 // GetActive retrieves the active cycle for a workspace.
 func (r *CycleRepository) GetActive(workspaceID string) (*Cycle, error) {
-	query := `SELECT * FROM cycles WHERE workspace_id = ? AND status = 'active' LIMIT 1`
+	query := `SELECT ` + cycleColumns + ` FROM cycles WHERE workspace_id = ? AND status = 'active' LIMIT 1`
 
-	var cycle Cycle
-	var startDate, endDate sql.NullTime
-
-	err := r.db.QueryRow(query, workspaceID).Scan(
-		&cycle.ID,
-		&cycle.WorkspaceID,
-		&cycle.Name,
-		&startDate,
-		&endDate,
-		&cycle.Status,
-		&cycle.CreatedAt,
-	)
+	cycle, err := scanCycle(r.db.QueryRow(query, workspaceID))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -187,19 +175,12 @@ func (r *CycleRepository) GetActive(workspaceID string) (*Cycle, error) {
 		return nil, fmt.Errorf("failed to get active cycle: %w", err)
 	}
 
-	if startDate.Valid {
-		cycle.StartDate = &startDate.Time
-	}
-	if endDate.Valid {
-		cycle.EndDate = &endDate.Time
-	}
-
-	return &cycle, nil
+	return cycle, nil
 }
 
 // GetUpcoming retrieves upcoming cycles for a workspace.
 func (r *CycleRepository) GetUpcoming(workspaceID string) ([]*Cycle, error) {
-	query := `SELECT * FROM cycles WHERE workspace_id = ? AND status = 'upcoming' ORDER BY created_at ASC`
+	query := `SELECT ` + cycleColumns + ` FROM cycles WHERE workspace_id = ? AND status = 'upcoming' ORDER BY created_at ASC`
 
 	rows, err := r.db.Query(query, workspaceID)
 	if err != nil {
@@ -209,31 +190,12 @@ func (r *CycleRepository) GetUpcoming(workspaceID string) ([]*Cycle, error) {
 
 	var cycles []*Cycle
 	for rows.Next() {
-		var cycle Cycle
-		var startDate, endDate sql.NullTime
-
-		err := rows.Scan(
-			&cycle.ID,
-			&cycle.WorkspaceID,
-			&cycle.Name,
-			&startDate,
-			&endDate,
-			&cycle.Status,
-			&cycle.CreatedAt,
-		)
+		cycle, err := scanCycle(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan cycle: %w", err)
 		}
-
-		if startDate.Valid {
-			cycle.StartDate = &startDate.Time
-		}
-		if endDate.Valid {
-			cycle.EndDate = &endDate.Time
-		}
-
-		cycles = append(cycles, &cycle)
+		cycles = append(cycles, cycle)
 	}
 
-	return cycles, nil
+	return cycles, rows.Err()
 }
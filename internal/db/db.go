@@ -1,20 +1,56 @@
-// Package db provides SQLite database operations for Pulse.
+// Package db provides database operations for Pulse. SQLite is used for
+// local installs; PostgreSQL can be selected for shared server deployments.
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
+	_ "github.com/lib/pq"
 	_ "github.com/mattn/go-sqlite3"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/pulse/pm/internal/telemetry"
+)
+
+// Dialect identifies the SQL flavour spoken by the underlying database.
+type Dialect string
+
+const (
+	// SQLite is the embedded default backend.
+	SQLite Dialect = "sqlite3"
+	// Postgres is the server backend selected by a postgres:// URL.
+	Postgres Dialect = "postgres"
 )
 
-// DB represents the SQLite database connection.
+// DB represents the database connection.
 type DB struct {
 	*sql.DB
-	path string
+	path    string
+	dialect Dialect
+	observe QueryObserver
+	ctx     context.Context
+}
+
+// Open connects to databaseURL when it is set, and otherwise to the SQLite
+// database in dataDir.
+func Open(dataDir, databaseURL string) (*DB, error) {
+	if databaseURL == "" {
+		return New(dataDir)
+	}
+	if !strings.HasPrefix(databaseURL, "postgres://") && !strings.HasPrefix(databaseURL, "postgresql://") {
+		return nil, fmt.Errorf("unsupported database url %q: expected postgres://", redactURL(databaseURL))
+	}
+	return NewPostgres(databaseURL)
 }
 
 // New creates a new database connection.
@@ -31,112 +67,34 @@ func New(dataDir string) (*DB, error) {
 	}
 
 	// Set pragmas for performance
-	db.Exec("PRAGMA journal_mode=WAL")
-	db.Exec("PRAGMA synchronous=NORMAL")
-	db.Exec("PRAGMA busy_timeout=30000")
-
-	return &DB{DB: db, path: dbPath}, nil
-}
-
-// Migrate runs database migrations.
-func (db *DB) Migrate() error {
-	// Create workspaces table
-	if _, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS workspaces (
-			id TEXT PRIMARY KEY,
-			name TEXT NOT NULL,
-			description TEXT,
-			settings TEXT,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		)
-	`); err != nil {
-		return fmt.Errorf("failed to create workspaces table: %w", err)
-	}
-
-	// Create issues table
-	if _, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS issues (
-			id TEXT PRIMARY KEY,
-			workspace_id TEXT NOT NULL,
-			title TEXT NOT NULL,
-			description TEXT,
-			status TEXT DEFAULT 'backlog',
-			priority INTEGER DEFAULT 0,
-			assignee_id TEXT,
-			estimate INTEGER,
-			cycle_id TEXT,
-			labels TEXT,
-			parent_id TEXT,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			completed_at DATETIME,
-			FOREIGN KEY (workspace_id) REFERENCES workspaces(id)
-		)
-	`); err != nil {
-		return fmt.Errorf("failed to create issues table: %w", err)
-	}
-
-	// Create cycles table
-	if _, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS cycles (
-			id TEXT PRIMARY KEY,
-			workspace_id TEXT NOT NULL,
-			name TEXT NOT NULL,
-			start_date DATETIME,
-			end_date DATETIME,
-			status TEXT DEFAULT 'upcoming',
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (workspace_id) REFERENCES workspaces(id)
-		)
-	`); err != nil {
-		return fmt.Errorf("failed to create cycles table: %w", err)
-	}
-
-	// Create users table (for future auth)
-	if _, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS users (
-			id TEXT PRIMARY KEY,
-			email TEXT UNIQUE NOT NULL,
-			name TEXT,
-			avatar_url TEXT,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
-		)
-	`); err != nil {
-		return fmt.Errorf("failed to create users table: %w", err)
-	}
-
-	// Create indexes
-	indexes := []string{
-		`CREATE INDEX IF NOT EXISTS idx_issues_workspace ON issues(workspace_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_issues_status ON issues(status)`,
-		`CREATE INDEX IF NOT EXISTS idx_issues_assignee ON issues(assignee_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_issues_cycle ON issues(cycle_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_cycles_workspace ON cycles(workspace_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_cycles_status ON cycles(status)`,
-	}
-
-	for _, idx := range indexes {
-		if _, err := db.Exec(idx); err != nil {
-			return fmt.Errorf("failed to create index: %w", err)
+	pragmas := []string{
+		"PRAGMA journal_mode=WAL",
+		"PRAGMA synchronous=NORMAL",
+		"PRAGMA busy_timeout=30000",
+	}
+	for _, pragma := range pragmas {
+		if _, err := db.Exec(pragma); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to set %q: %w", pragma, err)
 		}
 	}
 
-	// Create default workspace if none exists
-	count := 0
-	db.QueryRow("SELECT COUNT(*) FROM workspaces").Scan(&count)
-	if count == 0 {
-		now := time.Now().Format(time.RFC3339)
-		_, err := db.Exec(`
-			INSERT INTO workspaces (id, name, description, settings, created_at, updated_at)
-			VALUES (?, ?, ?, ?, ?, ?)
-		`, "default", "Main Workspace", "Default workspace for tracking", "{}", now, now)
-		if err != nil {
-			return fmt.Errorf("failed to create default workspace: %w", err)
-		}
+	return &DB{DB: db, path: dbPath, dialect: SQLite}, nil
+}
+
+// NewPostgres creates a new PostgreSQL database connection.
+func NewPostgres(databaseURL string) (*DB, error) {
+	db, err := sql.Open("postgres", databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	return nil
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	return &DB{DB: db, path: redactURL(databaseURL), dialect: Postgres}, nil
 }
 
 // Close closes the database connection.
@@ -144,7 +102,245 @@ func (db *DB) Close() error {
 	return db.DB.Close()
 }
 
-// Path returns the database file path.
+// Path returns the database file path, or the redacted URL for server
+// databases.
 func (db *DB) Path() string {
 	return db.path
 }
+
+// Dialect returns the SQL dialect of the connection.
+func (db *DB) Dialect() Dialect {
+	return db.dialect
+}
+
+// QueryObserver is told how long each statement took to run. op is the
+// statement's leading keyword in lower case, such as select or insert.
+// Query durations end when the first rows are ready, not when they have
+// all been read.
+type QueryObserver func(op string, d time.Duration)
+
+// ObserveQueries reports the duration of every statement run on the
+// connection and its transactions to fn. It must be called before the
+// connection is shared.
+func (db *DB) ObserveQueries(fn QueryObserver) {
+	db.observe = fn
+}
+
+// WithContext returns a view of the connection whose statements run under
+// ctx: they are canceled with it, traced as children of its span, and
+// their errors name its request ID.
+func (db *DB) WithContext(ctx context.Context) *DB {
+	c := *db
+	c.ctx = ctx
+	return &c
+}
+
+func (db *DB) context() context.Context {
+	if db.ctx == nil {
+		return context.Background()
+	}
+	return db.ctx
+}
+
+// statementKind returns the leading keyword of a statement.
+func statementKind(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToLower(strings.TrimLeft(fields[0], "("))
+}
+
+// statement runs one statement rebound for the dialect under ctx, timing
+// and tracing it.
+func statement[T any](ctx context.Context, d Dialect, observe QueryObserver, query string, run func(ctx context.Context, query string) (T, error)) (T, error) {
+	op := statementKind(query)
+	ctx, span := telemetry.StartSpan(ctx, "db."+op, trace.SpanKindClient)
+	span.SetAttributes(
+		attribute.String("db.system", d.system()),
+		attribute.String("db.operation", op),
+		attribute.String("db.statement", strings.Join(strings.Fields(query), " ")),
+	)
+
+	start := time.Now()
+	v, err := run(ctx, d.rebind(query))
+	if observe != nil {
+		observe(op, time.Since(start))
+	}
+	telemetry.EndSpan(span, err)
+
+	if err != nil {
+		if id := telemetry.RequestID(ctx); id != "" {
+			err = fmt.Errorf("%w (request %s)", err, id)
+		}
+	}
+	return v, err
+}
+
+// system names the dialect as OpenTelemetry does.
+func (d Dialect) system() string {
+	if d == Postgres {
+		return "postgresql"
+	}
+	return "sqlite"
+}
+
+// Exec executes a query written with ? placeholders.
+func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return statement(db.context(), db.dialect, db.observe, query, func(ctx context.Context, q string) (sql.Result, error) {
+		return db.DB.ExecContext(ctx, q, args...)
+	})
+}
+
+// Query runs a query written with ? placeholders.
+func (db *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return statement(db.context(), db.dialect, db.observe, query, func(ctx context.Context, q string) (*sql.Rows, error) {
+		return db.DB.QueryContext(ctx, q, args...)
+	})
+}
+
+// QueryRow runs a single-row query written with ? placeholders.
+func (db *DB) QueryRow(query string, args ...interface{}) *sql.Row {
+	row, _ := statement(db.context(), db.dialect, db.observe, query, func(ctx context.Context, q string) (*sql.Row, error) {
+		row := db.DB.QueryRowContext(ctx, q, args...)
+		return row, row.Err()
+	})
+	return row
+}
+
+// Begin starts a transaction under the connection's context.
+func (db *DB) Begin() (*Tx, error) {
+	ctx := db.context()
+	tx, err := db.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx, dialect: db.dialect, observe: db.observe, ctx: ctx}, nil
+}
+
+// Querier is implemented by DB and Tx. Repositories accept either so the
+// same code can run standalone or as part of a larger transaction.
+type Querier interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// InTx runs fn inside a transaction, committing if it returns nil.
+func (db *DB) InTx(fn func(tx *Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// inTx runs fn in a new transaction, or in q itself when q is already a
+// transaction.
+func inTx(q Querier, fn func(tx *Tx) error) error {
+	switch q := q.(type) {
+	case *Tx:
+		return fn(q)
+	case *DB:
+		return q.InTx(fn)
+	default:
+		return fmt.Errorf("unsupported querier %T", q)
+	}
+}
+
+// dialectOf returns the SQL dialect q speaks, for the few queries that
+// cannot be written portably.
+func dialectOf(q Querier) Dialect {
+	switch q := q.(type) {
+	case *Tx:
+		return q.dialect
+	case *DB:
+		return q.dialect
+	default:
+		return SQLite
+	}
+}
+
+// Tx is a transaction that accepts ? placeholders like DB.
+type Tx struct {
+	*sql.Tx
+	dialect Dialect
+	observe QueryObserver
+	ctx     context.Context
+}
+
+// Exec executes a query written with ? placeholders.
+func (tx *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return statement(tx.ctx, tx.dialect, tx.observe, query, func(ctx context.Context, q string) (sql.Result, error) {
+		return tx.Tx.ExecContext(ctx, q, args...)
+	})
+}
+
+// Query runs a query written with ? placeholders.
+func (tx *Tx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return statement(tx.ctx, tx.dialect, tx.observe, query, func(ctx context.Context, q string) (*sql.Rows, error) {
+		return tx.Tx.QueryContext(ctx, q, args...)
+	})
+}
+
+// QueryRow runs a single-row query written with ? placeholders.
+func (tx *Tx) QueryRow(query string, args ...interface{}) *sql.Row {
+	row, _ := statement(tx.ctx, tx.dialect, tx.observe, query, func(ctx context.Context, q string) (*sql.Row, error) {
+		row := tx.Tx.QueryRowContext(ctx, q, args...)
+		return row, row.Err()
+	})
+	return row
+}
+
+// rebind rewrites ? placeholders into the dialect's native form.
+func (d Dialect) rebind(query string) string {
+	if d != Postgres || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+	n := 0
+	inString := false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'':
+			inString = !inString
+			b.WriteByte(c)
+		case c == '?' && !inString:
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// ddl adapts schema statements written for SQLite to the dialect.
+func (d Dialect) ddl(stmt string) string {
+	if d != Postgres {
+		return stmt
+	}
+	return strings.ReplaceAll(stmt, "DATETIME", "TIMESTAMPTZ")
+}
+
+// redactURL strips the password from a database URL for display.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "postgres"
+	}
+	return u.Redacted()
+}
@@ -0,0 +1,175 @@
+package db
+
+import (
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Fields tracked in issue history.
+const (
+	FieldStatus    = "status"
+	FieldPriority  = "priority"
+	FieldAssignee  = "assignee_id"
+	FieldEstimate  = "estimate"
+	FieldCycle     = "cycle_id"
+	FieldParent    = "parent_id"
+	FieldProject   = "project_id"
+	FieldMilestone = "milestone_id"
+	// FieldBreach records a missed deadline; the new value is the
+	// deadline's source (sla or due_date) and time, as source@RFC 3339.
+	FieldBreach = "breach"
+)
+
+// IssueEvent records one field of an issue changing value. Numeric fields
+// are stored in decimal.
+type IssueEvent struct {
+	ID          string    `json:"id"`
+	IssueID     string    `json:"issue_id"`
+	WorkspaceID string    `json:"workspace_id"`
+	Field       string    `json:"field"`
+	OldValue    string    `json:"old_value"`
+	NewValue    string    `json:"new_value"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// eventColumns lists the event columns in the order scanEvent reads them.
+const eventColumns = `id, issue_id, workspace_id, field, old_value, new_value, created_at`
+
+func scanEvent(row rowScanner) (*IssueEvent, error) {
+	var e IssueEvent
+	err := row.Scan(
+		&e.ID,
+		&e.IssueID,
+		&e.WorkspaceID,
+		&e.Field,
+		&e.OldValue,
+		&e.NewValue,
+		&e.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// EventRepository reads and writes issue history.
+type EventRepository struct {
+	db Querier
+}
+
+// NewEventRepository creates a new event repository. Pass a *Tx to run its
+// operations inside a transaction.
+func NewEventRepository(db Querier) *EventRepository {
+	return &EventRepository{db: db}
+}
+
+// Record stores an event, assigning its ID if unset.
+func (r *EventRepository) Record(e *IssueEvent) error {
+	if e.ID == "" {
+		e.ID = nextEventID()
+	}
+
+	_, err := r.db.Exec(`
+		INSERT INTO issue_events (`+eventColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`,
+		e.ID,
+		e.IssueID,
+		e.WorkspaceID,
+		e.Field,
+		e.OldValue,
+		e.NewValue,
+		e.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record issue event: %w", err)
+	}
+	return nil
+}
+
+// ListByIssue returns an issue's history, oldest first.
+func (r *EventRepository) ListByIssue(issueID string) ([]*IssueEvent, error) {
+	return r.list(`SELECT `+eventColumns+` FROM issue_events WHERE issue_id = ? ORDER BY created_at ASC, id ASC`, issueID)
+}
+
+// ListByWorkspace returns the history of every issue in a workspace since
+// the given time, oldest first. A zero since returns all history.
+func (r *EventRepository) ListByWorkspace(workspaceID string, since time.Time) ([]*IssueEvent, error) {
+	return r.list(`SELECT `+eventColumns+` FROM issue_events WHERE workspace_id = ? AND created_at >= ? ORDER BY created_at ASC, id ASC`, workspaceID, since)
+}
+
+// ListByField returns every event recorded for one field in a workspace,
+// oldest first.
+func (r *EventRepository) ListByField(workspaceID, field string) ([]*IssueEvent, error) {
+	return r.list(`SELECT `+eventColumns+` FROM issue_events WHERE workspace_id = ? AND field = ? ORDER BY created_at ASC, id ASC`, workspaceID, field)
+}
+
+func (r *EventRepository) list(query string, args ...interface{}) ([]*IssueEvent, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issue events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*IssueEvent
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan issue event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// recordChanges stores an event for every tracked field that differs
+// between before and after.
+func recordChanges(q Querier, before, after *Issue, at time.Time) error {
+	events := NewEventRepository(q)
+
+	for _, c := range [][3]string{
+		{FieldStatus, before.Status, after.Status},
+		{FieldPriority, strconv.Itoa(before.Priority), strconv.Itoa(after.Priority)},
+		{FieldAssignee, before.AssigneeID, after.AssigneeID},
+		{FieldEstimate, strconv.Itoa(before.Estimate), strconv.Itoa(after.Estimate)},
+		{FieldCycle, before.CycleID, after.CycleID},
+		{FieldParent, before.ParentID, after.ParentID},
+		{FieldProject, before.ProjectID, after.ProjectID},
+		{FieldMilestone, before.MilestoneID, after.MilestoneID},
+	} {
+		if c[1] == c[2] {
+			continue
+		}
+		err := events.Record(&IssueEvent{
+			IssueID:     after.ID,
+			WorkspaceID: after.WorkspaceID,
+			Field:       c[0],
+			OldValue:    c[1],
+			NewValue:    c[2],
+			CreatedAt:   at,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eventSeq keeps event IDs unique when several are recorded within the
+// same clock tick.
+var eventSeq atomic.Int64
+
+func nextEventID() string {
+	for {
+		last := eventSeq.Load()
+		next := time.Now().UnixNano()
+		if next <= last {
+			next = last + 1
+		}
+		if eventSeq.CompareAndSwap(last, next) {
+			return fmt.Sprintf("event_%d", next)
+		}
+	}
+}
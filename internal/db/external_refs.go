@@ -0,0 +1,55 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ExternalRefRepository maps IDs from external trackers (Jira, Linear,
+// GitHub) to Pulse entities so that repeated imports update rather than
+// duplicate. Mappings are kept per workspace, so the same export can be
+// imported into several workspaces.
+type ExternalRefRepository struct {
+	db Querier
+}
+
+// NewExternalRefRepository creates a new external reference repository.
+// Pass a *Tx to run its operations inside a transaction.
+func NewExternalRefRepository(db Querier) *ExternalRefRepository {
+	return &ExternalRefRepository{db: db}
+}
+
+// Lookup returns the Pulse ID recorded for an external ID in a workspace,
+// or "" if none.
+func (r *ExternalRefRepository) Lookup(workspaceID, source, entity, externalID string) (string, error) {
+	query := `SELECT entity_id FROM external_refs WHERE workspace_id = ? AND source = ? AND entity = ? AND external_id = ?`
+
+	var id string
+	err := r.db.QueryRow(query, workspaceID, source, entity, externalID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to look up external ref: %w", err)
+	}
+
+	return id, nil
+}
+
+// Record stores the mapping from an external ID to a Pulse ID in a
+// workspace, replacing any previous mapping.
+func (r *ExternalRefRepository) Record(workspaceID, source, entity, externalID, entityID string) error {
+	query := `
+		INSERT INTO external_refs (workspace_id, source, entity, external_id, entity_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (workspace_id, source, entity, external_id) DO UPDATE SET entity_id = excluded.entity_id
+	`
+
+	_, err := r.db.Exec(query, workspaceID, source, entity, externalID, entityID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to record external ref: %w", err)
+	}
+
+	return nil
+}
@@ -4,7 +4,10 @@ package db
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -12,6 +15,9 @@ import (
 type Issue struct {
 	ID          string     `json:"id"`
 	WorkspaceID string     `json:"workspace_id"`
+	Number      int        `json:"number"`
+	Key         string     `json:"key"` // e.g. PUL-42; derived from the workspace prefix and Number
+	Branch      string     `json:"branch"`
 	Title       string     `json:"title"`
 	Description string     `json:"description"`
 	Status      string     `json:"status"`
@@ -21,37 +27,249 @@ type Issue struct {
 	CycleID     string     `json:"cycle_id"`
 	Labels      []string   `json:"labels"`
 	ParentID    string     `json:"parent_id"`
+	ProjectID   string     `json:"project_id"`
+	MilestoneID string     `json:"milestone_id"`
+	StartDate   *time.Time `json:"start_date"`
+	DueDate     *time.Time `json:"due_date"`
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 	CompletedAt *time.Time `json:"completed_at"`
 }
 
+// Statuses are the issue workflow states in board order.
+var Statuses = []string{"backlog", "todo", "in_progress", "done", "canceled"}
+
+// ValidStatus reports whether s is an issue workflow state.
+func ValidStatus(s string) bool {
+	for _, status := range Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// priorityNames maps issue priorities to their display names.
+var priorityNames = map[int]string{
+	0: "none",
+	1: "urgent",
+	2: "high",
+	3: "medium",
+	4: "low",
+}
+
+// PriorityName returns the display name of an issue priority.
+func PriorityName(p int) string {
+	if name, ok := priorityNames[p]; ok {
+		return name
+	}
+	return strconv.Itoa(p)
+}
+
+// ParsePriority accepts a priority name such as "urgent" or its number.
+func ParsePriority(s string) (int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for p, name := range priorityNames {
+		if s == name || s == strconv.Itoa(p) {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown priority %q (use urgent, high, medium, low or none)", s)
+}
+
+// IssueChange describes a partial update applied to one or more issues.
+// Nil fields are left untouched.
+type IssueChange struct {
+	Status       *string  `json:"status"`
+	AssigneeID   *string  `json:"assignee_id"`
+	CycleID      *string  `json:"cycle_id"`
+	Priority     *int     `json:"priority"`
+	Estimate     *int     `json:"estimate"`
+	AddLabels    []string `json:"add_labels"`
+	RemoveLabels []string `json:"remove_labels"`
+}
+
+// Validate checks the values of the change that do not depend on the
+// issues it is applied to.
+func (c *IssueChange) Validate() error {
+	if c.Status != nil && !ValidStatus(*c.Status) {
+		return fmt.Errorf("invalid status %q", *c.Status)
+	}
+	return nil
+}
+
+// Apply mutates issue according to the change.
+func (c *IssueChange) Apply(issue *Issue, now time.Time) {
+	if c.Status != nil && *c.Status != issue.Status {
+		issue.Status = *c.Status
+		if issue.Status == "done" {
+			issue.CompletedAt = &now
+		} else {
+			issue.CompletedAt = nil
+		}
+	}
+	if c.AssigneeID != nil {
+		issue.AssigneeID = *c.AssigneeID
+	}
+	if c.CycleID != nil {
+		issue.CycleID = *c.CycleID
+	}
+	if c.Priority != nil {
+		issue.Priority = *c.Priority
+	}
+	if c.Estimate != nil {
+		issue.Estimate = *c.Estimate
+	}
+
+	if len(c.RemoveLabels) > 0 {
+		remove := make(map[string]bool, len(c.RemoveLabels))
+		for _, l := range c.RemoveLabels {
+			remove[l] = true
+		}
+		kept := make([]string, 0, len(issue.Labels))
+		for _, l := range issue.Labels {
+			if !remove[l] {
+				kept = append(kept, l)
+			}
+		}
+		issue.Labels = kept
+	}
+	for _, l := range c.AddLabels {
+		found := false
+		for _, existing := range issue.Labels {
+			if existing == l {
+				found = true
+				break
+			}
+		}
+		if !found {
+			issue.Labels = append(issue.Labels, l)
+		}
+	}
+}
+
+// BulkResult is the outcome of a bulk change for a single issue.
+// WIPViolations lists the limits that are not enforced but that the
+// updated issue's workflow state or assignee is over.
+type BulkResult struct {
+	ID            string     `json:"id"`
+	OK            bool       `json:"ok"`
+	Error         string     `json:"error,omitempty"`
+	Issue         *Issue     `json:"issue,omitempty"`
+	WIPViolations []WIPUsage `json:"wip_violations,omitempty"`
+}
+
+// issueColumns lists the issue columns in the order scanIssue reads them.
+const issueColumns = `id, workspace_id, number, branch, title, description, status, priority, assignee_id, estimate, cycle_id, labels, parent_id, project_id, milestone_id, start_date, due_date, created_at, updated_at, completed_at`
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanIssue reads an issue selected with issueColumns.
+func scanIssue(row rowScanner) (*Issue, error) {
+	var issue Issue
+	var labelsJSON string
+
+	err := row.Scan(
+		&issue.ID,
+		&issue.WorkspaceID,
+		&issue.Number,
+		&issue.Branch,
+		&issue.Title,
+		&issue.Description,
+		&issue.Status,
+		&issue.Priority,
+		&issue.AssigneeID,
+		&issue.Estimate,
+		&issue.CycleID,
+		&labelsJSON,
+		&issue.ParentID,
+		&issue.ProjectID,
+		&issue.MilestoneID,
+		&issue.StartDate,
+		&issue.DueDate,
+		&issue.CreatedAt,
+		&issue.UpdatedAt,
+		&issue.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	json.Unmarshal([]byte(labelsJSON), &issue.Labels)
+
+	return &issue, nil
+}
+
 // IssueRepository handles issue database operations.
 type IssueRepository struct {
-	db *DB
+	db Querier
 }
 
-// NewIssueRepository creates a new issue repository.
-func NewIssueRepository(db *DB) *IssueRepository {
+// NewIssueRepository creates a new issue repository. Pass a *Tx to run its
+// operations inside a transaction.
+func NewIssueRepository(db Querier) *IssueRepository {
 	return &IssueRepository{db: db}
 }
 
-// Create inserts a new issue.
+// Create inserts a new issue, checking that its parent, project and
+// milestone are valid.
 func (r *IssueRepository) Create(issue *Issue) error {
 	now := time.Now()
 	issue.CreatedAt = now
 	issue.UpdatedAt = now
 
+	return inTx(r.db, func(tx *Tx) error {
+		if err := checkParent(tx, issue); err != nil {
+			return err
+		}
+		if err := checkProject(tx, issue); err != nil {
+			return err
+		}
+		return r.insert(tx, issue)
+	})
+}
+
+// Insert stores an issue exactly as given, preserving its timestamps.
+// Importers use it to load historical data. An issue without a number is
+// given the next one in its workspace. The parent is not checked, so
+// children may be loaded before their parents.
+func (r *IssueRepository) Insert(issue *Issue) error {
+	return inTx(r.db, func(tx *Tx) error {
+		return r.insert(tx, issue)
+	})
+}
+
+func (r *IssueRepository) insert(tx *Tx, issue *Issue) error {
+	if issue.Number == 0 {
+		// Two transactions reading the same maximum would hand out the same
+		// number, so Postgres creates in a workspace queue on its row.
+		// SQLite already admits only one writer at a time.
+		if tx.dialect == Postgres {
+			if _, err := tx.Exec(`SELECT id FROM workspaces WHERE id = ? FOR UPDATE`, issue.WorkspaceID); err != nil {
+				return fmt.Errorf("failed to lock workspace: %w", err)
+			}
+		}
+		err := tx.QueryRow(`SELECT COALESCE(MAX(number), 0) + 1 FROM issues WHERE workspace_id = ?`, issue.WorkspaceID).Scan(&issue.Number)
+		if err != nil {
+			return fmt.Errorf("failed to number issue: %w", err)
+		}
+	}
+
 	labelsJSON, _ := json.Marshal(issue.Labels)
 
 	query := `
-		INSERT INTO issues (id, workspace_id, title, description, status, priority, assignee_id, estimate, cycle_id, labels, parent_id, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO issues (` + issueColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := r.db.Exec(query,
+	_, err := tx.Exec(query,
 		issue.ID,
 		issue.WorkspaceID,
+		issue.Number,
+		issue.Branch,
 		issue.Title,
 		issue.Description,
 		issue.Status,
@@ -61,39 +279,26 @@ func (r *IssueRepository) Create(issue *Issue) error {
 		issue.CycleID,
 		string(labelsJSON),
 		issue.ParentID,
+		issue.ProjectID,
+		issue.MilestoneID,
+		issue.StartDate,
+		issue.DueDate,
 		issue.CreatedAt,
 		issue.UpdatedAt,
+		issue.CompletedAt,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create issue: %w", err)
 	}
 
-	return nil
+	return setKeys(tx, issue)
 }
 
 // GetByID retrieves an issue by ID.
 func (r *IssueRepository) GetByID(id string) (*Issue, error) {
-	query := `SELECT * FROM issues WHERE id = ?`
+	query := `SELECT ` + issueColumns + ` FROM issues WHERE id = ?`
 
-	var issue Issue
-	var labelsJSON string
-
-	err := r.db.QueryRow(query, id).Scan(
-		&issue.ID,
-		&issue.WorkspaceID,
-		&issue.Title,
-		&issue.Description,
-		&issue.Status,
-		&issue.Priority,
-		&issue.AssigneeID,
-		&issue.Estimate,
-		&issue.CycleID,
-		&labelsJSON,
-		&issue.ParentID,
-		&issue.CreatedAt,
-		&issue.UpdatedAt,
-		&issue.CompletedAt,
-	)
+	issue, err := scanIssue(r.db.QueryRow(query, id))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -101,14 +306,27 @@ func (r *IssueRepository) GetByID(id string) (*Issue, error) {
 		return nil, fmt.Errorf("failed to get issue: %w", err)
 	}
 
-	json.Unmarshal([]byte(labelsJSON), &issue.Labels)
+	return issue, setKeys(r.db, issue)
+}
 
-	return &issue, nil
+// GetByNumber retrieves an issue by its number within a workspace.
+func (r *IssueRepository) GetByNumber(workspaceID string, number int) (*Issue, error) {
+	query := `SELECT ` + issueColumns + ` FROM issues WHERE workspace_id = ? AND number = ?`
+
+	issue, err := scanIssue(r.db.QueryRow(query, workspaceID, number))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issue: %w", err)
+	}
+
+	return issue, setKeys(r.db, issue)
 }
 
 // List retrieves issues with optional filters.
 func (r *IssueRepository) List(workspaceID, status string, limit, offset int) ([]*Issue, error) {
-	query := `SELECT * FROM issues WHERE workspace_id = ?`
+	query := `SELECT ` + issueColumns + ` FROM issues WHERE workspace_id = ?`
 	args := []interface{}{workspaceID}
 
 	if status != "" {
@@ -135,40 +353,148 @@ func (r *IssueRepository) List(workspaceID, status string, limit, offset int) ([
 
 	var issues []*Issue
 	for rows.Next() {
-		var issue Issue
-		var labelsJSON string
-
-		err := rows.Scan(
-			&issue.ID,
-			&issue.WorkspaceID,
-			&issue.Title,
-			&issue.Description,
-			&issue.Status,
-			&issue.Priority,
-			&issue.AssigneeID,
-			&issue.Estimate,
-			&issue.CycleID,
-			&labelsJSON,
-			&issue.ParentID,
-			&issue.CreatedAt,
-			&issue.UpdatedAt,
-			&issue.CompletedAt,
-		)
+		issue, err := scanIssue(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan issue: %w", err)
 		}
+		issues = append(issues, issue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return issues, setKeys(r.db, issues...)
+}
+
+// ListByCycle retrieves every issue assigned to a cycle.
+func (r *IssueRepository) ListByCycle(cycleID string) ([]*Issue, error) {
+	issues, err := r.list(`SELECT `+issueColumns+` FROM issues WHERE cycle_id = ? ORDER BY priority ASC, created_at DESC`, cycleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cycle issues: %w", err)
+	}
+	return issues, nil
+}
 
-		json.Unmarshal([]byte(labelsJSON), &issue.Labels)
-		issues = append(issues, &issue)
+// ListByProject retrieves every issue in a project.
+func (r *IssueRepository) ListByProject(projectID string) ([]*Issue, error) {
+	issues, err := r.list(`SELECT `+issueColumns+` FROM issues WHERE project_id = ? ORDER BY priority ASC, created_at DESC`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list project issues: %w", err)
 	}
+	return issues, nil
+}
 
+// ListByMilestone retrieves every issue targeting a milestone.
+func (r *IssueRepository) ListByMilestone(milestoneID string) ([]*Issue, error) {
+	issues, err := r.list(`SELECT `+issueColumns+` FROM issues WHERE milestone_id = ? ORDER BY priority ASC, created_at DESC`, milestoneID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list milestone issues: %w", err)
+	}
 	return issues, nil
 }
 
-// Update updates an existing issue.
+func (r *IssueRepository) list(query string, args ...interface{}) ([]*Issue, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var issues []*Issue
+	for rows.Next() {
+		issue, err := scanIssue(rows)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, issue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return issues, setKeys(r.db, issues...)
+}
+
+// Update updates an existing issue and records the fields that changed in
+// its history. Changing the status sets or clears CompletedAt.
 func (r *IssueRepository) Update(issue *Issue) error {
-	issue.UpdatedAt = time.Now()
+	return inTx(r.db, func(tx *Tx) error {
+		return r.update(tx, issue)
+	})
+}
 
+func (r *IssueRepository) update(tx *Tx, issue *Issue) error {
+	before, err := scanIssue(tx.QueryRow(`SELECT `+issueColumns+` FROM issues WHERE id = ?`, issue.ID))
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("issue %s not found", issue.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get issue: %w", err)
+	}
+
+	if issue.ParentID != before.ParentID {
+		if err := checkParent(tx, issue); err != nil {
+			return err
+		}
+	}
+	if issue.ProjectID != before.ProjectID && issue.MilestoneID == before.MilestoneID {
+		// Moving an issue to another project drops its old milestone.
+		issue.MilestoneID = ""
+	}
+	if issue.ProjectID != before.ProjectID || issue.MilestoneID != before.MilestoneID {
+		if err := checkProject(tx, issue); err != nil {
+			return err
+		}
+	}
+
+	if err := checkWIP(tx, before, issue); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	issue.UpdatedAt = now
+	if issue.Status != before.Status {
+		if issue.Status == "done" {
+			issue.CompletedAt = &now
+		} else {
+			issue.CompletedAt = nil
+		}
+	}
+
+	if err := write(tx, issue); err != nil {
+		return err
+	}
+
+	return recordChanges(tx, before, issue, now)
+}
+
+// Replace overwrites an issue exactly as given, preserving its timestamps.
+// Importers use it to refresh issues loaded earlier: workflow rules such as
+// WIP limits are not applied, and changed fields are recorded in history at
+// the issue's UpdatedAt. A new parent must still form a valid hierarchy.
+func (r *IssueRepository) Replace(issue *Issue) error {
+	return inTx(r.db, func(tx *Tx) error {
+		before, err := scanIssue(tx.QueryRow(`SELECT `+issueColumns+` FROM issues WHERE id = ?`, issue.ID))
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("issue %s not found", issue.ID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get issue: %w", err)
+		}
+		if issue.ParentID != before.ParentID {
+			if err := checkParent(tx, issue); err != nil {
+				return err
+			}
+		}
+		if err := write(tx, issue); err != nil {
+			return err
+		}
+		return recordChanges(tx, before, issue, issue.UpdatedAt)
+	})
+}
+
+// write saves every mutable field of an issue as given.
+func write(tx *Tx, issue *Issue) error {
 	labelsJSON, _ := json.Marshal(issue.Labels)
 
 	query := `
@@ -182,12 +508,17 @@ func (r *IssueRepository) Update(issue *Issue) error {
 			cycle_id = ?,
 			labels = ?,
 			parent_id = ?,
+			project_id = ?,
+			milestone_id = ?,
+			start_date = ?,
+			due_date = ?,
+			branch = ?,
 			updated_at = ?,
 			completed_at = ?
 		WHERE id = ?
 	`
 
-	_, err := r.db.Exec(query,
+	_, err := tx.Exec(query,
 		issue.Title,
 		issue.Description,
 		issue.Status,
@@ -197,6 +528,11 @@ func (r *IssueRepository) Update(issue *Issue) error {
 		issue.CycleID,
 		string(labelsJSON),
 		issue.ParentID,
+		issue.ProjectID,
+		issue.MilestoneID,
+		issue.StartDate,
+		issue.DueDate,
+		issue.Branch,
 		issue.UpdatedAt,
 		issue.CompletedAt,
 		issue.ID,
@@ -204,42 +540,236 @@ func (r *IssueRepository) Update(issue *Issue) error {
 	if err != nil {
 		return fmt.Errorf("failed to update issue: %w", err)
 	}
-
-	return nil
+	return setKeys(tx, issue)
 }
 
 // UpdateStatus updates only the status of an issue.
 func (r *IssueRepository) UpdateStatus(id, status string) error {
-	now := time.Now()
+	return inTx(r.db, func(tx *Tx) error {
+		issue, err := scanIssue(tx.QueryRow(`SELECT `+issueColumns+` FROM issues WHERE id = ?`, id))
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("issue %s not found", id)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get issue: %w", err)
+		}
 
-	var completedAt *time.Time
-	if status == "done" {
-		completedAt = &now
-	}
+		issue.Status = status
+		if err := r.update(tx, issue); err != nil {
+			return fmt.Errorf("failed to update issue status: %w", err)
+		}
+		return nil
+	})
+}
 
-	query := `
-		UPDATE issues SET status = ?, updated_at = ?, completed_at = ?
-		WHERE id = ?
-	`
+// Delete removes an issue by ID along with its comments, relations and
+// history. Its sub-issues move up to the deleted issue's parent.
+func (r *IssueRepository) Delete(id string) error {
+	return inTx(r.db, func(tx *Tx) error {
+		issue, err := scanIssue(tx.QueryRow(`SELECT `+issueColumns+` FROM issues WHERE id = ?`, id))
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get issue: %w", err)
+		}
 
-	_, err := r.db.Exec(query, status, now, completedAt, id)
+		children, err := NewIssueRepository(tx).ListChildren(id)
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			child.ParentID = issue.ParentID
+			if err := r.update(tx, child); err != nil {
+				return fmt.Errorf("failed to reparent %s: %w", child.ID, err)
+			}
+		}
+
+		return deleteIssue(tx, id)
+	})
+}
+
+// DeleteTree removes an issue and all of its sub-issues, returning the IDs
+// of every deleted issue.
+func (r *IssueRepository) DeleteTree(id string) ([]string, error) {
+	var deleted []string
+
+	err := inTx(r.db, func(tx *Tx) error {
+		queue := []string{id}
+		seen := map[string]bool{id: true}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+
+			rows, err := tx.Query(`SELECT id FROM issues WHERE parent_id = ?`, current)
+			if err != nil {
+				return fmt.Errorf("failed to list sub-issues: %w", err)
+			}
+			for rows.Next() {
+				var child string
+				if err := rows.Scan(&child); err != nil {
+					rows.Close()
+					return fmt.Errorf("failed to scan sub-issue: %w", err)
+				}
+				if !seen[child] {
+					seen[child] = true
+					queue = append(queue, child)
+				}
+			}
+			rows.Close()
+			if err := rows.Err(); err != nil {
+				return err
+			}
+
+			if err := deleteIssue(tx, current); err != nil {
+				return err
+			}
+			deleted = append(deleted, current)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to update issue status: %w", err)
+		return nil, err
 	}
 
+	return deleted, nil
+}
+
+// deleteIssue removes one issue and the rows that belong to it.
+func deleteIssue(tx *Tx, id string) error {
+	if _, err := tx.Exec(`DELETE FROM comments WHERE issue_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete issue comments: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM issue_relations WHERE issue_id = ? OR related_issue_id = ?`, id, id); err != nil {
+		return fmt.Errorf("failed to delete issue relations: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM issue_events WHERE issue_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete issue history: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM issues WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete issue: %w", err)
+	}
 	return nil
 }
 
-// Delete removes an issue by ID.
-func (r *IssueRepository) Delete(id string) error {
-	query := `DELETE FROM issues WHERE id = ?`
+// BulkUpdate applies change to every issue in ids inside a single
+// transaction. Issues that do not exist, belong to another workspace or
+// would pass an enforced WIP limit are reported as failed results; any
+// database error rolls back the whole batch.
+func (r *IssueRepository) BulkUpdate(workspaceID string, ids []string, change *IssueChange) ([]*BulkResult, error) {
+	now := time.Now()
+	results := make([]*BulkResult, 0, len(ids))
+
+	err := inTx(r.db, func(tx *Tx) error {
+		for _, id := range ids {
+			result := &BulkResult{ID: id}
+			results = append(results, result)
+
+			issue, err := scanIssue(tx.QueryRow(`SELECT `+issueColumns+` FROM issues WHERE id = ?`, id))
+			if err == sql.ErrNoRows {
+				result.Error = "issue not found"
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to get issue %s: %w", id, err)
+			}
+			if workspaceID != "" && issue.WorkspaceID != workspaceID {
+				result.Error = "issue belongs to another workspace"
+				continue
+			}
+
+			if change.CycleID != nil && *change.CycleID != "" {
+				var cycleWorkspace string
+				err := tx.QueryRow(`SELECT workspace_id FROM cycles WHERE id = ?`, *change.CycleID).Scan(&cycleWorkspace)
+				if err == sql.ErrNoRows {
+					result.Error = "cycle not found"
+					continue
+				}
+				if err != nil {
+					return fmt.Errorf("failed to get cycle: %w", err)
+				}
+				if cycleWorkspace != issue.WorkspaceID {
+					result.Error = "cycle belongs to another workspace"
+					continue
+				}
+			}
+
+			before := *issue
+			change.Apply(issue, now)
+			issue.UpdatedAt = now
+
+			if err := checkWIP(tx, &before, issue); errors.Is(err, ErrWIPLimit) {
+				result.Error = err.Error()
+				continue
+			} else if err != nil {
+				return err
+			}
+
+			labels, _ := json.Marshal(issue.Labels)
+
+			_, err = tx.Exec(`
+				UPDATE issues SET
+					status = ?,
+					priority = ?,
+					assignee_id = ?,
+					estimate = ?,
+					cycle_id = ?,
+					labels = ?,
+					updated_at = ?,
+					completed_at = ?
+				WHERE id = ?
+			`,
+				issue.Status,
+				issue.Priority,
+				issue.AssigneeID,
+				issue.Estimate,
+				issue.CycleID,
+				string(labels),
+				issue.UpdatedAt,
+				issue.CompletedAt,
+				issue.ID,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to update issue %s: %w", id, err)
+			}
+			if err := recordChanges(tx, &before, issue, now); err != nil {
+				return err
+			}
+
+			if err := setKeys(tx, issue); err != nil {
+				return err
+			}
+			result.OK = true
+			result.Issue = issue
+		}
 
-	_, err := r.db.Exec(query, id)
+		// Warn once every change is in, so each issue sees the final counts.
+		settings := make(map[string]*WorkspaceSettings)
+		for _, result := range results {
+			if !result.OK {
+				continue
+			}
+			ws, ok := settings[result.Issue.WorkspaceID]
+			if !ok {
+				var err error
+				if ws, err = wipSettings(tx, result.Issue.WorkspaceID); err != nil {
+					return err
+				}
+				settings[result.Issue.WorkspaceID] = ws
+			}
+			violations, err := wipViolations(tx, ws, result.Issue)
+			if err != nil {
+				return err
+			}
+			result.WIPViolations = violations
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to delete issue: %w", err)
+		return nil, err
 	}
 
-	return nil
+	return results, nil
 }
 
 // CountByStatus counts issues by status for a workspace.
@@ -268,7 +798,7 @@ func (r *IssueRepository) CountByStatus(workspaceID string) (map[string]int, err
 // CountByCycle counts issues by cycle for a workspace.
 func (r *IssueRepository) CountByCycle(workspaceID, cycleID string) (total, completed int, err error) {
 	query := `
-		SELECT COUNT(*), SUM(CASE WHEN status = 'done' THEN 1 ELSE 0 END)
+		SELECT COUNT(*), COALESCE(SUM(CASE WHEN status = 'done' THEN 1 ELSE 0 END), 0)
 		FROM issues WHERE workspace_id = ? AND cycle_id = ?
 	`
 
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// IssueKey formats an issue key such as PUL-42.
+func IssueKey(prefix string, number int) string {
+	return prefix + "-" + strconv.Itoa(number)
+}
+
+// ParseIssueKey splits a key such as PUL-42 (in any case) into its
+// upper-cased prefix and number.
+func ParseIssueKey(key string) (prefix string, number int, ok bool) {
+	i := strings.LastIndexByte(key, '-')
+	if i <= 0 || i == len(key)-1 {
+		return "", 0, false
+	}
+	prefix = key[:i]
+	for _, r := range prefix {
+		if !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9') {
+			return "", 0, false
+		}
+	}
+	number, err := strconv.Atoi(key[i+1:])
+	if err != nil || number <= 0 {
+		return "", 0, false
+	}
+	return strings.ToUpper(prefix), number, true
+}
+
+// setKeys fills in Key on each issue from its workspace's prefix.
+func setKeys(q Querier, issues ...*Issue) error {
+	prefixes := make(map[string]string)
+	for _, issue := range issues {
+		prefix, ok := prefixes[issue.WorkspaceID]
+		if !ok {
+			var raw sql.NullString
+			err := q.QueryRow(`SELECT settings FROM workspaces WHERE id = ?`, issue.WorkspaceID).Scan(&raw)
+			if err != nil && err != sql.ErrNoRows {
+				return fmt.Errorf("failed to get workspace settings: %w", err)
+			}
+			prefix = DefaultIssuePrefix
+			if settings, err := parseSettings(raw.String); err == nil {
+				prefix = settings.KeyPrefix()
+			}
+			prefixes[issue.WorkspaceID] = prefix
+		}
+		issue.Key = IssueKey(prefix, issue.Number)
+	}
+	return nil
+}
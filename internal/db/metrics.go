@@ -0,0 +1,262 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Breakdown groupings.
+const (
+	GroupAssignee = "assignee"
+	GroupLabel    = "label"
+	GroupPriority = "priority"
+	GroupProject  = "project"
+)
+
+// GroupBys lists the groupings a breakdown accepts.
+var GroupBys = []string{GroupAssignee, GroupLabel, GroupPriority, GroupProject}
+
+// ValidGroupBy reports whether g is a known breakdown grouping.
+func ValidGroupBy(g string) bool {
+	for _, v := range GroupBys {
+		if v == g {
+			return true
+		}
+	}
+	return false
+}
+
+// BreakdownRow holds the delivery metrics of one group of issues. An
+// issue with several labels counts toward each of them; the empty key
+// groups unassigned, unlabeled or projectless issues.
+type BreakdownRow struct {
+	Key string `json:"key"`
+
+	// WIP is the work in progress now.
+	WIP       int `json:"wip"`
+	WIPPoints int `json:"wip_points"`
+
+	// Throughput is the work completed in the window, and cycle time how
+	// long it took from first moving to in_progress (or creation) to done.
+	Throughput        int     `json:"throughput"`
+	ThroughputPoints  int     `json:"throughput_points"`
+	CycleTimeAvgHours float64 `json:"cycle_time_avg_hours"`
+	CycleTimeMaxHours float64 `json:"cycle_time_max_hours"`
+
+	// Velocity covers the most recent cycles: points planned and
+	// completed, and completed points per cycle.
+	VelocityPlanned   int     `json:"velocity_planned"`
+	VelocityCompleted int     `json:"velocity_completed"`
+	VelocityAverage   float64 `json:"velocity_average"`
+}
+
+// MetricsRepository aggregates delivery metrics in the database.
+type MetricsRepository struct {
+	db Querier
+}
+
+// NewMetricsRepository creates a new metrics repository. Pass a *Tx to run
+// its queries inside a transaction.
+func NewMetricsRepository(db Querier) *MetricsRepository {
+	return &MetricsRepository{db: db}
+}
+
+// Totals sums a workspace's estimates and counts its bugs.
+type Totals struct {
+	TotalPoints     int
+	CompletedPoints int
+	Bugs            int
+}
+
+// Totals computes the points planned and completed across a workspace and
+// the number of issues labeled bugLabel.
+func (r *MetricsRepository) Totals(workspaceID, bugLabel string) (*Totals, error) {
+	labeled := `EXISTS (SELECT 1 FROM json_each(` + labelsArray + `) WHERE value = ?)`
+	if dialectOf(r.db) == Postgres {
+		labeled = `EXISTS (SELECT 1 FROM jsonb_array_elements_text((` + labelsArray + `)::jsonb) AS l(value) WHERE l.value = ?)`
+	}
+	query := `
+		SELECT COALESCE(SUM(estimate), 0),
+			COALESCE(SUM(CASE WHEN status = 'done' THEN estimate ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN ` + labeled + ` THEN 1 ELSE 0 END), 0)
+		FROM issues i WHERE workspace_id = ?
+	`
+
+	var t Totals
+	if err := r.db.QueryRow(query, bugLabel, workspaceID).Scan(&t.TotalPoints, &t.CompletedPoints, &t.Bugs); err != nil {
+		return nil, fmt.Errorf("failed to total issues: %w", err)
+	}
+	return &t, nil
+}
+
+// StatusCount is the number of issues in one workflow state of a
+// workspace.
+type StatusCount struct {
+	WorkspaceID string
+	Status      string
+	Count       int
+}
+
+// CountByStatus counts the issues in each workflow state of every
+// workspace.
+func (r *MetricsRepository) CountByStatus() ([]StatusCount, error) {
+	rows, err := r.db.Query(`SELECT workspace_id, status, COUNT(*) FROM issues GROUP BY workspace_id, status ORDER BY workspace_id, status`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count issues: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []StatusCount
+	for rows.Next() {
+		var c StatusCount
+		if err := rows.Scan(&c.WorkspaceID, &c.Status, &c.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan issue count: %w", err)
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
+// Breakdown computes WIP, throughput and cycle time for issues completed
+// since the given time, and velocity over the last cycles cycles, for each
+// group of a workspace's issues. Groups are ordered by key.
+func (r *MetricsRepository) Breakdown(workspaceID, groupBy string, since time.Time, cycles int) ([]*BreakdownRow, error) {
+	if !ValidGroupBy(groupBy) {
+		return nil, fmt.Errorf("unknown grouping %q", groupBy)
+	}
+	dialect := dialectOf(r.db)
+	key, join := groupSource(dialect, groupBy)
+
+	rows := make(map[string]*BreakdownRow)
+	row := func(k string) *BreakdownRow {
+		if groupBy == GroupPriority {
+			n, _ := strconv.Atoi(k)
+			k = PriorityName(n)
+		}
+		b, ok := rows[k]
+		if !ok {
+			b = &BreakdownRow{Key: k}
+			rows[k] = b
+		}
+		return b
+	}
+
+	err := r.scan(`
+		SELECT `+key+`, COUNT(*), COALESCE(SUM(i.estimate), 0)
+		FROM issues i `+join+`
+		WHERE i.workspace_id = ? AND i.status = 'in_progress'
+		GROUP BY `+key,
+		[]interface{}{workspaceID},
+		func(k string, vals []float64) {
+			b := row(k)
+			b.WIP, b.WIPPoints = int(vals[0]), int(vals[1])
+		}, 2)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count work in progress: %w", err)
+	}
+
+	// SQLite stores times as text with the writer's offset, so they are
+	// compared as instants through julianday rather than as strings.
+	hours := `(julianday(i.completed_at) - julianday(COALESCE(s.started, i.created_at))) * 24`
+	completedSince := `julianday(i.completed_at) >= julianday(?)`
+	if dialect == Postgres {
+		hours = `EXTRACT(EPOCH FROM (i.completed_at - COALESCE(s.started, i.created_at))) / 3600`
+		completedSince = `i.completed_at >= ?`
+	}
+	err = r.scan(`
+		SELECT `+key+`, COUNT(*), COALESCE(SUM(i.estimate), 0), COALESCE(AVG(`+hours+`), 0), COALESCE(MAX(`+hours+`), 0)
+		FROM issues i `+join+`
+		LEFT JOIN (
+			SELECT issue_id, MIN(created_at) AS started FROM issue_events
+			WHERE field = 'status' AND new_value = 'in_progress'
+			GROUP BY issue_id
+		) s ON s.issue_id = i.id
+		WHERE i.workspace_id = ? AND i.status = 'done' AND `+completedSince+`
+		GROUP BY `+key,
+		[]interface{}{workspaceID, since.UTC()},
+		func(k string, vals []float64) {
+			b := row(k)
+			b.Throughput, b.ThroughputPoints = int(vals[0]), int(vals[1])
+			b.CycleTimeAvgHours, b.CycleTimeMaxHours = vals[2], vals[3]
+		}, 4)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure throughput: %w", err)
+	}
+
+	recent := `SELECT id FROM cycles WHERE workspace_id = ? AND start_date IS NOT NULL ORDER BY start_date DESC LIMIT ?`
+	var cycleCount int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM (`+recent+`) c`, workspaceID, cycles).Scan(&cycleCount); err != nil {
+		return nil, fmt.Errorf("failed to count cycles: %w", err)
+	}
+	err = r.scan(`
+		SELECT `+key+`, COALESCE(SUM(i.estimate), 0), COALESCE(SUM(CASE WHEN i.status = 'done' THEN i.estimate ELSE 0 END), 0)
+		FROM issues i `+join+`
+		WHERE i.workspace_id = ? AND i.status != 'canceled' AND i.cycle_id IN (`+recent+`)
+		GROUP BY `+key,
+		[]interface{}{workspaceID, workspaceID, cycles},
+		func(k string, vals []float64) {
+			b := row(k)
+			b.VelocityPlanned, b.VelocityCompleted = int(vals[0]), int(vals[1])
+			if cycleCount > 0 {
+				b.VelocityAverage = vals[1] / float64(cycleCount)
+			}
+		}, 2)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure velocity: %w", err)
+	}
+
+	result := make([]*BreakdownRow, 0, len(rows))
+	for _, b := range rows {
+		result = append(result, b)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result, nil
+}
+
+// scan runs a grouped query whose rows are a key followed by n numbers.
+func (r *MetricsRepository) scan(query string, args []interface{}, fn func(key string, vals []float64), n int) error {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key string
+		vals := make([]float64, n)
+		dest := []interface{}{&key}
+		for i := range vals {
+			dest = append(dest, &vals[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+		fn(key, vals)
+	}
+	return rows.Err()
+}
+
+// labelsArray is the labels of issue i as a JSON array. Issues saved
+// without labels store NULL or "null".
+const labelsArray = `CASE WHEN i.labels IS NULL OR i.labels IN ('', 'null') THEN '[]' ELSE i.labels END`
+
+// groupSource returns the SQL expression that keys a grouping over issues
+// aliased i, and any join it needs. Labels are stored as a JSON array, so
+// grouping by label expands it into one row per label.
+func groupSource(d Dialect, groupBy string) (key, join string) {
+	switch groupBy {
+	case GroupLabel:
+		if d == Postgres {
+			return `COALESCE(l.value, '')`, `LEFT JOIN LATERAL jsonb_array_elements_text((` + labelsArray + `)::jsonb) AS l(value) ON true`
+		}
+		return `COALESCE(l.value, '')`, `LEFT JOIN json_each(` + labelsArray + `) l`
+	case GroupPriority:
+		return `CAST(i.priority AS TEXT)`, ``
+	case GroupProject:
+		return `COALESCE(i.project_id, '')`, ``
+	default:
+		return `COALESCE(i.assignee_id, '')`, ``
+	}
+}
@@ -0,0 +1,527 @@
+package db
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Migration is a single numbered schema change. Migrations are applied in
+// order and never edited once released: the checksum of Up is recorded when
+// a migration is applied and verified on every subsequent run.
+//
+// Scripts are written in portable SQL with SQLite type names; DATETIME is
+// mapped to TIMESTAMPTZ when running against PostgreSQL.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	// Down reverts Up. Migrations without a Down cannot be rolled back.
+	Down string
+}
+
+// Checksum returns the hex-encoded SHA-256 of the migration's Up script.
+func (m Migration) Checksum() string {
+	sum := sha256.Sum256([]byte(m.Up))
+	return hex.EncodeToString(sum[:])
+}
+
+// MigrationStatus reports whether a migration has been applied.
+type MigrationStatus struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at"`
+	// Modified is set when the recorded checksum differs from the
+	// migration compiled into this binary.
+	Modified bool `json:"modified"`
+}
+
+// migrations is the ordered list of schema changes. Append new migrations
+// to the end with the next version number; never reorder or edit them.
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "initial schema",
+		Up: `
+			CREATE TABLE IF NOT EXISTS workspaces (
+				id TEXT PRIMARY KEY,
+				name TEXT NOT NULL,
+				description TEXT,
+				settings TEXT,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			);
+
+			CREATE TABLE IF NOT EXISTS issues (
+				id TEXT PRIMARY KEY,
+				workspace_id TEXT NOT NULL,
+				title TEXT NOT NULL,
+				description TEXT,
+				status TEXT DEFAULT 'backlog',
+				priority INTEGER DEFAULT 0,
+				assignee_id TEXT,
+				estimate INTEGER,
+				cycle_id TEXT,
+				labels TEXT,
+				parent_id TEXT,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				completed_at DATETIME,
+				FOREIGN KEY (workspace_id) REFERENCES workspaces(id)
+			);
+
+			CREATE TABLE IF NOT EXISTS cycles (
+				id TEXT PRIMARY KEY,
+				workspace_id TEXT NOT NULL,
+				name TEXT NOT NULL,
+				start_date DATETIME,
+				end_date DATETIME,
+				status TEXT DEFAULT 'upcoming',
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (workspace_id) REFERENCES workspaces(id)
+			);
+
+			CREATE TABLE IF NOT EXISTS users (
+				id TEXT PRIMARY KEY,
+				email TEXT UNIQUE NOT NULL,
+				name TEXT,
+				avatar_url TEXT,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_issues_workspace ON issues(workspace_id);
+			CREATE INDEX IF NOT EXISTS idx_issues_status ON issues(status);
+			CREATE INDEX IF NOT EXISTS idx_issues_assignee ON issues(assignee_id);
+			CREATE INDEX IF NOT EXISTS idx_issues_cycle ON issues(cycle_id);
+			CREATE INDEX IF NOT EXISTS idx_cycles_workspace ON cycles(workspace_id);
+			CREATE INDEX IF NOT EXISTS idx_cycles_status ON cycles(status);
+		`,
+	},
+	{
+		Version: 2,
+		Name:    "default workspace",
+		Up: `
+			INSERT INTO workspaces (id, name, description, settings, created_at, updated_at)
+			SELECT 'default', 'Main Workspace', 'Default workspace for tracking', '{}', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+			WHERE NOT EXISTS (SELECT 1 FROM workspaces);
+		`,
+	},
+	{
+		Version: 3,
+		Name:    "comments and issue relations",
+		Up: `
+			CREATE TABLE comments (
+				id TEXT PRIMARY KEY,
+				issue_id TEXT NOT NULL,
+				author_id TEXT,
+				body TEXT NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (issue_id) REFERENCES issues(id)
+			);
+
+			CREATE TABLE issue_relations (
+				id TEXT PRIMARY KEY,
+				issue_id TEXT NOT NULL,
+				related_issue_id TEXT NOT NULL,
+				type TEXT NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (issue_id) REFERENCES issues(id),
+				FOREIGN KEY (related_issue_id) REFERENCES issues(id)
+			);
+
+			CREATE INDEX idx_comments_issue ON comments(issue_id);
+			CREATE INDEX idx_relations_issue ON issue_relations(issue_id);
+			CREATE INDEX idx_relations_related ON issue_relations(related_issue_id);
+		`,
+		Down: `
+			DROP TABLE issue_relations;
+			DROP TABLE comments;
+		`,
+	},
+	{
+		Version: 4,
+		Name:    "external references",
+		Up: `
+			CREATE TABLE external_refs (
+				source TEXT NOT NULL,
+				entity TEXT NOT NULL,
+				external_id TEXT NOT NULL,
+				entity_id TEXT NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (source, entity, external_id)
+			);
+
+			CREATE INDEX idx_external_refs_entity ON external_refs(entity, entity_id);
+		`,
+		Down: `
+			DROP TABLE external_refs;
+		`,
+	},
+	{
+		// Issues completed before history was recorded get a single status
+		// event at completed_at with an unknown ('') previous status.
+		Version: 5,
+		Name:    "issue history",
+		Up: `
+			CREATE TABLE issue_events (
+				id TEXT PRIMARY KEY,
+				issue_id TEXT NOT NULL,
+				workspace_id TEXT NOT NULL,
+				field TEXT NOT NULL,
+				old_value TEXT NOT NULL DEFAULT '',
+				new_value TEXT NOT NULL DEFAULT '',
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			);
+
+			CREATE INDEX idx_issue_events_issue ON issue_events(issue_id, created_at);
+			CREATE INDEX idx_issue_events_workspace ON issue_events(workspace_id, created_at);
+
+			INSERT INTO issue_events (id, issue_id, workspace_id, field, old_value, new_value, created_at)
+			SELECT 'event_backfill_' || id, id, workspace_id, 'status', '', 'done', completed_at
+			FROM issues WHERE status = 'done' AND completed_at IS NOT NULL;
+		`,
+		Down: `
+			DROP TABLE issue_events;
+		`,
+	},
+	{
+		// Existing issues are numbered per workspace in creation order.
+		Version: 6,
+		Name:    "issue numbers and branches",
+		Up: `
+			ALTER TABLE issues ADD COLUMN number INTEGER NOT NULL DEFAULT 0;
+			ALTER TABLE issues ADD COLUMN branch TEXT NOT NULL DEFAULT '';
+
+			UPDATE issues SET number = (
+				SELECT COUNT(*) FROM issues AS earlier
+				WHERE earlier.workspace_id = issues.workspace_id
+				AND (earlier.created_at < issues.created_at
+					OR (earlier.created_at = issues.created_at AND earlier.id <= issues.id))
+			);
+
+			CREATE UNIQUE INDEX idx_issues_number ON issues(workspace_id, number);
+		`,
+		Down: `
+			DROP INDEX idx_issues_number;
+			ALTER TABLE issues DROP COLUMN branch;
+			ALTER TABLE issues DROP COLUMN number;
+		`,
+	},
+	{
+		Version: 7,
+		Name:    "projects and milestones",
+		Up: `
+			CREATE TABLE projects (
+				id TEXT PRIMARY KEY,
+				workspace_id TEXT NOT NULL,
+				name TEXT NOT NULL,
+				description TEXT NOT NULL DEFAULT '',
+				lead_id TEXT NOT NULL DEFAULT '',
+				status TEXT NOT NULL DEFAULT 'planned',
+				target_date DATETIME,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (workspace_id) REFERENCES workspaces(id)
+			);
+
+			CREATE TABLE milestones (
+				id TEXT PRIMARY KEY,
+				project_id TEXT NOT NULL,
+				name TEXT NOT NULL,
+				description TEXT NOT NULL DEFAULT '',
+				target_date DATETIME,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (project_id) REFERENCES projects(id)
+			);
+
+			ALTER TABLE issues ADD COLUMN project_id TEXT NOT NULL DEFAULT '';
+			ALTER TABLE issues ADD COLUMN milestone_id TEXT NOT NULL DEFAULT '';
+
+			CREATE INDEX idx_projects_workspace ON projects(workspace_id);
+			CREATE INDEX idx_milestones_project ON milestones(project_id);
+			CREATE INDEX idx_issues_project ON issues(project_id);
+			CREATE INDEX idx_issues_milestone ON issues(milestone_id);
+		`,
+		Down: `
+			DROP INDEX idx_issues_milestone;
+			DROP INDEX idx_issues_project;
+			ALTER TABLE issues DROP COLUMN milestone_id;
+			ALTER TABLE issues DROP COLUMN project_id;
+			DROP TABLE milestones;
+			DROP TABLE projects;
+		`,
+	},
+	{
+		Version: 8,
+		Name:    "issue start and due dates",
+		Up: `
+			ALTER TABLE issues ADD COLUMN start_date DATETIME;
+			ALTER TABLE issues ADD COLUMN due_date DATETIME;
+
+			CREATE INDEX idx_issues_due_date ON issues(due_date);
+		`,
+		Down: `
+			DROP INDEX idx_issues_due_date;
+			ALTER TABLE issues DROP COLUMN due_date;
+			ALTER TABLE issues DROP COLUMN start_date;
+		`,
+	},
+	{
+		Version: 9,
+		Name:    "cycle capacity",
+		Up: `
+			CREATE TABLE cycle_capacities (
+				cycle_id TEXT NOT NULL,
+				member_id TEXT NOT NULL,
+				points INTEGER NOT NULL DEFAULT 0,
+				days_off INTEGER NOT NULL DEFAULT 0,
+				updated_at DATETIME NOT NULL,
+				PRIMARY KEY (cycle_id, member_id)
+			);
+		`,
+		Down: `
+			DROP TABLE cycle_capacities;
+		`,
+	},
+	{
+		// Existing references take the workspace of the issue or cycle they
+		// point at. User references take the workspace of an issue assigned
+		// to the user, or none; users are found again by email either way.
+		Version: 10,
+		Name:    "workspace external references",
+		Up: `
+			CREATE TABLE external_refs_scoped (
+				workspace_id TEXT NOT NULL,
+				source TEXT NOT NULL,
+				entity TEXT NOT NULL,
+				external_id TEXT NOT NULL,
+				entity_id TEXT NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (workspace_id, source, entity, external_id)
+			);
+
+			INSERT INTO external_refs_scoped (workspace_id, source, entity, external_id, entity_id, created_at)
+			SELECT COALESCE(CASE r.entity
+					WHEN 'issue' THEN (SELECT i.workspace_id FROM issues i WHERE i.id = r.entity_id)
+					WHEN 'cycle' THEN (SELECT c.workspace_id FROM cycles c WHERE c.id = r.entity_id)
+					ELSE (SELECT MIN(i.workspace_id) FROM issues i WHERE i.assignee_id = r.entity_id)
+				END, ''), r.source, r.entity, r.external_id, r.entity_id, r.created_at
+			FROM external_refs r;
+
+			DROP TABLE external_refs;
+			ALTER TABLE external_refs_scoped RENAME TO external_refs;
+			CREATE INDEX idx_external_refs_entity ON external_refs(entity, entity_id);
+		`,
+		Down: `
+			CREATE TABLE external_refs_unscoped (
+				source TEXT NOT NULL,
+				entity TEXT NOT NULL,
+				external_id TEXT NOT NULL,
+				entity_id TEXT NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (source, entity, external_id)
+			);
+
+			INSERT INTO external_refs_unscoped (source, entity, external_id, entity_id, created_at)
+			SELECT source, entity, external_id, MIN(entity_id), MIN(created_at)
+			FROM external_refs GROUP BY source, entity, external_id;
+
+			DROP TABLE external_refs;
+			ALTER TABLE external_refs_unscoped RENAME TO external_refs;
+			CREATE INDEX idx_external_refs_entity ON external_refs(entity, entity_id);
+		`,
+	},
+}
+
+// Migrations returns the migrations compiled into this binary.
+func Migrations() []Migration {
+	return migrations
+}
+
+// Migrate applies all pending migrations.
+func (db *DB) Migrate() error {
+	return db.MigrateUp(0)
+}
+
+// MigrateUp applies pending migrations up to and including target. A target
+// of zero applies every pending migration.
+func (db *DB) MigrateUp(target int) error {
+	applied, err := db.appliedMigrations()
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if target > 0 && m.Version > target {
+			break
+		}
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		if err := db.applyMigration(m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MigrateDownTo reverts applied migrations newer than target, most recent
+// first. It stops with an error at the first migration without a Down.
+func (db *DB) MigrateDownTo(target int) error {
+	applied, err := db.appliedMigrations()
+	if err != nil {
+		return err
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if m.Version <= target {
+			break
+		}
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		if m.Down == "" {
+			return fmt.Errorf("migration %d (%s) cannot be reverted", m.Version, m.Name)
+		}
+		if err := db.revertMigration(m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MigrationStatuses reports the state of every known migration.
+func (db *DB) MigrationStatuses() ([]MigrationStatus, error) {
+	if err := db.ensureMigrationsTable(); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT version, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	type record struct {
+		checksum  string
+		appliedAt time.Time
+	}
+	records := make(map[int]record)
+	for rows.Next() {
+		var version int
+		var rec record
+		if err := rows.Scan(&version, &rec.checksum, &rec.appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan migration: %w", err)
+		}
+		records[version] = rec
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		st := MigrationStatus{Version: m.Version, Name: m.Name}
+		if rec, ok := records[m.Version]; ok {
+			appliedAt := rec.appliedAt
+			st.Applied = true
+			st.AppliedAt = &appliedAt
+			st.Modified = rec.checksum != m.Checksum()
+		}
+		statuses = append(statuses, st)
+	}
+
+	return statuses, nil
+}
+
+func (db *DB) ensureMigrationsTable() error {
+	_, err := db.Exec(db.dialect.ddl(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			checksum TEXT NOT NULL,
+			applied_at DATETIME NOT NULL
+		)
+	`))
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	return nil
+}
+
+// appliedMigrations returns the checksums of applied migrations keyed by
+// version, verifying them against the migrations compiled into the binary.
+func (db *DB) appliedMigrations() (map[int]string, error) {
+	if err := db.ensureMigrationsTable(); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT version, checksum FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]string)
+	for rows.Next() {
+		var version int
+		var checksum string
+		if err := rows.Scan(&version, &checksum); err != nil {
+			return nil, fmt.Errorf("failed to scan migration: %w", err)
+		}
+		applied[version] = checksum
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+
+	known := make(map[int]Migration, len(migrations))
+	for _, m := range migrations {
+		known[m.Version] = m
+	}
+	for version, checksum := range applied {
+		m, ok := known[version]
+		if !ok {
+			return nil, fmt.Errorf("database has migration %d which this binary does not know; upgrade pulse", version)
+		}
+		if checksum != m.Checksum() {
+			return nil, fmt.Errorf("migration %d (%s) has been modified since it was applied", version, m.Name)
+		}
+	}
+
+	return applied, nil
+}
+
+func (db *DB) applyMigration(m Migration) error {
+	return db.InTx(func(tx *Tx) error {
+		if _, err := tx.Exec(db.dialect.ddl(m.Up)); err != nil {
+			return fmt.Errorf("failed to apply migration %d (%s): %w", m.Version, m.Name, err)
+		}
+		_, err := tx.Exec(
+			`INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)`,
+			m.Version, m.Name, m.Checksum(), time.Now(),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to record migration %d: %w", m.Version, err)
+		}
+		return nil
+	})
+}
+
+func (db *DB) revertMigration(m Migration) error {
+	return db.InTx(func(tx *Tx) error {
+		if _, err := tx.Exec(db.dialect.ddl(m.Down)); err != nil {
+			return fmt.Errorf("failed to revert migration %d (%s): %w", m.Version, m.Name, err)
+		}
+		if _, err := tx.Exec(`DELETE FROM schema_migrations WHERE version = ?`, m.Version); err != nil {
+			return fmt.Errorf("failed to unrecord migration %d: %w", m.Version, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,410 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidProject is returned when an issue's project or milestone does
+// not exist, is in another workspace, or the milestone belongs to a
+// different project.
+var ErrInvalidProject = errors.New("invalid project")
+
+// ProjectStatuses are the lifecycle states of a project.
+var ProjectStatuses = []string{"planned", "in_progress", "paused", "completed", "canceled"}
+
+// ValidProjectStatus reports whether s is a project lifecycle state.
+func ValidProjectStatus(s string) bool {
+	for _, status := range ProjectStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Project groups issues that deliver one piece of work, typically spanning
+// several cycles.
+type Project struct {
+	ID          string     `json:"id"`
+	WorkspaceID string     `json:"workspace_id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	LeadID      string     `json:"lead_id"`
+	Status      string     `json:"status"`
+	TargetDate  *time.Time `json:"target_date"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Milestone is a dated checkpoint within a project.
+type Milestone struct {
+	ID          string     `json:"id"`
+	ProjectID   string     `json:"project_id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	TargetDate  *time.Time `json:"target_date"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// projectColumns lists the project columns in the order scanProject reads
+// them.
+const projectColumns = `id, workspace_id, name, description, lead_id, status, target_date, created_at, updated_at`
+
+func scanProject(row rowScanner) (*Project, error) {
+	var p Project
+	var targetDate sql.NullTime
+
+	err := row.Scan(
+		&p.ID,
+		&p.WorkspaceID,
+		&p.Name,
+		&p.Description,
+		&p.LeadID,
+		&p.Status,
+		&targetDate,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if targetDate.Valid {
+		p.TargetDate = &targetDate.Time
+	}
+	return &p, nil
+}
+
+// milestoneColumns lists the milestone columns in the order scanMilestone
+// reads them.
+const milestoneColumns = `id, project_id, name, description, target_date, created_at`
+
+func scanMilestone(row rowScanner) (*Milestone, error) {
+	var m Milestone
+	var targetDate sql.NullTime
+
+	err := row.Scan(
+		&m.ID,
+		&m.ProjectID,
+		&m.Name,
+		&m.Description,
+		&targetDate,
+		&m.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if targetDate.Valid {
+		m.TargetDate = &targetDate.Time
+	}
+	return &m, nil
+}
+
+// ProjectRepository handles project database operations.
+type ProjectRepository struct {
+	db Querier
+}
+
+// NewProjectRepository creates a new project repository. Pass a *Tx to run
+// its operations inside a transaction.
+func NewProjectRepository(db Querier) *ProjectRepository {
+	return &ProjectRepository{db: db}
+}
+
+// Create inserts a new project.
+func (r *ProjectRepository) Create(p *Project) error {
+	now := time.Now()
+	p.CreatedAt = now
+	p.UpdatedAt = now
+	return r.Insert(p)
+}
+
+// Insert stores a project exactly as given, preserving its timestamps.
+func (r *ProjectRepository) Insert(p *Project) error {
+	if p.Status == "" {
+		p.Status = "planned"
+	}
+
+	_, err := r.db.Exec(`
+		INSERT INTO projects (`+projectColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		p.ID,
+		p.WorkspaceID,
+		p.Name,
+		p.Description,
+		p.LeadID,
+		p.Status,
+		p.TargetDate,
+		p.CreatedAt,
+		p.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create project: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a project by ID.
+func (r *ProjectRepository) GetByID(id string) (*Project, error) {
+	p, err := scanProject(r.db.QueryRow(`SELECT `+projectColumns+` FROM projects WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	return p, nil
+}
+
+// List retrieves all projects in a workspace, soonest target date first.
+func (r *ProjectRepository) List(workspaceID string) ([]*Project, error) {
+	rows, err := r.db.Query(`
+		SELECT `+projectColumns+` FROM projects WHERE workspace_id = ?
+		ORDER BY CASE WHEN target_date IS NULL THEN 1 ELSE 0 END, target_date ASC, created_at ASC
+	`, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+	defer rows.Close()
+
+	var projects []*Project
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan project: %w", err)
+		}
+		projects = append(projects, p)
+	}
+
+	return projects, rows.Err()
+}
+
+// Update updates an existing project.
+func (r *ProjectRepository) Update(p *Project) error {
+	p.UpdatedAt = time.Now()
+
+	_, err := r.db.Exec(`
+		UPDATE projects SET
+			name = ?,
+			description = ?,
+			lead_id = ?,
+			status = ?,
+			target_date = ?,
+			updated_at = ?
+		WHERE id = ?
+	`,
+		p.Name,
+		p.Description,
+		p.LeadID,
+		p.Status,
+		p.TargetDate,
+		p.UpdatedAt,
+		p.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update project: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a project and its milestones. Its issues are kept but no
+// longer belong to a project.
+func (r *ProjectRepository) Delete(id string) error {
+	return inTx(r.db, func(tx *Tx) error {
+		issueRepo := NewIssueRepository(tx)
+		issues, err := issueRepo.ListByProject(id)
+		if err != nil {
+			return err
+		}
+		for _, issue := range issues {
+			issue.ProjectID = ""
+			issue.MilestoneID = ""
+			if err := issueRepo.update(tx, issue); err != nil {
+				return fmt.Errorf("failed to detach %s: %w", issue.ID, err)
+			}
+		}
+
+		if _, err := tx.Exec(`DELETE FROM milestones WHERE project_id = ?`, id); err != nil {
+			return fmt.Errorf("failed to delete project milestones: %w", err)
+		}
+		if _, err := tx.Exec(`DELETE FROM projects WHERE id = ?`, id); err != nil {
+			return fmt.Errorf("failed to delete project: %w", err)
+		}
+		return nil
+	})
+}
+
+// MilestoneRepository handles milestone database operations.
+type MilestoneRepository struct {
+	db Querier
+}
+
+// NewMilestoneRepository creates a new milestone repository. Pass a *Tx to
+// run its operations inside a transaction.
+func NewMilestoneRepository(db Querier) *MilestoneRepository {
+	return &MilestoneRepository{db: db}
+}
+
+// Create inserts a new milestone.
+func (r *MilestoneRepository) Create(m *Milestone) error {
+	m.CreatedAt = time.Now()
+	return r.Insert(m)
+}
+
+// Insert stores a milestone exactly as given, preserving its timestamp.
+func (r *MilestoneRepository) Insert(m *Milestone) error {
+	_, err := r.db.Exec(`
+		INSERT INTO milestones (`+milestoneColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`,
+		m.ID,
+		m.ProjectID,
+		m.Name,
+		m.Description,
+		m.TargetDate,
+		m.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create milestone: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a milestone by ID.
+func (r *MilestoneRepository) GetByID(id string) (*Milestone, error) {
+	m, err := scanMilestone(r.db.QueryRow(`SELECT `+milestoneColumns+` FROM milestones WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get milestone: %w", err)
+	}
+
+	return m, nil
+}
+
+// ListByProject retrieves a project's milestones, soonest target date
+// first.
+func (r *MilestoneRepository) ListByProject(projectID string) ([]*Milestone, error) {
+	return r.list(`
+		SELECT `+milestoneColumns+` FROM milestones WHERE project_id = ?
+		ORDER BY CASE WHEN target_date IS NULL THEN 1 ELSE 0 END, target_date ASC, created_at ASC
+	`, projectID)
+}
+
+// ListByWorkspace retrieves the milestones of every project in a workspace.
+func (r *MilestoneRepository) ListByWorkspace(workspaceID string) ([]*Milestone, error) {
+	return r.list(`
+		SELECT `+milestoneColumns+` FROM milestones
+		WHERE project_id IN (SELECT id FROM projects WHERE workspace_id = ?)
+		ORDER BY created_at ASC
+	`, workspaceID)
+}
+
+func (r *MilestoneRepository) list(query string, args ...interface{}) ([]*Milestone, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list milestones: %w", err)
+	}
+	defer rows.Close()
+
+	var milestones []*Milestone
+	for rows.Next() {
+		m, err := scanMilestone(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan milestone: %w", err)
+		}
+		milestones = append(milestones, m)
+	}
+
+	return milestones, rows.Err()
+}
+
+// Update updates an existing milestone. A milestone cannot move between
+// projects.
+func (r *MilestoneRepository) Update(m *Milestone) error {
+	_, err := r.db.Exec(`
+		UPDATE milestones SET
+			name = ?,
+			description = ?,
+			target_date = ?
+		WHERE id = ?
+	`,
+		m.Name,
+		m.Description,
+		m.TargetDate,
+		m.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update milestone: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a milestone. Its issues stay in the project.
+func (r *MilestoneRepository) Delete(id string) error {
+	return inTx(r.db, func(tx *Tx) error {
+		issueRepo := NewIssueRepository(tx)
+		issues, err := issueRepo.ListByMilestone(id)
+		if err != nil {
+			return err
+		}
+		for _, issue := range issues {
+			issue.MilestoneID = ""
+			if err := issueRepo.update(tx, issue); err != nil {
+				return fmt.Errorf("failed to detach %s: %w", issue.ID, err)
+			}
+		}
+
+		if _, err := tx.Exec(`DELETE FROM milestones WHERE id = ?`, id); err != nil {
+			return fmt.Errorf("failed to delete milestone: %w", err)
+		}
+		return nil
+	})
+}
+
+// checkProject verifies that issue.ProjectID names a project in the
+// issue's workspace and that issue.MilestoneID belongs to that project. An
+// issue given only a milestone joins the milestone's project.
+func checkProject(tx *Tx, issue *Issue) error {
+	if issue.MilestoneID != "" {
+		m, err := NewMilestoneRepository(tx).GetByID(issue.MilestoneID)
+		if err != nil {
+			return err
+		}
+		if m == nil {
+			return fmt.Errorf("%w: milestone %s not found", ErrInvalidProject, issue.MilestoneID)
+		}
+		if issue.ProjectID == "" {
+			issue.ProjectID = m.ProjectID
+		} else if m.ProjectID != issue.ProjectID {
+			return fmt.Errorf("%w: milestone %s belongs to another project", ErrInvalidProject, m.ID)
+		}
+	}
+
+	if issue.ProjectID == "" {
+		return nil
+	}
+
+	p, err := NewProjectRepository(tx).GetByID(issue.ProjectID)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return fmt.Errorf("%w: project %s not found", ErrInvalidProject, issue.ProjectID)
+	}
+	if p.WorkspaceID != issue.WorkspaceID {
+		return fmt.Errorf("%w: project %s is in another workspace", ErrInvalidProject, p.ID)
+	}
+	return nil
+}
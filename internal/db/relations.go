@@ -0,0 +1,246 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Relation types between issues. A "blocks" relation from A to B means A
+// must be completed before B; "blocked by" is the same row seen from B.
+const (
+	RelationBlocks    = "blocks"
+	RelationDuplicate = "duplicate"
+	RelationRelates   = "relates"
+)
+
+// ErrInvalidRelation is returned when a relation links an issue to itself
+// or to an issue in another workspace, or when a blocking relation would
+// make the dependencies circular.
+var ErrInvalidRelation = errors.New("invalid relation")
+
+// Relation links two issues.
+type Relation struct {
+	ID             string    `json:"id"`
+	IssueID        string    `json:"issue_id"`
+	RelatedIssueID string    `json:"related_issue_id"`
+	Type           string    `json:"type"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// ValidRelationType reports whether t is a known relation type.
+func ValidRelationType(t string) bool {
+	switch t {
+	case RelationBlocks, RelationDuplicate, RelationRelates:
+		return true
+	}
+	return false
+}
+
+// relationColumns lists the relation columns in the order scanRelation
+// reads them.
+const relationColumns = `id, issue_id, related_issue_id, type, created_at`
+
+// scanRelation reads a relation selected with relationColumns.
+func scanRelation(row rowScanner) (*Relation, error) {
+	var rel Relation
+	err := row.Scan(
+		&rel.ID,
+		&rel.IssueID,
+		&rel.RelatedIssueID,
+		&rel.Type,
+		&rel.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rel, nil
+}
+
+// RelationRepository handles issue relation database operations.
+type RelationRepository struct {
+	db Querier
+}
+
+// NewRelationRepository creates a new relation repository. Pass a *Tx to
+// run its operations inside a transaction.
+func NewRelationRepository(db Querier) *RelationRepository {
+	return &RelationRepository{db: db}
+}
+
+// Create inserts a new relation after checking that it is valid.
+func (r *RelationRepository) Create(rel *Relation) error {
+	rel.CreatedAt = time.Now()
+	return inTx(r.db, func(tx *Tx) error {
+		if err := checkRelation(tx, rel); err != nil {
+			return err
+		}
+		return NewRelationRepository(tx).Insert(rel)
+	})
+}
+
+// Insert stores a relation exactly as given, preserving its timestamp.
+func (r *RelationRepository) Insert(rel *Relation) error {
+	query := `
+		INSERT INTO issue_relations (id, issue_id, related_issue_id, type, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.Exec(query,
+		rel.ID,
+		rel.IssueID,
+		rel.RelatedIssueID,
+		rel.Type,
+		rel.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create relation: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a relation by ID.
+func (r *RelationRepository) GetByID(id string) (*Relation, error) {
+	rel, err := scanRelation(r.db.QueryRow(`SELECT `+relationColumns+` FROM issue_relations WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get relation: %w", err)
+	}
+
+	return rel, nil
+}
+
+// ListByIssue retrieves relations in which the issue takes part on either
+// side.
+func (r *RelationRepository) ListByIssue(issueID string) ([]*Relation, error) {
+	query := `
+		SELECT ` + relationColumns + ` FROM issue_relations
+		WHERE issue_id = ? OR related_issue_id = ?
+		ORDER BY created_at ASC
+	`
+	return r.list(query, issueID, issueID)
+}
+
+// ListByWorkspace retrieves every relation between issues in a workspace.
+func (r *RelationRepository) ListByWorkspace(workspaceID string) ([]*Relation, error) {
+	query := `
+		SELECT ` + relationColumns + ` FROM issue_relations
+		WHERE issue_id IN (SELECT id FROM issues WHERE workspace_id = ?)
+		ORDER BY created_at ASC
+	`
+	return r.list(query, workspaceID)
+}
+
+func (r *RelationRepository) list(query string, args ...interface{}) ([]*Relation, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list relations: %w", err)
+	}
+	defer rows.Close()
+
+	var relations []*Relation
+	for rows.Next() {
+		rel, err := scanRelation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan relation: %w", err)
+		}
+		relations = append(relations, rel)
+	}
+
+	return relations, rows.Err()
+}
+
+// Delete removes a relation by ID.
+func (r *RelationRepository) Delete(id string) error {
+	query := `DELETE FROM issue_relations WHERE id = ?`
+
+	_, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete relation: %w", err)
+	}
+
+	return nil
+}
+
+// checkRelation verifies that rel links two distinct issues in the same
+// workspace and, for blocking relations, that the related issue does not
+// already block the issue directly or transitively.
+func checkRelation(tx *Tx, rel *Relation) error {
+	if !ValidRelationType(rel.Type) {
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidRelation, rel.Type)
+	}
+	if rel.IssueID == rel.RelatedIssueID {
+		return fmt.Errorf("%w: an issue cannot be related to itself", ErrInvalidRelation)
+	}
+
+	var workspaces []string
+	rows, err := tx.Query(`SELECT workspace_id FROM issues WHERE id IN (?, ?)`, rel.IssueID, rel.RelatedIssueID)
+	if err != nil {
+		return fmt.Errorf("failed to check relation: %w", err)
+	}
+	for rows.Next() {
+		var ws string
+		if err := rows.Scan(&ws); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to check relation: %w", err)
+		}
+		workspaces = append(workspaces, ws)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(workspaces) != 2 {
+		return fmt.Errorf("%w: issue not found", ErrInvalidRelation)
+	}
+	if workspaces[0] != workspaces[1] {
+		return fmt.Errorf("%w: issues are in different workspaces", ErrInvalidRelation)
+	}
+
+	if rel.Type != RelationBlocks {
+		return nil
+	}
+
+	// Walk everything the related issue blocks; reaching the issue means
+	// the new edge would close a loop.
+	queue := []string{rel.RelatedIssueID}
+	seen := map[string]bool{rel.RelatedIssueID: true}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		rows, err := tx.Query(`SELECT related_issue_id FROM issue_relations WHERE issue_id = ? AND type = ?`, current, RelationBlocks)
+		if err != nil {
+			return fmt.Errorf("failed to check relation: %w", err)
+		}
+		var next []string
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to check relation: %w", err)
+			}
+			next = append(next, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, id := range next {
+			if id == rel.IssueID {
+				return fmt.Errorf("%w: %s already depends on %s", ErrInvalidRelation, rel.IssueID, rel.RelatedIssueID)
+			}
+			if !seen[id] {
+				seen[id] = true
+				queue = append(queue, id)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package db
+
+import "time"
+
+// WorkspaceStore persists workspaces.
+type WorkspaceStore interface {
+	Create(ws *Workspace) error
+	GetByID(id string) (*Workspace, error)
+	List() ([]*Workspace, error)
+	Update(ws *Workspace) error
+	Delete(id string) error
+}
+
+// IssueStore persists issues.
+type IssueStore interface {
+	Create(issue *Issue) error
+	GetByID(id string) (*Issue, error)
+	GetByNumber(workspaceID string, number int) (*Issue, error)
+	List(workspaceID, status string, limit, offset int) ([]*Issue, error)
+	ListByCycle(cycleID string) ([]*Issue, error)
+	ListByProject(projectID string) ([]*Issue, error)
+	ListByMilestone(milestoneID string) ([]*Issue, error)
+	ListChildren(parentID string) ([]*Issue, error)
+	Update(issue *Issue) error
+	UpdateStatus(id, status string) error
+	Delete(id string) error
+	DeleteTree(id string) ([]string, error)
+	BulkUpdate(workspaceID string, ids []string, change *IssueChange) ([]*BulkResult, error)
+	CountByStatus(workspaceID string) (map[string]int, error)
+	CountByCycle(workspaceID, cycleID string) (total, completed int, err error)
+}
+
+// CycleStore persists cycles.
+type CycleStore interface {
+	Create(cycle *Cycle) error
+	GetByID(id string) (*Cycle, error)
+	List(workspaceID string) ([]*Cycle, error)
+	Update(cycle *Cycle) error
+	Delete(id string) error
+	GetActive(workspaceID string) (*Cycle, error)
+	GetUpcoming(workspaceID string) ([]*Cycle, error)
+}
+
+// CommentStore persists issue comments.
+type CommentStore interface {
+	Create(c *Comment) error
+	GetByID(id string) (*Comment, error)
+	ListByIssue(issueID string) ([]*Comment, error)
+	ListByWorkspace(workspaceID string) ([]*Comment, error)
+	Delete(id string) error
+}
+
+// RelationStore persists relations between issues.
+type RelationStore interface {
+	Create(rel *Relation) error
+	GetByID(id string) (*Relation, error)
+	ListByIssue(issueID string) ([]*Relation, error)
+	ListByWorkspace(workspaceID string) ([]*Relation, error)
+	Delete(id string) error
+}
+
+// ProjectStore persists projects.
+type ProjectStore interface {
+	Create(p *Project) error
+	GetByID(id string) (*Project, error)
+	List(workspaceID string) ([]*Project, error)
+	Update(p *Project) error
+	Delete(id string) error
+}
+
+// MilestoneStore persists project milestones.
+type MilestoneStore interface {
+	Create(m *Milestone) error
+	GetByID(id string) (*Milestone, error)
+	ListByProject(projectID string) ([]*Milestone, error)
+	ListByWorkspace(workspaceID string) ([]*Milestone, error)
+	Update(m *Milestone) error
+	Delete(id string) error
+}
+
+// CapacityStore persists member capacity per cycle.
+type CapacityStore interface {
+	Set(c *Capacity) error
+	ListByCycle(cycleID string) ([]*Capacity, error)
+	ListByWorkspace(workspaceID string) ([]*Capacity, error)
+	Delete(cycleID, memberID string) error
+}
+
+// MetricsStore aggregates delivery metrics.
+type MetricsStore interface {
+	Totals(workspaceID, bugLabel string) (*Totals, error)
+	CountByStatus() ([]StatusCount, error)
+	Breakdown(workspaceID, groupBy string, since time.Time, cycles int) ([]*BreakdownRow, error)
+	WIP(workspaceID string) (*WIPReport, error)
+	WIPViolations(issue *Issue) ([]WIPUsage, error)
+}
+
+// The repositories implement the stores for both SQLite and PostgreSQL;
+// queries are written with ? placeholders and rebound by DB per dialect.
+var (
+	_ WorkspaceStore = (*WorkspaceRepository)(nil)
+	_ IssueStore     = (*IssueRepository)(nil)
+	_ CycleStore     = (*CycleRepository)(nil)
+	_ CommentStore   = (*CommentRepository)(nil)
+	_ RelationStore  = (*RelationRepository)(nil)
+	_ ProjectStore   = (*ProjectRepository)(nil)
+	_ MilestoneStore = (*MilestoneRepository)(nil)
+	_ CapacityStore  = (*CapacityRepository)(nil)
+	_ MetricsStore   = (*MetricsRepository)(nil)
+)
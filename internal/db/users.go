@@ -0,0 +1,107 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// User is a person who can be assigned issues.
+type User struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	AvatarURL string    `json:"avatar_url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// userColumns lists the user columns in the order scanUser reads them.
+const userColumns = `id, email, name, avatar_url, created_at`
+
+// scanUser reads a user selected with userColumns.
+func scanUser(row rowScanner) (*User, error) {
+	var u User
+	var name, avatarURL sql.NullString
+	err := row.Scan(
+		&u.ID,
+		&u.Email,
+		&name,
+		&avatarURL,
+		&u.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	u.Name = name.String
+	u.AvatarURL = avatarURL.String
+	return &u, nil
+}
+
+// UserRepository handles user database operations.
+type UserRepository struct {
+	db Querier
+}
+
+// NewUserRepository creates a new user repository. Pass a *Tx to run its
+// operations inside a transaction.
+func NewUserRepository(db Querier) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// Create inserts a new user.
+func (r *UserRepository) Create(u *User) error {
+	u.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO users (id, email, name, avatar_url, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.Exec(query, u.ID, u.Email, u.Name, u.AvatarURL, u.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a user by ID.
+func (r *UserRepository) GetByID(id string) (*User, error) {
+	return r.get(`SELECT `+userColumns+` FROM users WHERE id = ?`, id)
+}
+
+// GetByEmail retrieves a user by email address.
+func (r *UserRepository) GetByEmail(email string) (*User, error) {
+	return r.get(`SELECT `+userColumns+` FROM users WHERE email = ?`, email)
+}
+
+func (r *UserRepository) get(query string, arg string) (*User, error) {
+	u, err := scanUser(r.db.QueryRow(query, arg))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return u, nil
+}
+
+// List retrieves all users.
+func (r *UserRepository) List() ([]*User, error) {
+	rows, err := r.db.Query(`SELECT ` + userColumns + ` FROM users ORDER BY name ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, u)
+	}
+
+	return users, rows.Err()
+}
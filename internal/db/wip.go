@@ -0,0 +1,183 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrWIPLimit is returned when a change would take a workflow state or an
+// assignee past an enforced WIP limit.
+var ErrWIPLimit = errors.New("WIP limit exceeded")
+
+// WIP limit scopes.
+const (
+	WIPScopeStatus   = "status"
+	WIPScopeAssignee = "assignee"
+)
+
+// WIPUsage is the work in one workflow state, or in progress for one
+// assignee, against its limit. A zero Limit means none is set.
+type WIPUsage struct {
+	Scope string `json:"scope"`
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+	Limit int    `json:"limit"`
+}
+
+// Exceeded reports whether the usage is over its limit.
+func (u WIPUsage) Exceeded() bool {
+	return u.Limit > 0 && u.Count > u.Limit
+}
+
+func (u WIPUsage) String() string {
+	return fmt.Sprintf("%s %s has %d issues (limit %d)", u.Scope, u.Key, u.Count, u.Limit)
+}
+
+// WIPReport is a workspace's work in progress against its limits.
+type WIPReport struct {
+	Enforced   bool       `json:"enforced"`
+	Statuses   []WIPUsage `json:"statuses"`
+	Assignees  []WIPUsage `json:"assignees"`
+	Violations []WIPUsage `json:"violations"`
+}
+
+// WIP counts the issues in each workflow state and in progress for each
+// assignee of a workspace, against the workspace's limits.
+func (r *MetricsRepository) WIP(workspaceID string) (*WIPReport, error) {
+	settings, err := wipSettings(r.db, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	report := &WIPReport{
+		Enforced:   settings.EnforceWIPLimits,
+		Statuses:   []WIPUsage{},
+		Assignees:  []WIPUsage{},
+		Violations: []WIPUsage{},
+	}
+
+	counts := make(map[string]int)
+	err = r.scan(`SELECT status, COUNT(*) FROM issues i WHERE workspace_id = ? GROUP BY status`,
+		[]interface{}{workspaceID},
+		func(k string, vals []float64) { counts[k] = int(vals[0]) }, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count issues: %w", err)
+	}
+	for _, status := range Statuses {
+		report.Statuses = append(report.Statuses, WIPUsage{
+			Scope: WIPScopeStatus,
+			Key:   status,
+			Count: counts[status],
+			Limit: settings.WIPLimits[status],
+		})
+	}
+
+	err = r.scan(`
+		SELECT assignee_id, COUNT(*) FROM issues i
+		WHERE workspace_id = ? AND status = 'in_progress' AND assignee_id IS NOT NULL AND assignee_id != ''
+		GROUP BY assignee_id`,
+		[]interface{}{workspaceID},
+		func(k string, vals []float64) {
+			report.Assignees = append(report.Assignees, WIPUsage{
+				Scope: WIPScopeAssignee,
+				Key:   k,
+				Count: int(vals[0]),
+				Limit: settings.AssigneeWIPLimit,
+			})
+		}, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count assignee work: %w", err)
+	}
+	sort.Slice(report.Assignees, func(i, j int) bool { return report.Assignees[i].Key < report.Assignees[j].Key })
+
+	for _, u := range append(report.Statuses, report.Assignees...) {
+		if u.Exceeded() {
+			report.Violations = append(report.Violations, u)
+		}
+	}
+	return report, nil
+}
+
+// WIPViolations returns the limits an issue's workflow state and assignee
+// are over, counting the issue as it is given rather than as stored.
+func (r *MetricsRepository) WIPViolations(issue *Issue) ([]WIPUsage, error) {
+	settings, err := wipSettings(r.db, issue.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	return wipViolations(r.db, settings, issue)
+}
+
+// checkWIP refuses a change that would move an issue into a workflow
+// state or onto an assignee past an enforced limit.
+func checkWIP(q Querier, before, after *Issue) error {
+	if after.Status == before.Status && after.AssigneeID == before.AssigneeID {
+		return nil
+	}
+	settings, err := wipSettings(q, after.WorkspaceID)
+	if err != nil || !settings.EnforceWIPLimits {
+		return err
+	}
+
+	violations, err := wipViolations(q, settings, after)
+	if err != nil {
+		return err
+	}
+	for _, v := range violations {
+		// A state already over its limit does not block reassigning
+		// the issues in it.
+		if v.Scope == WIPScopeStatus && after.Status == before.Status {
+			continue
+		}
+		return fmt.Errorf("%w: %s", ErrWIPLimit, v)
+	}
+	return nil
+}
+
+// wipViolations counts the other issues sharing the issue's workflow state
+// and assignee, plus the issue itself, against their limits.
+func wipViolations(q Querier, settings *WorkspaceSettings, issue *Issue) ([]WIPUsage, error) {
+	var violations []WIPUsage
+
+	if limit := settings.WIPLimits[issue.Status]; limit > 0 {
+		u := WIPUsage{Scope: WIPScopeStatus, Key: issue.Status, Limit: limit}
+		err := q.QueryRow(`SELECT COUNT(*) + 1 FROM issues WHERE workspace_id = ? AND status = ? AND id != ?`,
+			issue.WorkspaceID, issue.Status, issue.ID).Scan(&u.Count)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count issues: %w", err)
+		}
+		if u.Exceeded() {
+			violations = append(violations, u)
+		}
+	}
+
+	if limit := settings.AssigneeWIPLimit; limit > 0 && issue.AssigneeID != "" && issue.Status == "in_progress" {
+		u := WIPUsage{Scope: WIPScopeAssignee, Key: issue.AssigneeID, Limit: limit}
+		err := q.QueryRow(`SELECT COUNT(*) + 1 FROM issues WHERE workspace_id = ? AND assignee_id = ? AND status = 'in_progress' AND id != ?`,
+			issue.WorkspaceID, issue.AssigneeID, issue.ID).Scan(&u.Count)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count assignee work: %w", err)
+		}
+		if u.Exceeded() {
+			violations = append(violations, u)
+		}
+	}
+
+	return violations, nil
+}
+
+// wipSettings reads a workspace's settings for its WIP limits. Settings
+// that do not parse set no limits.
+func wipSettings(q Querier, workspaceID string) (*WorkspaceSettings, error) {
+	var raw sql.NullString
+	err := q.QueryRow(`SELECT settings FROM workspaces WHERE id = ?`, workspaceID).Scan(&raw)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get workspace settings: %w", err)
+	}
+	settings, err := parseSettings(raw.String)
+	if err != nil {
+		return &WorkspaceSettings{}, nil
+	}
+	return settings, nil
+}
@@ -2,7 +2,9 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -16,13 +18,132 @@ type Workspace struct {
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+// WorkspaceSettings is the configuration stored as JSON in
+// Workspace.Settings. Zero values mean the default.
+type WorkspaceSettings struct {
+	DefaultAssignee string `json:"defaultAssignee,omitempty"`
+	AutoCloseDays   int    `json:"autoCloseDays,omitempty"`
+	RequireEstimate bool   `json:"requireEstimate,omitempty"`
+	RequireLabels   bool   `json:"requireLabels,omitempty"`
+	CycleDuration   int    `json:"cycleDuration,omitempty"` // weeks
+	IssuePrefix     string `json:"issuePrefix,omitempty"`
+	// SLAHours is the time allowed to resolve an issue, by priority name,
+	// e.g. {"urgent": 48}.
+	SLAHours map[string]int `json:"slaHours,omitempty"`
+	// BugLabel marks an issue as a bug, and EscapedLabel a bug found in
+	// production.
+	BugLabel     string `json:"bugLabel,omitempty"`
+	EscapedLabel string `json:"escapedLabel,omitempty"`
+	// PointsPerDay converts a member's available days in a cycle into
+	// points; Holidays (YYYY-MM-DD) are not working days.
+	PointsPerDay float64  `json:"pointsPerDay,omitempty"`
+	Holidays     []string `json:"holidays,omitempty"`
+	// WIPLimits caps the issues in a workflow state, e.g.
+	// {"in_progress": 5}, and AssigneeWIPLimit the in-progress issues of
+	// each assignee. Moves past a limit are refused when EnforceWIPLimits
+	// is set and only reported otherwise.
+	WIPLimits        map[string]int `json:"wipLimits,omitempty"`
+	AssigneeWIPLimit int            `json:"assigneeWipLimit,omitempty"`
+	EnforceWIPLimits bool           `json:"enforceWipLimits,omitempty"`
+}
+
+// DefaultIssuePrefix is used for issue keys when a workspace sets none.
+const DefaultIssuePrefix = "PUL"
+
+// Labels used for quality metrics when a workspace sets none.
+const (
+	DefaultBugLabel     = "bug"
+	DefaultEscapedLabel = "escaped"
+)
+
+// ParseSettings decodes the workspace settings. Empty settings yield the
+// defaults.
+func (ws *Workspace) ParseSettings() (*WorkspaceSettings, error) {
+	return parseSettings(ws.Settings)
+}
+
+func parseSettings(raw string) (*WorkspaceSettings, error) {
+	settings := &WorkspaceSettings{}
+	if strings.TrimSpace(raw) == "" {
+		return settings, nil
+	}
+	if err := json.Unmarshal([]byte(raw), settings); err != nil {
+		return nil, fmt.Errorf("invalid workspace settings: %w", err)
+	}
+	return settings, nil
+}
+
+// KeyPrefix returns the prefix of issue keys in this workspace.
+func (s *WorkspaceSettings) KeyPrefix() string {
+	if s.IssuePrefix == "" {
+		return DefaultIssuePrefix
+	}
+	return strings.ToUpper(s.IssuePrefix)
+}
+
+// BugLabelName returns the label that marks bugs in this workspace.
+func (s *WorkspaceSettings) BugLabelName() string {
+	if s.BugLabel == "" {
+		return DefaultBugLabel
+	}
+	return s.BugLabel
+}
+
+// EscapedLabelName returns the label that marks bugs found in production.
+func (s *WorkspaceSettings) EscapedLabelName() string {
+	if s.EscapedLabel == "" {
+		return DefaultEscapedLabel
+	}
+	return s.EscapedLabel
+}
+
+// DefaultPointsPerDay is the points a member completes per available day
+// when a workspace sets no rate.
+const DefaultPointsPerDay = 1
+
+// DailyPoints returns the points a member completes per available day.
+func (s *WorkspaceSettings) DailyPoints() float64 {
+	if s.PointsPerDay <= 0 {
+		return DefaultPointsPerDay
+	}
+	return s.PointsPerDay
+}
+
+// SLATarget returns the time allowed to resolve an issue of the given
+// priority, or zero when the workspace sets no target.
+func (s *WorkspaceSettings) SLATarget(priority int) time.Duration {
+	return time.Duration(s.SLAHours[PriorityName(priority)]) * time.Hour
+}
+
+// workspaceColumns lists the workspace columns in the order scanWorkspace
+// reads them.
+const workspaceColumns = `id, name, description, settings, created_at, updated_at`
+
+// scanWorkspace reads a workspace selected with workspaceColumns.
+func scanWorkspace(row rowScanner) (*Workspace, error) {
+	var ws Workspace
+	err := row.Scan(
+		&ws.ID,
+		&ws.Name,
+		&ws.Description,
+		&ws.Settings,
+		&ws.CreatedAt,
+		&ws.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &ws, nil
+}
+
 // WorkspaceRepository handles workspace database operations.
 type WorkspaceRepository struct {
-	db *DB
+	db Querier
 }
 
-// NewWorkspaceRepository creates a new workspace repository.
-func NewWorkspaceRepository(db *DB) *WorkspaceRepository {
+// NewWorkspaceRepository creates a new workspace repository. Pass a *Tx to run its
+// operations inside a transaction.
+func NewWorkspaceRepository(db Querier) *WorkspaceRepository {
 	return &WorkspaceRepository{db: db}
 }
 
@@ -32,6 +153,11 @@ func (r *WorkspaceRepository) Create(ws *Workspace) error {
 	ws.CreatedAt = now
 	ws.UpdatedAt = now
 
+	return r.Insert(ws)
+}
+
+// Insert stores a workspace exactly as given, preserving its timestamps.
+func (r *WorkspaceRepository) Insert(ws *Workspace) error {
 	query := `
 		INSERT INTO workspaces (id, name, description, settings, created_at, updated_at)
 		VALUES (?, ?, ?, ?, ?, ?)
@@ -54,17 +180,9 @@ func (r *WorkspaceRepository) Create(ws *Workspace) error {
 
 // GetByID retrieves a workspace by ID.
 func (r *WorkspaceRepository) GetByID(id string) (*Workspace, error) {
-	query := `SELECT * FROM workspaces WHERE id = ?`
+	query := `SELECT ` + workspaceColumns + ` FROM workspaces WHERE id = ?`
 
-	var ws Workspace
-	err := r.db.QueryRow(query, id).Scan(
-		&ws.ID,
-		&ws.Name,
-		&ws.Description,
-		&ws.Settings,
-		&ws.CreatedAt,
-		&ws.UpdatedAt,
-	)
+	ws, err := scanWorkspace(r.db.QueryRow(query, id))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -72,12 +190,12 @@ func (r *WorkspaceRepository) GetByID(id string) (*Workspace, error) {
 		return nil, fmt.Errorf("failed to get workspace: %w", err)
 	}
 
-	return &ws, nil
+	return ws, nil
 }
 
 // List retrieves all workspaces.
 func (r *WorkspaceRepository) List() ([]*Workspace, error) {
-	query := `SELECT * FROM workspaces ORDER BY created_at DESC`
+	query := `SELECT ` + workspaceColumns + ` FROM workspaces ORDER BY created_at DESC`
 
 	rows, err := r.db.Query(query)
 	if err != nil {
@@ -87,21 +205,14 @@ func (r *WorkspaceRepository) List() ([]*Workspace, error) {
 
 	var workspaces []*Workspace
 	for rows.Next() {
-		var ws Workspace
-		if err := rows.Scan(
-			&ws.ID,
-			&ws.Name,
-			&ws.Description,
-			&ws.Settings,
-			&ws.CreatedAt,
-			&ws.UpdatedAt,
-		); err != nil {
+		ws, err := scanWorkspace(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan workspace: %w", err)
 		}
-		workspaces = append(workspaces, &ws)
+		workspaces = append(workspaces, ws)
 	}
 
-	return workspaces, nil
+	return workspaces, rows.Err()
 }
 
 // Update updates an existing workspace.
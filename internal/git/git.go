@@ -0,0 +1,130 @@
+// Package git links issues to branches in the local repository: it reads
+// the current branch, creates branches for issues and installs the commit
+// message hook.
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// FindGitDir returns the git directory of the repository containing dir,
+// following the .git file used by worktrees and submodules.
+func FindGitDir(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		path := filepath.Join(dir, ".git")
+		info, err := os.Stat(path)
+		if err == nil {
+			if info.IsDir() {
+				return path, nil
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return "", err
+			}
+			gitDir := strings.TrimSpace(strings.TrimPrefix(string(data), "gitdir:"))
+			if !filepath.IsAbs(gitDir) {
+				gitDir = filepath.Join(dir, gitDir)
+			}
+			return gitDir, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("not inside a git repository")
+		}
+		dir = parent
+	}
+}
+
+// CurrentBranch returns the branch checked out in the repository
+// containing dir.
+func CurrentBranch(dir string) (string, error) {
+	gitDir, err := FindGitDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read HEAD: %w", err)
+	}
+
+	ref := strings.TrimSpace(string(head))
+	if !strings.HasPrefix(ref, "ref: refs/heads/") {
+		return "", fmt.Errorf("HEAD is detached; check out a branch first")
+	}
+	return strings.TrimPrefix(ref, "ref: refs/heads/"), nil
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// maxSlug bounds the title part of generated branch names.
+const maxSlug = 40
+
+// BranchName builds a conventional branch name for an issue, such as
+// fix/pul-42-login-fails-on-mobile for a bug and feat/pul-43-dark-mode
+// otherwise.
+func BranchName(key, title string, labels []string) string {
+	kind := "feat"
+	for _, l := range labels {
+		if strings.EqualFold(l, "bug") {
+			kind = "fix"
+			break
+		}
+	}
+
+	slug := strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if len(slug) > maxSlug {
+		slug = strings.TrimRight(slug[:maxSlug], "-")
+		if i := strings.LastIndexByte(slug, '-'); i > maxSlug/2 {
+			slug = slug[:i]
+		}
+	}
+
+	name := kind + "/" + strings.ToLower(key)
+	if slug != "" {
+		name += "-" + slug
+	}
+	return name
+}
+
+// Checkout switches the repository in dir to branch, creating it from the
+// current HEAD if it does not exist yet.
+func Checkout(dir, branch string) (created bool, err error) {
+	if err := run(dir, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch); err == nil {
+		return false, run(dir, "checkout", branch)
+	}
+	return true, run(dir, "checkout", "-b", branch)
+}
+
+func output(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+func run(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return nil
+}
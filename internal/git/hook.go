@@ -0,0 +1,97 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// hookMarker identifies hooks written by InstallHook.
+const hookMarker = "# Installed by pulse"
+
+// prepareCommitMsg adds an "Issue: PUL-42" trailer to commit messages on
+// branches named after an issue key, such as fix/pul-42-login. Merges,
+// squashes and amended commits are left alone.
+const prepareCommitMsg = `#!/bin/sh
+` + hookMarker + `: adds the Pulse issue key from the branch name.
+case "$2" in merge|squash|commit) exit 0 ;; esac
+
+branch=$(git symbolic-ref --short -q HEAD) || exit 0
+key=$(printf '%s\n' "${branch##*/}" | grep -oE '^[A-Za-z][A-Za-z0-9]*-[0-9]+' | tr '[:lower:]' '[:upper:]')
+[ -n "$key" ] || exit 0
+
+git interpret-trailers --in-place --if-exists addIfDifferent --trailer "Issue: $key" "$1"
+`
+
+// HookPath returns where the prepare-commit-msg hook lives for the
+// repository containing dir, honouring core.hooksPath.
+func HookPath(dir string) (string, error) {
+	gitDir, err := FindGitDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	hooksDir := filepath.Join(gitDir, "hooks")
+	// Worktrees share the hooks of the main repository.
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		common := strings.TrimSpace(string(data))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(gitDir, common)
+		}
+		hooksDir = filepath.Join(common, "hooks")
+	}
+	if out, err := output(dir, "config", "--get", "core.hooksPath"); err == nil && out != "" {
+		hooksDir = out
+		if !filepath.IsAbs(hooksDir) {
+			root, err := output(dir, "rev-parse", "--show-toplevel")
+			if err != nil {
+				return "", err
+			}
+			hooksDir = filepath.Join(root, hooksDir)
+		}
+	}
+
+	return filepath.Join(hooksDir, "prepare-commit-msg"), nil
+}
+
+// InstallHook writes the prepare-commit-msg hook. An existing hook not
+// written by Pulse is only replaced when force is set.
+func InstallHook(dir string, force bool) (string, error) {
+	path, err := HookPath(dir)
+	if err != nil {
+		return "", err
+	}
+
+	if existing, err := os.ReadFile(path); err == nil && !strings.Contains(string(existing), hookMarker) && !force {
+		return "", fmt.Errorf("%s already exists; use --force to replace it", path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(prepareCommitMsg), 0755); err != nil {
+		return "", fmt.Errorf("failed to write hook: %w", err)
+	}
+	return path, nil
+}
+
+// UninstallHook removes the hook if Pulse installed it.
+func UninstallHook(dir string) (string, error) {
+	path, err := HookPath(dir)
+	if err != nil {
+		return "", err
+	}
+
+	existing, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("no prepare-commit-msg hook installed")
+	}
+	if err != nil {
+		return "", err
+	}
+	if !strings.Contains(string(existing), hookMarker) {
+		return "", fmt.Errorf("%s was not installed by pulse; remove it by hand", path)
+	}
+	return path, os.Remove(path)
+}
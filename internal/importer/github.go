@@ -0,0 +1,136 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	register(githubSource{})
+}
+
+// githubSource reads issues as JSON, either from the REST API
+// (GET /repos/{owner}/{repo}/issues) or from
+// "gh issue list --json number,title,body,state,stateReason,labels,assignees,milestone,createdAt,updatedAt,closedAt".
+// Milestones become cycles.
+type githubSource struct{}
+
+func (githubSource) Name() string { return "github-json" }
+
+func (githubSource) DefaultStatusMap() map[string]string {
+	return map[string]string{
+		"open":        "todo",
+		"closed":      "done",
+		"completed":   "done",
+		"not_planned": "canceled",
+		"duplicate":   "canceled",
+		"reopened":    "todo",
+	}
+}
+
+type githubIssue struct {
+	Number      int     `json:"number"`
+	Title       string  `json:"title"`
+	Body        string  `json:"body"`
+	State       string  `json:"state"`
+	StateReason *string `json:"state_reason"`
+	// gh uses camelCase field names.
+	StateReasonCLI string `json:"stateReason"`
+	Labels         []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	Assignees []struct {
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	} `json:"assignees"`
+	Milestone *struct {
+		Title    string     `json:"title"`
+		DueOn    *time.Time `json:"due_on"`
+		DueOnCLI *time.Time `json:"dueOn"`
+	} `json:"milestone"`
+	Parent *struct {
+		Number int `json:"number"`
+	} `json:"parent"`
+	PullRequest  json.RawMessage `json:"pull_request"`
+	CreatedAt    time.Time       `json:"created_at"`
+	CreatedAtCLI time.Time       `json:"createdAt"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+	UpdatedAtCLI time.Time       `json:"updatedAt"`
+	ClosedAt     *time.Time      `json:"closed_at"`
+	ClosedAtCLI  *time.Time      `json:"closedAt"`
+}
+
+func (githubSource) Parse(r io.Reader) ([]*Record, error) {
+	var issues []githubIssue
+	if err := json.NewDecoder(r).Decode(&issues); err != nil {
+		return nil, fmt.Errorf("failed to decode github issues: %w", err)
+	}
+
+	records := make([]*Record, 0, len(issues))
+	for _, gi := range issues {
+		// The REST API lists pull requests alongside issues.
+		if len(gi.PullRequest) > 0 && string(gi.PullRequest) != "null" {
+			continue
+		}
+
+		rec := &Record{
+			ExternalID:  strconv.Itoa(gi.Number),
+			Title:       gi.Title,
+			Description: gi.Body,
+			Status:      strings.ToLower(gi.State),
+			CreatedAt:   firstTime(gi.CreatedAt, gi.CreatedAtCLI),
+			UpdatedAt:   firstTime(gi.UpdatedAt, gi.UpdatedAtCLI),
+			CompletedAt: gi.ClosedAt,
+		}
+		if rec.CompletedAt == nil {
+			rec.CompletedAt = gi.ClosedAtCLI
+		}
+
+		reason := gi.StateReasonCLI
+		if gi.StateReason != nil {
+			reason = *gi.StateReason
+		}
+		if rec.Status == "closed" && reason != "" {
+			rec.Status = strings.ToLower(reason)
+		}
+
+		for _, l := range gi.Labels {
+			rec.Labels = append(rec.Labels, l.Name)
+		}
+		if len(gi.Assignees) > 0 {
+			a := gi.Assignees[0]
+			rec.Assignee = &Person{
+				Handle: a.Login,
+				Name:   a.Name,
+				Email:  a.Login + "@users.noreply.github.com",
+			}
+		}
+		if gi.Milestone != nil && gi.Milestone.Title != "" {
+			due := gi.Milestone.DueOn
+			if due == nil {
+				due = gi.Milestone.DueOnCLI
+			}
+			rec.Sprint = &Sprint{Name: gi.Milestone.Title, EndDate: due}
+		}
+		if gi.Parent != nil && gi.Parent.Number > 0 {
+			rec.ParentExternalID = strconv.Itoa(gi.Parent.Number)
+		}
+
+		records = append(records, rec)
+	}
+
+	return records, nil
+}
+
+func firstTime(times ...time.Time) time.Time {
+	for _, t := range times {
+		if !t.IsZero() {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,472 @@
+// Package importer loads issues exported from other trackers into Pulse.
+//
+// Each source parses its export format into source-neutral Records. Run
+// then maps statuses through a configurable table, creates cycles from
+// sprints and users from assignees, and links sub-tasks to their parents.
+// Every imported entity is keyed on its external ID so that running the
+// same import again updates the existing issues instead of duplicating them.
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+// Record is one issue read from an external tracker.
+type Record struct {
+	ExternalID       string
+	ParentExternalID string
+	Title            string
+	Description      string
+	// Status is the source's own status name; it is mapped on import.
+	Status      string
+	Priority    int
+	Estimate    int
+	Labels      []string
+	Assignee    *Person
+	Sprint      *Sprint
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	CompletedAt *time.Time
+}
+
+// Person identifies an assignee in the source tracker.
+type Person struct {
+	// Handle is the source's stable user identifier (login, name or email).
+	Handle string
+	Email  string
+	Name   string
+}
+
+// Sprint is a time-boxed iteration in the source tracker.
+type Sprint struct {
+	Name      string
+	StartDate *time.Time
+	EndDate   *time.Time
+}
+
+// Source parses one export format.
+type Source interface {
+	// Name is the value accepted by --from.
+	Name() string
+	// DefaultStatusMap maps the source's status names to Pulse statuses.
+	DefaultStatusMap() map[string]string
+	// Parse reads an export file.
+	Parse(r io.Reader) ([]*Record, error)
+}
+
+var sources = map[string]Source{}
+
+func register(s Source) {
+	sources[s.Name()] = s
+}
+
+// Lookup returns the source registered under name.
+func Lookup(name string) (Source, error) {
+	s, ok := sources[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown import source %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return s, nil
+}
+
+// Names lists the registered sources.
+func Names() []string {
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Options controls an import run.
+type Options struct {
+	WorkspaceID string
+	// StatusMap overrides entries of the source's default status table.
+	// Keys are matched case-insensitively.
+	StatusMap map[string]string
+}
+
+// Result summarises an import run.
+type Result struct {
+	Created  int      `json:"created"`
+	Updated  int      `json:"updated"`
+	Cycles   int      `json:"cycles"`
+	Users    int      `json:"users"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// LoadStatusMap reads a JSON object of source status to Pulse status.
+func LoadStatusMap(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]string
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse status map %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// Run imports records from src into a workspace in a single transaction.
+func Run(database *db.DB, src Source, records []*Record, opts Options) (*Result, error) {
+	statusMap := make(map[string]string)
+	for k, v := range src.DefaultStatusMap() {
+		statusMap[strings.ToLower(k)] = v
+	}
+	for k, v := range opts.StatusMap {
+		if !db.ValidStatus(v) {
+			return nil, fmt.Errorf("status map: %q is not a Pulse status", v)
+		}
+		statusMap[strings.ToLower(k)] = v
+	}
+
+	result := &Result{}
+	unmapped := make(map[string]bool)
+
+	err := database.InTx(func(tx *db.Tx) error {
+		ws, err := db.NewWorkspaceRepository(tx).GetByID(opts.WorkspaceID)
+		if err != nil {
+			return err
+		}
+		if ws == nil {
+			return fmt.Errorf("workspace %s not found", opts.WorkspaceID)
+		}
+
+		run := &run{
+			source:  src.Name(),
+			opts:    opts,
+			result:  result,
+			refs:    db.NewExternalRefRepository(tx),
+			issues:  db.NewIssueRepository(tx),
+			cycles:  db.NewCycleRepository(tx),
+			users:   db.NewUserRepository(tx),
+			seq:     time.Now().UnixNano(),
+			issueID: make(map[string]string),
+		}
+
+		for _, rec := range records {
+			status, ok := statusMap[strings.ToLower(rec.Status)]
+			if !ok {
+				unmapped[rec.Status] = true
+				status = "backlog"
+			}
+			if err := run.importRecord(rec, status); err != nil {
+				return fmt.Errorf("%s: %w", rec.ExternalID, err)
+			}
+		}
+
+		// Link sub-tasks once every record has a Pulse ID.
+		for _, rec := range records {
+			if rec.ParentExternalID == "" {
+				continue
+			}
+			if err := run.linkParent(rec); err != nil {
+				return fmt.Errorf("%s: %w", rec.ExternalID, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("import failed: %w", err)
+	}
+
+	for status := range unmapped {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("status %q is not mapped; imported as backlog", status))
+	}
+	sort.Strings(result.Warnings)
+
+	return result, nil
+}
+
+// run holds the state of a single import transaction.
+type run struct {
+	source string
+	opts   Options
+	result *Result
+
+	refs   *db.ExternalRefRepository
+	issues *db.IssueRepository
+	cycles *db.CycleRepository
+	users  *db.UserRepository
+
+	seq     int64
+	issueID map[string]string
+}
+
+// newID returns an ID following the server's <prefix>_<nanos> convention.
+func (r *run) newID(prefix string) string {
+	r.seq++
+	return fmt.Sprintf("%s_%d", prefix, r.seq)
+}
+
+func (r *run) importRecord(rec *Record, status string) error {
+	cycleID, err := r.ensureCycle(rec.Sprint)
+	if err != nil {
+		return err
+	}
+	assigneeID, err := r.ensureUser(rec.Assignee)
+	if err != nil {
+		return err
+	}
+
+	existingID, err := r.refs.Lookup(r.opts.WorkspaceID, r.source, "issue", rec.ExternalID)
+	if err != nil {
+		return err
+	}
+
+	var issue *db.Issue
+	if existingID != "" {
+		issue, err = r.issues.GetByID(existingID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if issue == nil {
+		issue = &db.Issue{
+			ID:          r.newID("issue"),
+			WorkspaceID: r.opts.WorkspaceID,
+			CreatedAt:   rec.CreatedAt,
+		}
+		if issue.CreatedAt.IsZero() {
+			issue.CreatedAt = time.Now()
+		}
+	}
+
+	issue.Title = rec.Title
+	issue.Description = rec.Description
+	issue.Status = status
+	issue.Priority = rec.Priority
+	issue.Estimate = rec.Estimate
+	issue.Labels = rec.Labels
+	issue.AssigneeID = assigneeID
+	issue.CycleID = cycleID
+	issue.UpdatedAt = rec.UpdatedAt
+	if issue.UpdatedAt.IsZero() {
+		issue.UpdatedAt = issue.CreatedAt
+	}
+	issue.CompletedAt = nil
+	if status == "done" {
+		issue.CompletedAt = rec.CompletedAt
+		if issue.CompletedAt == nil {
+			completed := issue.UpdatedAt
+			issue.CompletedAt = &completed
+		}
+	}
+
+	if existingID != "" && issue.ID == existingID {
+		// Imported fields overwrite the issue as they are; workflow rules
+		// such as WIP limits apply to changes made in Pulse, not to history.
+		if err := r.issues.Replace(issue); err != nil {
+			return err
+		}
+		r.result.Updated++
+	} else {
+		if err := r.issues.Insert(issue); err != nil {
+			return err
+		}
+		if err := r.refs.Record(r.opts.WorkspaceID, r.source, "issue", rec.ExternalID, issue.ID); err != nil {
+			return err
+		}
+		r.result.Created++
+	}
+
+	r.issueID[rec.ExternalID] = issue.ID
+	return nil
+}
+
+func (r *run) linkParent(rec *Record) error {
+	parentID, ok := r.issueID[rec.ParentExternalID]
+	if !ok {
+		id, err := r.refs.Lookup(r.opts.WorkspaceID, r.source, "issue", rec.ParentExternalID)
+		if err != nil {
+			return err
+		}
+		if id == "" {
+			r.result.Warnings = append(r.result.Warnings,
+				fmt.Sprintf("%s: parent %s not found; imported as a top-level issue", rec.ExternalID, rec.ParentExternalID))
+			return nil
+		}
+		parentID = id
+	}
+
+	issue, err := r.issues.GetByID(r.issueID[rec.ExternalID])
+	if err != nil {
+		return err
+	}
+	if issue == nil || issue.ParentID == parentID {
+		return nil
+	}
+
+	// The link is part of the record as of its last update upstream.
+	issue.ParentID = parentID
+	return r.issues.Replace(issue)
+}
+
+func (r *run) ensureCycle(sprint *Sprint) (string, error) {
+	if sprint == nil || sprint.Name == "" {
+		return "", nil
+	}
+
+	id, err := r.refs.Lookup(r.opts.WorkspaceID, r.source, "cycle", sprint.Name)
+	if err != nil || id != "" {
+		return id, err
+	}
+
+	status := "upcoming"
+	now := time.Now()
+	if sprint.EndDate != nil && sprint.EndDate.Before(now) {
+		status = "completed"
+	} else if sprint.StartDate != nil && sprint.StartDate.Before(now) {
+		status = "active"
+	}
+
+	cycle := &db.Cycle{
+		ID:          r.newID("cycle"),
+		WorkspaceID: r.opts.WorkspaceID,
+		Name:        sprint.Name,
+		StartDate:   sprint.StartDate,
+		EndDate:     sprint.EndDate,
+		Status:      status,
+	}
+	if err := r.cycles.Create(cycle); err != nil {
+		return "", err
+	}
+	if err := r.refs.Record(r.opts.WorkspaceID, r.source, "cycle", sprint.Name, cycle.ID); err != nil {
+		return "", err
+	}
+
+	r.result.Cycles++
+	return cycle.ID, nil
+}
+
+func (r *run) ensureUser(p *Person) (string, error) {
+	if p == nil || p.Handle == "" {
+		return "", nil
+	}
+
+	id, err := r.refs.Lookup(r.opts.WorkspaceID, r.source, "user", p.Handle)
+	if err != nil || id != "" {
+		return id, err
+	}
+
+	// Users without an address in the export get a placeholder that is
+	// unique per source; it can be corrected once they sign in.
+	email := p.Email
+	if email == "" {
+		email = fmt.Sprintf("%s@%s.import.invalid", slug(p.Handle), r.source)
+	}
+
+	user, err := r.users.GetByEmail(email)
+	if err != nil {
+		return "", err
+	}
+	if user == nil {
+		name := p.Name
+		if name == "" {
+			name = p.Handle
+		}
+		user = &db.User{ID: r.newID("user"), Email: email, Name: name}
+		if err := r.users.Create(user); err != nil {
+			return "", err
+		}
+		r.result.Users++
+	}
+
+	if err := r.refs.Record(r.opts.WorkspaceID, r.source, "user", p.Handle, user.ID); err != nil {
+		return "", err
+	}
+
+	return user.ID, nil
+}
+
+// slug lowercases s and replaces anything but letters and digits with dots.
+func slug(s string) string {
+	var b strings.Builder
+	for _, c := range strings.ToLower(s) {
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') {
+			b.WriteRune(c)
+		} else if b.Len() > 0 && !strings.HasSuffix(b.String(), ".") {
+			b.WriteByte('.')
+		}
+	}
+	return strings.Trim(b.String(), ".")
+}
+
+// splitList splits a comma separated cell, trimming blanks.
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// parseTime tries each layout in turn, returning nil for empty input.
+func parseTime(s string, layouts ...string) *time.Time {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+// csvTable indexes CSV header names, which may repeat (Jira repeats
+// "Labels" and "Sprint" once per value).
+type csvTable struct {
+	index map[string][]int
+}
+
+func newCSVTable(header []string) *csvTable {
+	t := &csvTable{index: make(map[string][]int)}
+	for i, name := range header {
+		key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		t.index[key] = append(t.index[key], i)
+	}
+	return t
+}
+
+// get returns the first non-empty value of any of the named columns.
+func (t *csvTable) get(row []string, names ...string) string {
+	for _, name := range names {
+		for _, i := range t.index[strings.ToLower(name)] {
+			if i < len(row) && strings.TrimSpace(row[i]) != "" {
+				return strings.TrimSpace(row[i])
+			}
+		}
+	}
+	return ""
+}
+
+// all returns every non-empty value of a repeated column.
+func (t *csvTable) all(row []string, name string) []string {
+	var out []string
+	for _, i := range t.index[strings.ToLower(name)] {
+		if i < len(row) && strings.TrimSpace(row[i]) != "" {
+			out = append(out, strings.TrimSpace(row[i]))
+		}
+	}
+	return out
+}
+
+func (t *csvTable) has(name string) bool {
+	return len(t.index[strings.ToLower(name)]) > 0
+}
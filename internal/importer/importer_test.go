@@ -0,0 +1,185 @@
+package importer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+func openDB(t *testing.T) *db.DB {
+	t.Helper()
+	database, err := db.New(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if err := database.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	return database
+}
+
+func createWorkspace(t *testing.T, database *db.DB, id, settings string) {
+	t.Helper()
+	ws := &db.Workspace{ID: id, Name: id, Settings: settings}
+	if err := db.NewWorkspaceRepository(database).Create(ws); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func records() []*Record {
+	created := time.Date(2026, 1, 5, 9, 0, 0, 0, time.UTC)
+	completed := time.Date(2026, 1, 9, 17, 0, 0, 0, time.UTC)
+	return []*Record{
+		{ExternalID: "ENG-1", Title: "Shipped", Status: "Done", CreatedAt: created, UpdatedAt: completed, CompletedAt: &completed, Sprint: &Sprint{Name: "Sprint 1"}},
+		{ExternalID: "ENG-2", Title: "Started", Status: "In Progress", CreatedAt: created, Sprint: &Sprint{Name: "Sprint 1"}},
+		{ExternalID: "ENG-3", Title: "Also started", Status: "In Progress", CreatedAt: created},
+	}
+}
+
+func TestImportIntoSeparateWorkspaces(t *testing.T) {
+	database := openDB(t)
+	createWorkspace(t, database, "ws_a", "")
+	createWorkspace(t, database, "ws_b", "")
+	src, err := Lookup("linear")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ws := range []string{"ws_a", "ws_b"} {
+		result, err := Run(database, src, records(), Options{WorkspaceID: ws})
+		if err != nil {
+			t.Fatalf("import into %s: %v", ws, err)
+		}
+		if result.Created != 3 || result.Updated != 0 || result.Cycles != 1 {
+			t.Errorf("import into %s: %+v", ws, result)
+		}
+	}
+
+	for _, ws := range []string{"ws_a", "ws_b"} {
+		issues, err := db.NewIssueRepository(database).List(ws, "", 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cycles, err := db.NewCycleRepository(database).List(ws)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(issues) != 3 || len(cycles) != 1 {
+			t.Errorf("%s has %d issues and %d cycles, want 3 and 1", ws, len(issues), len(cycles))
+		}
+		for _, issue := range issues {
+			if issue.CycleID != "" && issue.CycleID != cycles[0].ID {
+				t.Errorf("%s: issue %s is in another workspace's cycle %s", ws, issue.Title, issue.CycleID)
+			}
+		}
+	}
+}
+
+func TestReimportBypassesWorkflowRules(t *testing.T) {
+	database := openDB(t)
+	createWorkspace(t, database, "ws_a", `{"wipLimits":{"in_progress":1},"enforceWipLimits":true}`)
+	src, err := Lookup("linear")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recs := records()
+	recs[0].Status, recs[0].CompletedAt = "In Progress", nil
+	recs[2].Status = "Todo"
+	if _, err := Run(database, src, recs, Options{WorkspaceID: "ws_a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Upstream, one issue was finished and another started, leaving two in
+	// progress against a limit of one.
+	recs = records()
+	result, err := Run(database, src, recs, Options{WorkspaceID: "ws_a"})
+	if err != nil {
+		t.Fatalf("re-import over a WIP limit: %v", err)
+	}
+	if result.Created != 0 || result.Updated != 3 {
+		t.Errorf("re-import: %+v", result)
+	}
+
+	issues := db.NewIssueRepository(database)
+	started, err := issues.List("ws_a", "in_progress", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(started) != 2 {
+		t.Errorf("%d issues in progress, want 2", len(started))
+	}
+	done, err := issues.List("ws_a", "done", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != 1 {
+		t.Fatalf("%d done issues, want 1", len(done))
+	}
+	if want := *recs[0].CompletedAt; done[0].CompletedAt == nil || !done[0].CompletedAt.Equal(want) {
+		t.Errorf("completed at %v, want %v", done[0].CompletedAt, want)
+	}
+}
+
+func TestParentLinkKeepsRecordTime(t *testing.T) {
+	database := openDB(t)
+	createWorkspace(t, database, "ws_a", `{"wipLimits":{"in_progress":1},"enforceWipLimits":true}`)
+	src, err := Lookup("linear")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updated := time.Date(2026, 1, 7, 12, 0, 0, 0, time.UTC)
+	recs := records()
+	// Sub-issues come first so the link is made once the parent exists,
+	// on issues already over the WIP limit.
+	recs = append([]*Record{
+		{ExternalID: "ENG-4", Title: "Sub-task", Status: "In Progress", CreatedAt: updated.Add(-time.Hour), UpdatedAt: updated, ParentExternalID: "ENG-2"},
+	}, recs...)
+	if _, err := Run(database, src, recs, Options{WorkspaceID: "ws_a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	issues := db.NewIssueRepository(database)
+	started, err := issues.List("ws_a", "in_progress", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var child, parent *db.Issue
+	for _, issue := range started {
+		switch issue.Title {
+		case "Sub-task":
+			child = issue
+		case "Started":
+			parent = issue
+		}
+	}
+	if child == nil || parent == nil {
+		t.Fatalf("imported in-progress issues: %v", started)
+	}
+	if child.ParentID != parent.ID {
+		t.Errorf("child parent = %q, want %q", child.ParentID, parent.ID)
+	}
+	if !child.UpdatedAt.Equal(updated) {
+		t.Errorf("child updated at %v, want the record's %v", child.UpdatedAt, updated)
+	}
+
+	events, err := db.NewEventRepository(database).ListByIssue(child.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, e := range events {
+		if e.Field == db.FieldParent {
+			found = true
+			if !e.CreatedAt.Equal(updated) {
+				t.Errorf("parent event at %v, want %v", e.CreatedAt, updated)
+			}
+		}
+	}
+	if !found {
+		t.Error("no parent event recorded")
+	}
+}
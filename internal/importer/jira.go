@@ -0,0 +1,131 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	register(jiraSource{})
+}
+
+// jiraSource reads Jira's "Export Excel CSV (all fields)" format. Jira
+// repeats the Labels and Sprint columns once per value.
+type jiraSource struct{}
+
+func (jiraSource) Name() string { return "jira-csv" }
+
+func (jiraSource) DefaultStatusMap() map[string]string {
+	return map[string]string{
+		"Backlog":                  "backlog",
+		"Open":                     "todo",
+		"To Do":                    "todo",
+		"Selected for Development": "todo",
+		"In Progress":              "in_progress",
+		"In Review":                "in_progress",
+		"Done":                     "done",
+		"Closed":                   "done",
+		"Resolved":                 "done",
+		"Won't Do":                 "canceled",
+	}
+}
+
+var jiraPriorities = map[string]int{
+	"blocker":  1,
+	"critical": 1,
+	"highest":  1,
+	"high":     2,
+	"major":    2,
+	"medium":   3,
+	"low":      4,
+	"lowest":   4,
+	"minor":    4,
+	"trivial":  4,
+}
+
+func (jiraSource) Parse(r io.Reader) ([]*Record, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jira header: %w", err)
+	}
+	t := newCSVTable(header)
+	if !t.has("Issue key") || !t.has("Summary") {
+		return nil, fmt.Errorf("not a Jira export: missing Issue key or Summary column")
+	}
+
+	var records []*Record
+	// Sub-tasks reference their parent by numeric issue id, so keep a map
+	// from id to key to translate them once every row has been read.
+	keyByID := make(map[string]string)
+
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read jira row: %w", err)
+		}
+
+		rec := &Record{
+			ExternalID:       t.get(row, "Issue key"),
+			ParentExternalID: t.get(row, "Parent id", "Parent"),
+			Title:            t.get(row, "Summary"),
+			Description:      t.get(row, "Description"),
+			Status:           t.get(row, "Status"),
+			Priority:         jiraPriorities[strings.ToLower(t.get(row, "Priority"))],
+			Labels:           t.all(row, "Labels"),
+		}
+		if rec.ExternalID == "" {
+			continue
+		}
+		if id := t.get(row, "Issue id"); id != "" {
+			keyByID[id] = rec.ExternalID
+		}
+
+		points := t.get(row, "Custom field (Story Points)", "Custom field (Story point estimate)", "Story Points")
+		if est, err := strconv.ParseFloat(points, 64); err == nil {
+			rec.Estimate = int(est)
+		}
+		if assignee := t.get(row, "Assignee"); assignee != "" {
+			rec.Assignee = &Person{Handle: assignee, Name: assignee}
+			if strings.Contains(assignee, "@") {
+				rec.Assignee.Email = assignee
+			}
+		}
+		// An issue carried over between sprints lists each one; the last
+		// is the sprint it currently belongs to.
+		if sprints := t.all(row, "Sprint"); len(sprints) > 0 {
+			rec.Sprint = &Sprint{Name: sprints[len(sprints)-1]}
+		}
+		if created := jiraTime(t.get(row, "Created")); created != nil {
+			rec.CreatedAt = *created
+		}
+		if updated := jiraTime(t.get(row, "Updated")); updated != nil {
+			rec.UpdatedAt = *updated
+		}
+		rec.CompletedAt = jiraTime(t.get(row, "Resolved"))
+
+		records = append(records, rec)
+	}
+
+	for _, rec := range records {
+		if key, ok := keyByID[rec.ParentExternalID]; ok {
+			rec.ParentExternalID = key
+		}
+	}
+
+	return records, nil
+}
+
+func jiraTime(s string) *time.Time {
+	return parseTime(s, "02/Jan/06 3:04 PM", "02/Jan/06 15:04", "2006-01-02 15:04", time.RFC3339)
+}
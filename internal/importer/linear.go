@@ -0,0 +1,113 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	register(linearSource{})
+}
+
+// linearSource reads the CSV produced by Linear's "Export issues" command.
+type linearSource struct{}
+
+func (linearSource) Name() string { return "linear" }
+
+func (linearSource) DefaultStatusMap() map[string]string {
+	return map[string]string{
+		"Triage":      "backlog",
+		"Backlog":     "backlog",
+		"Todo":        "todo",
+		"In Progress": "in_progress",
+		"In Review":   "in_progress",
+		"Done":        "done",
+		"Canceled":    "canceled",
+		"Duplicate":   "canceled",
+	}
+}
+
+var linearPriorities = map[string]int{
+	"urgent":      1,
+	"high":        2,
+	"medium":      3,
+	"low":         4,
+	"no priority": 0,
+}
+
+func (linearSource) Parse(r io.Reader) ([]*Record, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read linear header: %w", err)
+	}
+	t := newCSVTable(header)
+	if !t.has("ID") || !t.has("Title") {
+		return nil, fmt.Errorf("not a Linear export: missing ID or Title column")
+	}
+
+	var records []*Record
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read linear row: %w", err)
+		}
+
+		rec := &Record{
+			ExternalID:       t.get(row, "ID"),
+			ParentExternalID: t.get(row, "Parent issue"),
+			Title:            t.get(row, "Title"),
+			Description:      t.get(row, "Description"),
+			Status:           t.get(row, "Status"),
+			Priority:         linearPriorities[strings.ToLower(t.get(row, "Priority"))],
+			Labels:           splitList(t.get(row, "Labels")),
+		}
+		if rec.ExternalID == "" {
+			continue
+		}
+
+		if est, err := strconv.ParseFloat(t.get(row, "Estimate"), 64); err == nil {
+			rec.Estimate = int(est)
+		}
+		if assignee := t.get(row, "Assignee"); assignee != "" {
+			rec.Assignee = &Person{Handle: assignee, Name: assignee}
+			if strings.Contains(assignee, "@") {
+				rec.Assignee.Email = assignee
+			}
+		}
+		if name := t.get(row, "Cycle Name", "Cycle Number"); name != "" {
+			if _, err := strconv.Atoi(name); err == nil {
+				name = "Cycle " + name
+			}
+			rec.Sprint = &Sprint{
+				Name:      name,
+				StartDate: linearTime(t.get(row, "Cycle Start")),
+				EndDate:   linearTime(t.get(row, "Cycle End")),
+			}
+		}
+		if created := linearTime(t.get(row, "Created")); created != nil {
+			rec.CreatedAt = *created
+		}
+		if updated := linearTime(t.get(row, "Updated")); updated != nil {
+			rec.UpdatedAt = *updated
+		}
+		rec.CompletedAt = linearTime(t.get(row, "Completed"))
+
+		records = append(records, rec)
+	}
+
+	return records, nil
+}
+
+func linearTime(s string) *time.Time {
+	return parseTime(s, time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02", "Mon Jan 02 2006 15:04:05 GMT-0700")
+}
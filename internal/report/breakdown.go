@@ -0,0 +1,35 @@
+package report
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+// BreakdownTable lays out delivery metrics, one row per group.
+func BreakdownTable(groupBy string, groups []*db.BreakdownRow) *Table {
+	table := &Table{Headers: []string{
+		strings.ToUpper(groupBy[:1]) + groupBy[1:],
+		"WIP", "WIP points", "Throughput", "Throughput points",
+		"Cycle time avg (h)", "Cycle time max (h)",
+		"Velocity planned", "Velocity completed", "Velocity avg",
+	}}
+
+	for _, g := range groups {
+		table.Rows = append(table.Rows, []string{
+			g.Key,
+			strconv.Itoa(g.WIP),
+			strconv.Itoa(g.WIPPoints),
+			strconv.Itoa(g.Throughput),
+			strconv.Itoa(g.ThroughputPoints),
+			strconv.FormatFloat(g.CycleTimeAvgHours, 'f', 1, 64),
+			strconv.FormatFloat(g.CycleTimeMaxHours, 'f', 1, 64),
+			strconv.Itoa(g.VelocityPlanned),
+			strconv.Itoa(g.VelocityCompleted),
+			strconv.FormatFloat(g.VelocityAverage, 'f', 1, 64),
+		})
+	}
+
+	return table
+}
@@ -0,0 +1,104 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+// CycleReport summarises the work planned and completed in a cycle.
+type CycleReport struct {
+	Cycle           *db.Cycle      `json:"cycle"`
+	IssuesPlanned   int            `json:"issues_planned"`
+	IssuesCompleted int            `json:"issues_completed"`
+	PointsPlanned   int            `json:"points_planned"`
+	PointsCompleted int            `json:"points_completed"`
+	CompletionRate  float64        `json:"completion_rate"`
+	Carryover       int            `json:"carryover"`
+	StatusCounts    map[string]int `json:"status_counts"`
+	Issues          []*db.Issue    `json:"issues"`
+}
+
+// BuildCycleReport computes the summary for a cycle from its issues.
+// Canceled issues are listed but not counted as planned work.
+func BuildCycleReport(cycle *db.Cycle, issues []*db.Issue) *CycleReport {
+	r := &CycleReport{
+		Cycle:        cycle,
+		StatusCounts: make(map[string]int),
+		Issues:       issues,
+	}
+	if r.Issues == nil {
+		r.Issues = []*db.Issue{}
+	}
+
+	for _, issue := range issues {
+		r.StatusCounts[issue.Status]++
+		if issue.Status == "canceled" {
+			continue
+		}
+		r.IssuesPlanned++
+		r.PointsPlanned += issue.Estimate
+		if issue.Status == "done" {
+			r.IssuesCompleted++
+			r.PointsCompleted += issue.Estimate
+		}
+	}
+
+	r.Carryover = r.PointsPlanned - r.PointsCompleted
+	if r.PointsPlanned > 0 {
+		r.CompletionRate = float64(r.PointsCompleted) / float64(r.PointsPlanned) * 100
+	} else if r.IssuesPlanned > 0 {
+		r.CompletionRate = float64(r.IssuesCompleted) / float64(r.IssuesPlanned) * 100
+	}
+
+	return r
+}
+
+// SummaryTable returns the headline numbers as a two-column table.
+func (r *CycleReport) SummaryTable() *Table {
+	dates := ""
+	if r.Cycle.StartDate != nil && r.Cycle.EndDate != nil {
+		dates = r.Cycle.StartDate.Format("2006-01-02") + " – " + r.Cycle.EndDate.Format("2006-01-02")
+	}
+
+	return &Table{
+		Headers: []string{"Metric", "Value"},
+		Rows: [][]string{
+			{"Cycle", r.Cycle.Name},
+			{"Status", r.Cycle.Status},
+			{"Dates", dates},
+			{"Issues completed", fmt.Sprintf("%d / %d", r.IssuesCompleted, r.IssuesPlanned)},
+			{"Points completed", fmt.Sprintf("%d / %d", r.PointsCompleted, r.PointsPlanned)},
+			{"Completion rate", fmt.Sprintf("%.0f%%", r.CompletionRate)},
+			{"Carryover points", strconv.Itoa(r.Carryover)},
+		},
+	}
+}
+
+// Write renders the report. CSV contains only the issue rows so it loads
+// cleanly into a spreadsheet; Markdown and text include the summary.
+func (r *CycleReport) Write(w io.Writer, format string, columns []string) error {
+	issues := IssueTable(r.Issues, columns)
+
+	switch format {
+	case FormatCSV:
+		return WriteCSV(w, issues)
+	case FormatMarkdown:
+		fmt.Fprintf(w, "## %s\n\n", r.Cycle.Name)
+		if err := WriteMarkdown(w, r.SummaryTable()); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "\n### Issues\n\n")
+		return WriteMarkdown(w, issues)
+	case FormatTable:
+		if err := WriteText(w, r.SummaryTable()); err != nil {
+			return err
+		}
+		fmt.Fprintln(w)
+		return WriteText(w, issues)
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
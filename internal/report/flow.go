@@ -0,0 +1,59 @@
+package report
+
+import (
+	"strconv"
+
+	"github.com/pulse/pm/internal/analytics"
+)
+
+// CFDTable lays out a cumulative flow diagram, one row per day with a
+// column per status.
+func CFDTable(cfd *analytics.CFD) *Table {
+	table := &Table{Headers: append([]string{"Date"}, cfd.Statuses...)}
+	table.Headers = append(table.Headers, "Total")
+
+	for _, day := range cfd.Days {
+		row := []string{formatDate(day.Date)}
+		for _, status := range cfd.Statuses {
+			row = append(row, strconv.Itoa(day.Counts[status]))
+		}
+		table.Rows = append(table.Rows, append(row, strconv.Itoa(day.Total)))
+	}
+
+	return table
+}
+
+// ThroughputTable lays out completed work, one row per period.
+func ThroughputTable(s *analytics.ThroughputSeries) *Table {
+	table := &Table{Headers: []string{"Start", "Issues", "Points"}}
+
+	for _, p := range s.Periods {
+		table.Rows = append(table.Rows, []string{
+			formatDate(p.Start),
+			strconv.Itoa(p.Issues),
+			strconv.Itoa(p.Points),
+		})
+	}
+
+	return table
+}
+
+// BurndownTable lays out a cycle burndown, one row per day.
+func BurndownTable(b *analytics.Burndown) *Table {
+	table := &Table{Headers: []string{"Date", "Remaining", "Completed", "Scope", "Remaining issues", "Completed issues", "Scope issues", "Ideal"}}
+
+	for _, p := range b.Points {
+		table.Rows = append(table.Rows, []string{
+			formatDate(p.Date),
+			strconv.Itoa(p.Remaining),
+			strconv.Itoa(p.Completed),
+			strconv.Itoa(p.Scope),
+			strconv.Itoa(p.RemainingIssues),
+			strconv.Itoa(p.CompletedIssues),
+			strconv.Itoa(p.ScopeIssues),
+			strconv.FormatFloat(p.Ideal, 'f', 1, 64),
+		})
+	}
+
+	return table
+}
@@ -0,0 +1,100 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+// issueColumn extracts one display column from an issue.
+type issueColumn struct {
+	header string
+	value  func(*db.Issue) string
+}
+
+// issueColumns are the columns available for issue tables, keyed by the
+// name accepted in columns= and --columns.
+var issueColumns = map[string]issueColumn{
+	"id":           {"ID", func(i *db.Issue) string { return i.ID }},
+	"key":          {"Key", func(i *db.Issue) string { return i.Key }},
+	"branch":       {"Branch", func(i *db.Issue) string { return i.Branch }},
+	"title":        {"Title", func(i *db.Issue) string { return i.Title }},
+	"description":  {"Description", func(i *db.Issue) string { return i.Description }},
+	"status":       {"Status", func(i *db.Issue) string { return i.Status }},
+	"priority":     {"Priority", func(i *db.Issue) string { return db.PriorityName(i.Priority) }},
+	"assignee":     {"Assignee", func(i *db.Issue) string { return i.AssigneeID }},
+	"estimate":     {"Estimate", func(i *db.Issue) string { return strconv.Itoa(i.Estimate) }},
+	"labels":       {"Labels", func(i *db.Issue) string { return strings.Join(i.Labels, ", ") }},
+	"cycle":        {"Cycle", func(i *db.Issue) string { return i.CycleID }},
+	"parent":       {"Parent", func(i *db.Issue) string { return i.ParentID }},
+	"project":      {"Project", func(i *db.Issue) string { return i.ProjectID }},
+	"milestone":    {"Milestone", func(i *db.Issue) string { return i.MilestoneID }},
+	"start_date":   {"Start", func(i *db.Issue) string { return formatTime(i.StartDate) }},
+	"due_date":     {"Due", func(i *db.Issue) string { return formatTime(i.DueDate) }},
+	"created_at":   {"Created", func(i *db.Issue) string { return formatTime(&i.CreatedAt) }},
+	"updated_at":   {"Updated", func(i *db.Issue) string { return formatTime(&i.UpdatedAt) }},
+	"completed_at": {"Completed", func(i *db.Issue) string { return formatTime(i.CompletedAt) }},
+}
+
+// DefaultIssueColumns are used when no columns are requested.
+var DefaultIssueColumns = []string{"key", "title", "status", "priority", "assignee", "estimate", "labels"}
+
+// IssueColumnNames lists every available issue column.
+func IssueColumnNames() []string {
+	names := make([]string, 0, len(issueColumns))
+	for name := range issueColumns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseColumns splits a comma separated column list, falling back to the
+// defaults when it is empty.
+func ParseColumns(spec string) ([]string, error) {
+	if strings.TrimSpace(spec) == "" {
+		return DefaultIssueColumns, nil
+	}
+
+	var columns []string
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := issueColumns[name]; !ok {
+			return nil, fmt.Errorf("unknown column %q (available: %s)", name, strings.Join(IssueColumnNames(), ", "))
+		}
+		columns = append(columns, name)
+	}
+	return columns, nil
+}
+
+// IssueTable lays out issues with the given columns.
+func IssueTable(issues []*db.Issue, columns []string) *Table {
+	t := &Table{Headers: make([]string, len(columns))}
+	for i, name := range columns {
+		t.Headers[i] = issueColumns[name].header
+	}
+
+	for _, issue := range issues {
+		row := make([]string, len(columns))
+		for i, name := range columns {
+			row[i] = issueColumns[name].value(issue)
+		}
+		t.Rows = append(t.Rows, row)
+	}
+
+	return t
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,120 @@
+// Package report renders issue lists and cycle summaries as CSV, Markdown
+// or aligned text tables, for the API and the CLI alike.
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats understood by Write.
+const (
+	FormatJSON     = "json"
+	FormatCSV      = "csv"
+	FormatMarkdown = "md"
+	FormatTable    = "table"
+)
+
+// Table is a rectangular set of string cells with a header row.
+type Table struct {
+	Headers []string
+	Rows    [][]string
+}
+
+// ValidFormat reports whether f is a tabular format handled by Write.
+func ValidFormat(f string) bool {
+	switch f {
+	case FormatCSV, FormatMarkdown, FormatTable:
+		return true
+	}
+	return false
+}
+
+// ContentType returns the HTTP content type for a tabular format.
+func ContentType(f string) string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// Write renders t in the given tabular format.
+func Write(w io.Writer, format string, t *Table) error {
+	switch format {
+	case FormatCSV:
+		return WriteCSV(w, t)
+	case FormatMarkdown:
+		return WriteMarkdown(w, t)
+	case FormatTable:
+		return WriteText(w, t)
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// WriteCSV renders t as RFC 4180 CSV.
+func WriteCSV(w io.Writer, t *Table) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(t.Headers); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(t.Rows); err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteMarkdown renders t as a GitHub-flavoured Markdown table.
+func WriteMarkdown(w io.Writer, t *Table) error {
+	var b strings.Builder
+
+	b.WriteString("|")
+	for _, h := range t.Headers {
+		b.WriteString(" " + escapeMarkdown(h) + " |")
+	}
+	b.WriteString("\n|")
+	for range t.Headers {
+		b.WriteString(" --- |")
+	}
+	b.WriteString("\n")
+
+	for _, row := range t.Rows {
+		b.WriteString("|")
+		for _, cell := range row {
+			b.WriteString(" " + escapeMarkdown(cell) + " |")
+		}
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteText renders t as space-aligned columns for terminals.
+func WriteText(w io.Writer, t *Table) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(t.Headers, "\t")))
+	for _, row := range t.Rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = strings.ReplaceAll(cell, "\n", " ")
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// escapeMarkdown keeps cell content from breaking the table layout.
+func escapeMarkdown(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	s = strings.ReplaceAll(s, "\r\n", "<br>")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
@@ -0,0 +1,72 @@
+package report
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pulse/pm/internal/analytics"
+)
+
+// TimelineTable lays out a scheduled timeline, one row per item.
+func TimelineTable(t *analytics.Timeline) *Table {
+	table := &Table{
+		Headers: []string{"Kind", "Key", "Title", "Status", "Start", "Finish", "Due", "Slack", "Critical", "Late", "Blocked by"},
+	}
+
+	keys := make(map[string]string, len(t.Items))
+	for _, item := range t.Items {
+		keys[item.ID] = item.Key
+	}
+
+	for _, item := range t.Items {
+		key := item.Key
+		if key == "" {
+			key = item.ID
+		}
+		due := ""
+		if item.Due != nil {
+			due = item.Due.UTC().Format("2006-01-02")
+		}
+		slack, critical := "", ""
+		if item.Kind == analytics.KindIssue && item.Status != "done" {
+			slack = strconv.Itoa(item.SlackDays)
+			critical = yesNo(item.Critical)
+		}
+
+		blockers := make([]string, len(item.BlockedBy))
+		for i, id := range item.BlockedBy {
+			blockers[i] = keys[id]
+		}
+
+		table.Rows = append(table.Rows, []string{
+			item.Kind,
+			key,
+			item.Title,
+			item.Status,
+			formatDate(item.EarliestStart),
+			formatDate(item.EarliestFinish),
+			due,
+			slack,
+			critical,
+			yesNo(item.Late),
+			strings.Join(blockers, ", "),
+		})
+	}
+
+	return table
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02")
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+// requireAdmin rejects requests that do not carry the admin token as a
+// bearer token, and hides the admin routes when no token is configured.
+func (s *Server) requireAdmin(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.adminToken == "" {
+			http.NotFound(w, r)
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="pulse admin"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h(w, r)
+	}
+}
+
+// handleAdminBackup takes an on-demand snapshot of the running database.
+func (s *Server) handleAdminBackup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	st := s.storesFor(r)
+	info, err := st.db.Snapshot(s.snapshotDir, s.snapshotRetain)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to back up database: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	jsonResponse(w, info)
+}
+
+// handleAdminBackups lists the snapshots available for restore.
+func (s *Server) handleAdminBackups(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	snapshots, err := db.ListSnapshots(s.snapshotDir)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list backups: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if snapshots == nil {
+		snapshots = []*db.BackupInfo{}
+	}
+
+	jsonResponse(w, snapshots)
+}
+
+// handleAdminRestore restores the running database from a named snapshot.
+// Only files in the snapshot directory can be restored through the API.
+func (s *Server) handleAdminRestore(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	st := s.storesFor(r)
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	if filepath.Base(req.Name) != req.Name {
+		http.Error(w, "invalid backup name", http.StatusBadRequest)
+		return
+	}
+
+	if err := st.db.Restore(filepath.Join(s.snapshotDir, req.Name)); err != nil {
+		http.Error(w, fmt.Sprintf("failed to restore database: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if err := st.db.Migrate(); err != nil {
+		http.Error(w, fmt.Sprintf("failed to migrate restored database: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	jsonResponse(w, map[string]interface{}{
+		"status":   "restored",
+		"name":     req.Name,
+		"restored": time.Now().Format(time.RFC3339),
+	})
+}
+
+// runSnapshots takes a snapshot every interval until ctx is cancelled.
+func (s *Server) runSnapshots(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := s.db.Snapshot(s.snapshotDir, s.snapshotRetain)
+			if err != nil {
+				s.logger.Error("snapshot failed", "error", err)
+				continue
+			}
+			s.logger.Info("snapshot written", "path", info.Path, "bytes", info.Size)
+		}
+	}
+}
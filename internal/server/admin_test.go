@@ -0,0 +1,56 @@
+package server
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminRoutesDisabledByDefault(t *testing.T) {
+	s := newTestServer(t)
+	for _, path := range []string{"/api/admin/backup", "/api/admin/backups", "/api/admin/restore"} {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set("Authorization", "Bearer ")
+		rec := httptest.NewRecorder()
+		s.mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("%s: status %d without an admin token configured, want 404", path, rec.Code)
+		}
+	}
+}
+
+func TestAdminRoutesRequireToken(t *testing.T) {
+	s, err := NewServer(Config{
+		DataDir:             t.TempDir(),
+		AdminToken:          "s3cret",
+		BreachCheckInterval: -1,
+		AutoCloseInterval:   -1,
+		Logger:              slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	for _, auth := range []string{"", "s3cret", "Bearer wrong", "Bearer s3cret2"} {
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/backup", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		s.mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: status %d, want 401", auth, rec.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/backup", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	rec := httptest.NewRecorder()
+	s.mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("backup with the admin token: %d %s", rec.Code, rec.Body)
+	}
+}
@@ -0,0 +1,184 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pulse/pm/internal/analytics"
+	"github.com/pulse/pm/internal/db"
+)
+
+// DefaultAutoCloseInterval is how often the server cancels stale backlog
+// issues when Config.AutoCloseInterval is unset.
+const DefaultAutoCloseInterval = 24 * time.Hour
+
+// handleAgingMetrics buckets a workspace's open issues by age and by time
+// in their current status. buckets= sets the bucket boundaries in days
+// (default 7,30,90,180) and stale_days= the time in status after which
+// an issue is listed as stale (default the workspace's autoCloseDays, or
+// 30).
+func (s *Server) handleAgingMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	st := s.storesFor(r)
+	bounds := analytics.DefaultAgeBuckets
+	if v := r.URL.Query().Get("buckets"); v != "" {
+		var err error
+		if bounds, err = parseBuckets(v); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	workspaceID := workspaceParam(r)
+	settings, ok := st.workspaceSettings(w, workspaceID)
+	if !ok {
+		return
+	}
+
+	staleDays := settings.AutoCloseDays
+	if staleDays <= 0 {
+		staleDays = analytics.DefaultStaleDays
+	}
+	if v := r.URL.Query().Get("stale_days"); v != "" {
+		d, err := strconv.Atoi(v)
+		if err != nil || d <= 0 {
+			http.Error(w, "stale_days must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		staleDays = d
+	}
+
+	issues, err := st.issueRepo.List(workspaceID, "", 0, 0)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list issues: %v", err), http.StatusInternalServerError)
+		return
+	}
+	events, err := db.NewEventRepository(st.db).ListByWorkspace(workspaceID, time.Time{})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list history: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	jsonResponse(w, analytics.CalculateAging(issues, analytics.NewHistory(events), bounds, staleDays, time.Now()))
+}
+
+// parseBuckets reads comma-separated, strictly ascending day boundaries.
+func parseBuckets(v string) ([]int, error) {
+	var bounds []int
+	for _, part := range strings.Split(v, ",") {
+		d, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid bucket %q: buckets must be positive day counts", part)
+		}
+		bounds = append(bounds, d)
+	}
+	if !sort.IntsAreSorted(bounds) {
+		return nil, fmt.Errorf("buckets must be in ascending order")
+	}
+	for i := 1; i < len(bounds); i++ {
+		if bounds[i] == bounds[i-1] {
+			return nil, fmt.Errorf("duplicate bucket %d", bounds[i])
+		}
+	}
+	return bounds, nil
+}
+
+// runAutoClose cancels stale backlog issues now and then every interval
+// until ctx is cancelled.
+func (s *Server) runAutoClose(ctx context.Context, interval time.Duration) {
+	check := func() {
+		n, err := s.autoCloseStale(time.Now())
+		if err != nil {
+			s.logger.Error("auto-close failed", "canceled", n, "error", err)
+			return
+		}
+		if n > 0 {
+			s.logger.Info("auto-close", "canceled", n)
+		}
+	}
+
+	check()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			check()
+		}
+	}
+}
+
+// autoCloseStale cancels the backlog issues of every workspace that sets
+// autoCloseDays once they have gone that many days without activity,
+// leaving a comment that explains why. It returns how many were canceled.
+// A workspace or issue that cannot be closed is logged and skipped; the
+// failures are returned together once the rest are done.
+func (s *Server) autoCloseStale(now time.Time) (int, error) {
+	workspaces, err := s.workspaceRepo.List()
+	if err != nil {
+		return 0, err
+	}
+
+	closed := 0
+	var errs []error
+	for _, ws := range workspaces {
+		settings, err := ws.ParseSettings()
+		if err != nil || settings.AutoCloseDays <= 0 {
+			continue
+		}
+		issues, err := s.issueRepo.List(ws.ID, "backlog", 0, 0)
+		if err != nil {
+			s.logger.Warn("auto-close skipped workspace", "workspace", ws.ID, "error", err)
+			errs = append(errs, fmt.Errorf("workspace %s: %w", ws.ID, err))
+			continue
+		}
+		comments, err := s.commentRepo.ListByWorkspace(ws.ID)
+		if err != nil {
+			s.logger.Warn("auto-close skipped workspace", "workspace", ws.ID, "error", err)
+			errs = append(errs, fmt.Errorf("workspace %s: %w", ws.ID, err))
+			continue
+		}
+
+		for _, issue := range analytics.StaleIssues(issues, comments, settings.AutoCloseDays, now) {
+			comment := &db.Comment{
+				ID:       fmt.Sprintf("comment_%d", time.Now().UnixNano()),
+				IssueID:  issue.ID,
+				AuthorID: "pulse",
+				Body: fmt.Sprintf("Canceled automatically after %d days in the backlog without activity "+
+					"(workspace setting autoCloseDays). Move it back to the backlog if it is still relevant.", settings.AutoCloseDays),
+			}
+			issue.Status = "canceled"
+			err := s.db.InTx(func(tx *db.Tx) error {
+				if err := db.NewIssueRepository(tx).Update(issue); err != nil {
+					return err
+				}
+				return db.NewCommentRepository(tx).Create(comment)
+			})
+			if err != nil {
+				s.logger.Warn("auto-close skipped issue", "issue", issue.ID, "error", err)
+				errs = append(errs, fmt.Errorf("issue %s: %w", issue.ID, err))
+				continue
+			}
+
+			s.publishIssue(EventIssueUpdated, issue)
+			s.events.publish(Event{Type: EventCommentCreated, WorkspaceID: issue.WorkspaceID, IssueID: issue.ID, Comment: comment})
+			closed++
+		}
+	}
+
+	return closed, errors.Join(errs...)
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+func TestAutoCloseSkipsFailedIssues(t *testing.T) {
+	s := newTestServer(t)
+	if err := s.workspaceRepo.Create(&db.Workspace{ID: "ws", Name: "Stale", Settings: `{"autoCloseDays":3}`}); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"a", "stuck", "z"} {
+		if err := s.issueRepo.Create(&db.Issue{ID: id, WorkspaceID: "ws", Title: id, Status: "backlog"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.db.Exec(`CREATE TRIGGER stuck BEFORE UPDATE ON issues WHEN OLD.id = 'stuck'
+		BEGIN SELECT RAISE(ABORT, 'issue is locked'); END`); err != nil {
+		t.Fatal(err)
+	}
+
+	closed, err := s.autoCloseStale(time.Now().AddDate(0, 0, 7))
+	if closed != 2 {
+		t.Errorf("canceled %d issues, want 2", closed)
+	}
+	if err == nil || !strings.Contains(err.Error(), "issue stuck") || !strings.Contains(err.Error(), "issue is locked") {
+		t.Errorf("error = %v, want the failure for issue stuck", err)
+	}
+
+	for id, want := range map[string]string{"a": "canceled", "stuck": "backlog", "z": "canceled"} {
+		issue, err := s.issueRepo.GetByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if issue.Status != want {
+			t.Errorf("issue %s is %s, want %s", id, issue.Status, want)
+		}
+	}
+}
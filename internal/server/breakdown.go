@@ -0,0 +1,76 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pulse/pm/internal/analytics"
+	"github.com/pulse/pm/internal/db"
+	"github.com/pulse/pm/internal/report"
+)
+
+// defaultBreakdownDays is the throughput window of a breakdown when days=
+// is not set.
+const defaultBreakdownDays = 28
+
+// BreakdownResult is a workspace's delivery metrics per group.
+type BreakdownResult struct {
+	WorkspaceID string             `json:"workspace_id"`
+	GroupBy     string             `json:"group_by"`
+	Since       time.Time          `json:"since"`
+	Cycles      int                `json:"cycles"`
+	Groups      []*db.BreakdownRow `json:"groups"`
+}
+
+// handleMetricsBreakdown reports WIP, throughput, cycle time and velocity
+// per group_by=assignee, label, priority or project. Throughput and cycle
+// time cover issues completed in the last days= days (default 28), and
+// velocity the last cycles= cycles (default 6). format=csv or md lists one
+// row per group.
+func (s *Server) handleMetricsBreakdown(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	st := s.storesFor(r)
+	groupBy := r.URL.Query().Get("group_by")
+	if !db.ValidGroupBy(groupBy) {
+		http.Error(w, fmt.Sprintf("invalid group_by %q (use %s)", groupBy, strings.Join(db.GroupBys, ", ")), http.StatusBadRequest)
+		return
+	}
+	format, ok := tabularFormat(w, r)
+	if !ok {
+		return
+	}
+	days, ok := positiveParam(w, r, "days", defaultBreakdownDays)
+	if !ok {
+		return
+	}
+	cycles, ok := positiveParam(w, r, "cycles", analytics.DefaultQualityCycles)
+	if !ok {
+		return
+	}
+
+	result := &BreakdownResult{
+		WorkspaceID: workspaceParam(r),
+		GroupBy:     groupBy,
+		Since:       time.Now().UTC().AddDate(0, 0, -days),
+		Cycles:      cycles,
+	}
+	groups, err := st.metricsRepo.Breakdown(result.WorkspaceID, groupBy, result.Since, cycles)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to break down metrics: %v", err), http.StatusInternalServerError)
+		return
+	}
+	result.Groups = groups
+
+	if format != "" {
+		w.Header().Set("Content-Type", report.ContentType(format))
+		report.Write(w, format, report.BreakdownTable(groupBy, groups))
+		return
+	}
+	jsonResponse(w, result)
+}
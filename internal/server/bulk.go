@@ -0,0 +1,106 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+// maxBulkIssues caps how many issues a single bulk request may touch.
+const maxBulkIssues = 1000
+
+// handleBulkIssues applies one change set to many issues in a single
+// transaction. Issues are selected either by ID or by a search query.
+func (s *Server) handleBulkIssues(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	st := s.storesFor(r)
+	var req struct {
+		WorkspaceID string         `json:"workspace_id"`
+		IDs         []string       `json:"ids"`
+		Query       string         `json:"query"`
+		Changes     db.IssueChange `json:"changes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if len(req.IDs) > 0 && req.Query != "" {
+		http.Error(w, "specify either ids or query, not both", http.StatusBadRequest)
+		return
+	}
+	if len(req.IDs) == 0 && req.Query == "" {
+		http.Error(w, "no issues selected", http.StatusBadRequest)
+		return
+	}
+	if err := req.Changes.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ids := req.IDs
+	if req.Query != "" {
+		workspaceID := req.WorkspaceID
+		if workspaceID == "" {
+			workspaceID = "default"
+		}
+		req.WorkspaceID = workspaceID
+
+		issues, err := st.searchIssues(workspaceID, req.Query, url.Values{})
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to search issues: %v", err), searchErrorStatus(err))
+			return
+		}
+		for _, issue := range issues {
+			ids = append(ids, issue.ID)
+		}
+	}
+
+	if cycleID := req.Changes.CycleID; cycleID != nil && *cycleID != "" {
+		cycle, err := st.cycleRepo.GetByID(*cycleID)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to get cycle: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if cycle == nil {
+			http.Error(w, "cycle not found", http.StatusBadRequest)
+			return
+		}
+		if req.WorkspaceID != "" && cycle.WorkspaceID != req.WorkspaceID {
+			http.Error(w, "cycle belongs to another workspace", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if len(ids) > maxBulkIssues {
+		http.Error(w, fmt.Sprintf("too many issues: %d (max %d)", len(ids), maxBulkIssues), http.StatusBadRequest)
+		return
+	}
+
+	results, err := st.issueRepo.BulkUpdate(req.WorkspaceID, ids, &req.Changes)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to update issues: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	updated := 0
+	for _, result := range results {
+		if result.OK {
+			updated++
+			s.publishIssue(EventIssueUpdated, result.Issue)
+		}
+	}
+
+	jsonResponse(w, map[string]interface{}{
+		"updated": updated,
+		"failed":  len(results) - updated,
+		"results": results,
+	})
+}
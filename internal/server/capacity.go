@@ -0,0 +1,253 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pulse/pm/internal/analytics"
+	"github.com/pulse/pm/internal/db"
+)
+
+// handleCycleCapacity lists the member capacities set for a cycle.
+func (s *Server) handleCycleCapacity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	st := s.storesFor(r)
+	cycle, ok := st.findCycle(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	capacities, err := st.capacityRepo.ListByCycle(cycle.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list capacity: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if capacities == nil {
+		capacities = []*db.Capacity{}
+	}
+	jsonResponse(w, capacities)
+}
+
+// handleMemberCapacity sets (PUT {points, days_off}) or clears (DELETE) a
+// member's capacity for a cycle. Points fixes the capacity; otherwise it
+// follows from the working days left after days_off.
+func (s *Server) handleMemberCapacity(w http.ResponseWriter, r *http.Request) {
+	st := s.storesFor(r)
+	cycle, ok := st.findCycle(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	member := r.PathValue("member")
+
+	switch r.Method {
+	case http.MethodPut:
+		var req struct {
+			Points  int `json:"points"`
+			DaysOff int `json:"days_off"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+		if req.Points < 0 || req.DaysOff < 0 {
+			http.Error(w, "points and days_off must not be negative", http.StatusBadRequest)
+			return
+		}
+
+		capacity := &db.Capacity{CycleID: cycle.ID, MemberID: member, Points: req.Points, DaysOff: req.DaysOff}
+		if err := st.capacityRepo.Set(capacity); err != nil {
+			http.Error(w, fmt.Sprintf("failed to set capacity: %v", err), http.StatusInternalServerError)
+			return
+		}
+		jsonResponse(w, capacity)
+
+	case http.MethodDelete:
+		if err := st.capacityRepo.Delete(cycle.ID, member); err != nil {
+			http.Error(w, fmt.Sprintf("failed to delete capacity: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleCyclePlan compares the estimates assigned to each member of a
+// cycle with their capacity.
+func (s *Server) handleCyclePlan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	st := s.storesFor(r)
+	cycle, ok := st.findCycle(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	plan, _, ok := st.capacityPlan(w, cycle)
+	if !ok {
+		return
+	}
+	jsonResponse(w, plan)
+}
+
+// BalanceResult is a cycle's capacity plan after the suggested changes.
+type BalanceResult struct {
+	Plan        *analytics.CapacityPlan `json:"plan"`
+	Suggestions []analytics.Suggestion  `json:"suggestions"`
+	Applied     bool                    `json:"applied"`
+}
+
+// handleCycleBalance proposes reassigning, assigning or moving issues so
+// each member of a cycle fits their capacity, by priority. Issues moved
+// out go to the next cycle by start date, or the backlog when there is
+// none. GET only suggests; POST applies the suggestions.
+func (s *Server) handleCycleBalance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	st := s.storesFor(r)
+	cycle, ok := st.findCycle(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	plan, issues, ok := st.capacityPlan(w, cycle)
+	if !ok {
+		return
+	}
+	next, err := st.nextCycle(cycle)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list cycles: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	result := &BalanceResult{Plan: plan, Suggestions: analytics.Balance(plan, issues, next)}
+	if r.Method == http.MethodGet || len(result.Suggestions) == 0 {
+		jsonResponse(w, result)
+		return
+	}
+
+	byID := make(map[string]*db.Issue, len(issues))
+	for _, issue := range issues {
+		byID[issue.ID] = issue
+	}
+	var updated []*db.Issue
+	err = st.db.InTx(func(tx *db.Tx) error {
+		repo := db.NewIssueRepository(tx)
+		for _, sg := range result.Suggestions {
+			issue := byID[sg.IssueID]
+			switch sg.Action {
+			case analytics.ActionReassign, analytics.ActionAssign:
+				issue.AssigneeID = sg.ToAssignee
+			case analytics.ActionMove:
+				issue.CycleID = sg.ToCycleID
+			}
+			if err := repo.Update(issue); err != nil {
+				return fmt.Errorf("%s %s: %w", sg.Action, suggestionRef(sg), err)
+			}
+			updated = append(updated, issue)
+		}
+		return nil
+	})
+	if err != nil {
+		// An enforced WIP limit can refuse a reassignment; nothing is
+		// applied and the caller learns which suggestion was refused.
+		http.Error(w, fmt.Sprintf("failed to apply suggestions: %v", err), issueErrorStatus(err))
+		return
+	}
+
+	for _, issue := range updated {
+		s.publishIssue(EventIssueUpdated, issue)
+	}
+	result.Applied = true
+	jsonResponse(w, result)
+}
+
+// suggestionRef names the issue a suggestion changes and where it goes.
+func suggestionRef(sg analytics.Suggestion) string {
+	ref := sg.Key
+	if ref == "" {
+		ref = sg.IssueID
+	}
+	switch {
+	case sg.ToAssignee != "":
+		return ref + " to " + sg.ToAssignee
+	case sg.ToCycleID != "":
+		return ref + " to cycle " + sg.ToCycleID
+	case sg.Action == analytics.ActionMove:
+		return ref + " to the backlog"
+	}
+	return ref
+}
+
+// capacityPlan builds a cycle's capacity plan, returning it with the
+// cycle's issues and writing an error response when it cannot.
+func (st *stores) capacityPlan(w http.ResponseWriter, cycle *db.Cycle) (*analytics.CapacityPlan, []*db.Issue, bool) {
+	settings, ok := st.workspaceSettings(w, cycle.WorkspaceID)
+	if !ok {
+		return nil, nil, false
+	}
+	issues, err := st.issueRepo.ListByCycle(cycle.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list cycle issues: %v", err), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	capacities, err := st.capacityRepo.ListByCycle(cycle.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list capacity: %v", err), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+
+	plan, err := analytics.PlanCapacity(cycle, issues, capacities, settings)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, nil, false
+	}
+	return plan, issues, true
+}
+
+// nextCycle returns the ID of the first cycle that starts after this one
+// and is not completed, or "" when there is none.
+func (st *stores) nextCycle(cycle *db.Cycle) (string, error) {
+	cycles, err := st.cycleRepo.List(cycle.WorkspaceID)
+	if err != nil {
+		return "", err
+	}
+
+	var next *db.Cycle
+	for _, c := range cycles {
+		if c.ID == cycle.ID || c.Status == "completed" || c.StartDate == nil || !c.StartDate.After(*cycle.StartDate) {
+			continue
+		}
+		if next == nil || c.StartDate.Before(*next.StartDate) {
+			next = c
+		}
+	}
+	if next == nil {
+		return "", nil
+	}
+	return next.ID, nil
+}
+
+// findCycle loads a cycle, writing a 404 or 500 response when it cannot.
+func (st *stores) findCycle(w http.ResponseWriter, id string) (*db.Cycle, bool) {
+	cycle, err := st.cycleRepo.GetByID(id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to get cycle: %v", err), http.StatusInternalServerError)
+		return nil, false
+	}
+	if cycle == nil {
+		http.Error(w, "cycle not found", http.StatusNotFound)
+		return nil, false
+	}
+	return cycle, true
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+func TestBalanceRefusedByWIPLimit(t *testing.T) {
+	s := newTestServer(t)
+	ws := &db.Workspace{ID: "ws", Name: "WIP"}
+	if err := s.workspaceRepo.Create(ws); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 11)
+	if err := s.cycleRepo.Create(&db.Cycle{ID: "cycle", WorkspaceID: ws.ID, Name: "Sprint", StartDate: &start, EndDate: &end}); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []*db.Capacity{
+		{CycleID: "cycle", MemberID: "alice", Points: 1},
+		{CycleID: "cycle", MemberID: "bob", Points: 10},
+	} {
+		if err := s.capacityRepo.Set(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, issue := range []*db.Issue{
+		{ID: "heavy", WorkspaceID: ws.ID, CycleID: "cycle", AssigneeID: "alice", Title: "Heavy", Status: "in_progress", Estimate: 3},
+		{ID: "light", WorkspaceID: ws.ID, CycleID: "cycle", AssigneeID: "bob", Title: "Light", Status: "in_progress", Estimate: 1},
+	} {
+		if err := s.issueRepo.Create(issue); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Balancing wants to hand alice's overflow to bob, who already has
+	// the one issue in progress the limit allows.
+	ws.Settings = `{"assigneeWipLimit":1,"enforceWipLimits":true}`
+	if err := s.workspaceRepo.Update(ws); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	s.mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/cycles/cycle/balance", nil))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status %d, want 409: %s", rec.Code, rec.Body)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, "reassign") || !strings.Contains(body, "to bob") {
+		t.Errorf("response does not name the refused suggestion: %s", body)
+	}
+	heavy, err := s.issueRepo.GetByID("heavy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if heavy.AssigneeID != "alice" {
+		t.Errorf("refused balance reassigned the issue to %q", heavy.AssigneeID)
+	}
+}
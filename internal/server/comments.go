@@ -0,0 +1,70 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+// handleIssueComments lists and adds comments on an issue.
+func (s *Server) handleIssueComments(w http.ResponseWriter, r *http.Request) {
+	st := s.storesFor(r)
+	issue, err := st.findIssue(r.PathValue("id"), r.URL.Query().Get("workspace_id"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to get issue: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if issue == nil {
+		http.Error(w, "issue not found", http.StatusNotFound)
+		return
+	}
+	id := issue.ID
+
+	switch r.Method {
+	case http.MethodGet:
+		comments, err := st.commentRepo.ListByIssue(id)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to list comments: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if comments == nil {
+			comments = []*db.Comment{}
+		}
+		jsonResponse(w, comments)
+
+	case http.MethodPost:
+		var req struct {
+			AuthorID string `json:"author_id"`
+			Body     string `json:"body"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+		if strings.TrimSpace(req.Body) == "" {
+			http.Error(w, "comment body is required", http.StatusBadRequest)
+			return
+		}
+
+		comment := &db.Comment{
+			ID:       fmt.Sprintf("comment_%d", time.Now().UnixNano()),
+			IssueID:  id,
+			AuthorID: req.AuthorID,
+			Body:     req.Body,
+		}
+		if err := st.commentRepo.Create(comment); err != nil {
+			http.Error(w, fmt.Sprintf("failed to create comment: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		s.events.publish(Event{Type: EventCommentCreated, WorkspaceID: issue.WorkspaceID, IssueID: id, Comment: comment})
+		jsonResponse(w, comment)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
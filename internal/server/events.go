@@ -0,0 +1,123 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+// Event types published on the event stream.
+const (
+	EventIssueCreated   = "issue.created"
+	EventIssueUpdated   = "issue.updated"
+	EventIssueDeleted   = "issue.deleted"
+	EventCommentCreated = "comment.created"
+)
+
+// Event notifies stream subscribers of a change in a workspace.
+type Event struct {
+	Type        string      `json:"type"`
+	WorkspaceID string      `json:"workspace_id"`
+	IssueID     string      `json:"issue_id"`
+	Issue       *db.Issue   `json:"issue,omitempty"`
+	Comment     *db.Comment `json:"comment,omitempty"`
+	Time        time.Time   `json:"time"`
+}
+
+// eventBroker fans events out to stream subscribers. Slow subscribers
+// miss events rather than blocking the request that published them.
+type eventBroker struct {
+	mu   sync.Mutex
+	subs map[chan Event]string
+}
+
+func newEventBroker() *eventBroker {
+	return &eventBroker{subs: make(map[chan Event]string)}
+}
+
+// subscribe registers a subscriber for one workspace, or all workspaces
+// when workspaceID is empty.
+func (b *eventBroker) subscribe(workspaceID string) chan Event {
+	ch := make(chan Event, 64)
+	b.mu.Lock()
+	b.subs[ch] = workspaceID
+	b.mu.Unlock()
+	return ch
+}
+
+func (b *eventBroker) unsubscribe(ch chan Event) {
+	b.mu.Lock()
+	delete(b.subs, ch)
+	b.mu.Unlock()
+}
+
+func (b *eventBroker) publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch, workspaceID := range b.subs {
+		if workspaceID != "" && workspaceID != e.WorkspaceID {
+			continue
+		}
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// publishIssue announces a change to an issue.
+func (s *Server) publishIssue(eventType string, issue *db.Issue) {
+	s.events.publish(Event{Type: eventType, WorkspaceID: issue.WorkspaceID, IssueID: issue.ID, Issue: issue})
+}
+
+// handleEvents streams workspace changes as server-sent events. Each event
+// is sent with its type as the SSE event name and the Event as JSON data.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	ch := s.events.subscribe(r.URL.Query().Get("workspace_id"))
+	defer s.events.unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(25 * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case e := <-ch:
+			data, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pulse/pm/internal/archive"
+)
+
+// handleWorkspaceExport streams a workspace archive as a JSON download.
+func (s *Server) handleWorkspaceExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	st := s.storesFor(r)
+	id := r.PathValue("id")
+
+	ws, err := st.workspaceRepo.GetByID(id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to get workspace: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if ws == nil {
+		http.Error(w, "workspace not found", http.StatusNotFound)
+		return
+	}
+
+	a, err := archive.Export(st.db, id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to export workspace: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	filename := fmt.Sprintf("pulse-%s-%s.json", id, time.Now().UTC().Format("20060102"))
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	archive.Write(w, a)
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pulse/pm/internal/analytics"
+	"github.com/pulse/pm/internal/db"
+	"github.com/pulse/pm/internal/report"
+)
+
+// defaultFlowDays is the range of the flow metrics when from= is not set.
+const defaultFlowDays = 30
+
+// maxFlowDays bounds the range of the flow metrics, which replay history
+// for every day.
+const maxFlowDays = 366
+
+// handleCFD returns the daily count of issues in each status between
+// from= and to= (default the last 30 days), replayed from status history.
+// format=csv or md lists one row per day.
+func (s *Server) handleCFD(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	st := s.storesFor(r)
+	format, ok := tabularFormat(w, r)
+	if !ok {
+		return
+	}
+	from, to, ok := flowRange(w, r)
+	if !ok {
+		return
+	}
+	issues, history, ok := st.loadHistory(w, workspaceParam(r))
+	if !ok {
+		return
+	}
+
+	cfd := analytics.CalculateCFD(issues, history, from, to, time.Now())
+	if format != "" {
+		w.Header().Set("Content-Type", report.ContentType(format))
+		report.Write(w, format, report.CFDTable(cfd))
+		return
+	}
+	jsonResponse(w, cfd)
+}
+
+// handleThroughput returns the issues and points completed per
+// interval=day or week (default week) between from= and to=.
+// format=csv or md lists one row per period.
+func (s *Server) handleThroughput(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	st := s.storesFor(r)
+	interval := r.URL.Query().Get("interval")
+	switch interval {
+	case "":
+		interval = analytics.IntervalWeek
+	case analytics.IntervalDay, analytics.IntervalWeek:
+	default:
+		http.Error(w, fmt.Sprintf("invalid interval %q (use day or week)", interval), http.StatusBadRequest)
+		return
+	}
+
+	format, ok := tabularFormat(w, r)
+	if !ok {
+		return
+	}
+	from, to, ok := flowRange(w, r)
+	if !ok {
+		return
+	}
+	issues, history, ok := st.loadHistory(w, workspaceParam(r))
+	if !ok {
+		return
+	}
+
+	series := analytics.CalculateThroughput(issues, history, interval, from, to)
+	if format != "" {
+		w.Header().Set("Content-Type", report.ContentType(format))
+		report.Write(w, format, report.ThroughputTable(series))
+		return
+	}
+	jsonResponse(w, series)
+}
+
+// flowRange reads the from= and to= dates of a flow metric, writing an
+// error response when they are invalid.
+func flowRange(w http.ResponseWriter, r *http.Request) (from, to time.Time, ok bool) {
+	to = time.Now().UTC()
+	if v := r.URL.Query().Get("to"); v != "" {
+		t, err := parseDate(v)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("to: %v", err), http.StatusBadRequest)
+			return from, to, false
+		}
+		to = t
+	}
+	from = to.AddDate(0, 0, -(defaultFlowDays - 1))
+	if v := r.URL.Query().Get("from"); v != "" {
+		t, err := parseDate(v)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("from: %v", err), http.StatusBadRequest)
+			return from, to, false
+		}
+		from = t
+	}
+
+	if from.After(to) {
+		http.Error(w, "from must not be after to", http.StatusBadRequest)
+		return from, to, false
+	}
+	if to.Sub(from) > maxFlowDays*24*time.Hour {
+		http.Error(w, fmt.Sprintf("date range must be at most %d days", maxFlowDays), http.StatusBadRequest)
+		return from, to, false
+	}
+	return from, to, true
+}
+
+// handleCycleBurndown returns the daily remaining and completed points and
+// issues of a cycle, replayed from history, with the issues added,
+// removed, canceled or re-estimated after its first day. format=csv or md
+// lists one row per day.
+func (s *Server) handleCycleBurndown(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	st := s.storesFor(r)
+	format, ok := tabularFormat(w, r)
+	if !ok {
+		return
+	}
+
+	cycle, ok := st.findCycle(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	issues, history, ok := st.loadHistory(w, cycle.WorkspaceID)
+	if !ok {
+		return
+	}
+
+	b, err := analytics.CalculateBurndown(cycle, issues, history, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if format != "" {
+		w.Header().Set("Content-Type", report.ContentType(format))
+		report.Write(w, format, report.BurndownTable(b))
+		return
+	}
+	jsonResponse(w, b)
+}
+
+// workspaceParam returns the workspace_id= query parameter, defaulting to
+// the default workspace.
+func workspaceParam(r *http.Request) string {
+	if id := r.URL.Query().Get("workspace_id"); id != "" {
+		return id
+	}
+	return "default"
+}
+
+// loadHistory reads the issues of a workspace with their recorded history,
+// writing an error response when it cannot.
+func (st *stores) loadHistory(w http.ResponseWriter, workspaceID string) ([]*db.Issue, *analytics.History, bool) {
+	issues, err := st.issueRepo.List(workspaceID, "", 0, 0)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list issues: %v", err), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	events, err := db.NewEventRepository(st.db).ListByWorkspace(workspaceID, time.Time{})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list history: %v", err), http.StatusInternalServerError)
+		return nil, nil, false
+	}
+
+	return issues, analytics.NewHistory(events), true
+}
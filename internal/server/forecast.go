@@ -0,0 +1,140 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pulse/pm/internal/analytics"
+	"github.com/pulse/pm/internal/db"
+)
+
+// maxForecastSimulations bounds the work of a single forecast request.
+const maxForecastSimulations = 100000
+
+// handleForecast forecasts when the open issues of a project (project_id=),
+// milestone (milestone_id=), search (q=) or whole workspace will be done,
+// by Monte Carlo simulation over the workspace's weekly throughput in the
+// last history_weeks= weeks (default 12). date= also forecasts how many
+// will be done by that date. simulations= (default 10000) and seed=
+// (default 1) control the simulation; a given seed always gives the same
+// result.
+func (s *Server) handleForecast(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	st := s.storesFor(r)
+	query := r.URL.Query()
+	simulations, ok := positiveParam(w, r, "simulations", analytics.DefaultForecastSimulations)
+	if !ok {
+		return
+	}
+	if simulations > maxForecastSimulations {
+		http.Error(w, fmt.Sprintf("simulations must be at most %d", maxForecastSimulations), http.StatusBadRequest)
+		return
+	}
+	historyWeeks, ok := positiveParam(w, r, "history_weeks", analytics.DefaultForecastHistory)
+	if !ok {
+		return
+	}
+
+	seed := int64(analytics.DefaultForecastSeed)
+	if v := query.Get("seed"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			http.Error(w, "seed must be an integer", http.StatusBadRequest)
+			return
+		}
+		seed = n
+	}
+
+	var target *time.Time
+	if v := query.Get("date"); v != "" {
+		t, err := parseDate(v)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("date: %v", err), http.StatusBadRequest)
+			return
+		}
+		target = &t
+	}
+
+	workspaceID := workspaceParam(r)
+	var scope []*db.Issue
+	var err error
+	switch {
+	case query.Get("project_id") != "":
+		project, ok := st.findProject(w, query.Get("project_id"))
+		if !ok {
+			return
+		}
+		workspaceID = project.WorkspaceID
+		scope, err = st.issueRepo.ListByProject(project.ID)
+	case query.Get("milestone_id") != "":
+		milestone, ok := st.findMilestone(w, query.Get("milestone_id"))
+		if !ok {
+			return
+		}
+		project, ok := st.findProject(w, milestone.ProjectID)
+		if !ok {
+			return
+		}
+		workspaceID = project.WorkspaceID
+		scope, err = st.issueRepo.ListByMilestone(milestone.ID)
+	case query.Get("q") != "":
+		scope, err = st.searchIssues(workspaceID, query.Get("q"), query)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to search issues: %v", err), searchErrorStatus(err))
+			return
+		}
+	default:
+		scope, err = st.issueRepo.List(workspaceID, "", 0, 0)
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list issues: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	remaining := 0
+	for _, issue := range scope {
+		if issue.Status != "done" && issue.Status != "canceled" {
+			remaining++
+		}
+	}
+
+	issues, history, ok := st.loadHistory(w, workspaceID)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	throughput := analytics.WeeklyThroughput(issues, history, historyWeeks, now)
+	forecast, err := analytics.RunForecast(throughput, remaining, simulations, seed, now, target)
+	if errors.Is(err, analytics.ErrNoThroughput) {
+		http.Error(w, fmt.Sprintf("cannot forecast: %v in the last %d weeks", err, historyWeeks), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to forecast: %v", err), http.StatusInternalServerError)
+		return
+	}
+	jsonResponse(w, forecast)
+}
+
+// positiveParam reads an optional positive integer query parameter,
+// writing an error response when it is invalid.
+func positiveParam(w http.ResponseWriter, r *http.Request, name string, def int) (int, bool) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		http.Error(w, fmt.Sprintf("%s must be a positive integer", name), http.StatusBadRequest)
+		return 0, false
+	}
+	return n, true
+}
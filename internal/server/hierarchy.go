@@ -0,0 +1,127 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+// issueErrorStatus maps an issue write error to an HTTP status.
+func issueErrorStatus(err error) int {
+	if errors.Is(err, db.ErrInvalidParent) || errors.Is(err, db.ErrInvalidProject) {
+		return http.StatusBadRequest
+	}
+	if errors.Is(err, db.ErrWIPLimit) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// deleteIssue deletes an issue. With children=cascade its sub-issues are
+// deleted too; by default they move up to the deleted issue's parent.
+func (s *Server) deleteIssue(w http.ResponseWriter, r *http.Request, issue *db.Issue) {
+	switch mode := r.URL.Query().Get("children"); mode {
+	case "", "reparent":
+		children, err := s.issueRepo.ListChildren(issue.ID)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to delete issue: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if err := s.issueRepo.Delete(issue.ID); err != nil {
+			http.Error(w, fmt.Sprintf("failed to delete issue: %v", err), http.StatusInternalServerError)
+			return
+		}
+		s.publishIssue(EventIssueDeleted, issue)
+		for _, child := range children {
+			child.ParentID = issue.ParentID
+			s.publishIssue(EventIssueUpdated, child)
+		}
+
+	case "cascade":
+		deleted, err := s.issueRepo.DeleteTree(issue.ID)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to delete issue: %v", err), http.StatusInternalServerError)
+			return
+		}
+		for _, id := range deleted {
+			s.events.publish(Event{Type: EventIssueDeleted, WorkspaceID: issue.WorkspaceID, IssueID: id})
+		}
+
+	default:
+		http.Error(w, fmt.Sprintf("invalid children mode %q (use reparent or cascade)", mode), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleIssueChildren lists the direct sub-issues of an issue.
+func (s *Server) handleIssueChildren(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	st := s.storesFor(r)
+	issue, err := st.findIssue(r.PathValue("id"), r.URL.Query().Get("workspace_id"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to get issue: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if issue == nil {
+		http.Error(w, "issue not found", http.StatusNotFound)
+		return
+	}
+
+	children, err := st.issueRepo.ListChildren(issue.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list sub-issues: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if children == nil {
+		children = []*db.Issue{}
+	}
+	jsonResponse(w, children)
+}
+
+// handleIssueTree returns an issue with its sub-issues nested to the
+// requested depth (default: all levels) and rolled-up progress at every
+// level.
+func (s *Server) handleIssueTree(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	st := s.storesFor(r)
+	depth := 0
+	if v := r.URL.Query().Get("depth"); v != "" {
+		d, err := strconv.Atoi(v)
+		if err != nil || d < 0 {
+			http.Error(w, "depth must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		depth = d
+	}
+
+	issue, err := st.findIssue(r.PathValue("id"), r.URL.Query().Get("workspace_id"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to get issue: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if issue == nil {
+		http.Error(w, "issue not found", http.StatusNotFound)
+		return
+	}
+
+	issues, err := st.issueRepo.List(issue.WorkspaceID, "", 0, 0)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list issues: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	jsonResponse(w, db.BuildTree(issue, issues, depth))
+}
@@ -0,0 +1,454 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pulse/pm/internal/analytics"
+	"github.com/pulse/pm/internal/db"
+)
+
+// parseDate accepts an RFC 3339 timestamp or a plain YYYY-MM-DD date.
+func parseDate(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (use YYYY-MM-DD or RFC 3339)", s)
+	}
+	return t, nil
+}
+
+// optionalDate reads a date field from a decoded JSON body. ok is false
+// when the field is absent; an explicit null or "" clears the date.
+func optionalDate(req map[string]interface{}, field string) (date *time.Time, ok bool, err error) {
+	v, present := req[field]
+	if !present {
+		return nil, false, nil
+	}
+	s, _ := v.(string)
+	if s == "" {
+		return nil, true, nil
+	}
+	t, err := parseDate(s)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", field, err)
+	}
+	return &t, true, nil
+}
+
+// handleProjects lists and creates projects in a workspace.
+func (s *Server) handleProjects(w http.ResponseWriter, r *http.Request) {
+	st := s.storesFor(r)
+	switch r.Method {
+	case http.MethodGet:
+		projects, err := st.projectRepo.List(r.URL.Query().Get("workspace_id"))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to list projects: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if projects == nil {
+			projects = []*db.Project{}
+		}
+		jsonResponse(w, projects)
+
+	case http.MethodPost:
+		var req map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		workspaceID, _ := req["workspace_id"].(string)
+		ws, err := st.workspaceRepo.GetByID(workspaceID)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to verify workspace: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if ws == nil {
+			http.Error(w, "workspace not found", http.StatusNotFound)
+			return
+		}
+
+		project := &db.Project{
+			ID:          fmt.Sprintf("project_%d", time.Now().UnixNano()),
+			WorkspaceID: workspaceID,
+			Status:      "planned",
+		}
+		if err := applyProjectFields(project, req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if project.Name == "" {
+			http.Error(w, "project name is required", http.StatusBadRequest)
+			return
+		}
+
+		if err := st.projectRepo.Create(project); err != nil {
+			http.Error(w, fmt.Sprintf("failed to create project: %v", err), http.StatusInternalServerError)
+			return
+		}
+		jsonResponse(w, project)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleProject reads, updates and deletes a project. Deleting a project
+// removes its milestones and detaches its issues.
+func (s *Server) handleProject(w http.ResponseWriter, r *http.Request) {
+	st := s.storesFor(r)
+	project, ok := st.findProject(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		jsonResponse(w, project)
+
+	case http.MethodPut:
+		var req map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+		if err := applyProjectFields(project, req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := st.projectRepo.Update(project); err != nil {
+			http.Error(w, fmt.Sprintf("failed to update project: %v", err), http.StatusInternalServerError)
+			return
+		}
+		jsonResponse(w, project)
+
+	case http.MethodDelete:
+		if err := st.projectRepo.Delete(project.ID); err != nil {
+			http.Error(w, fmt.Sprintf("failed to delete project: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// applyProjectFields copies the editable fields present in req onto p.
+func applyProjectFields(p *db.Project, req map[string]interface{}) error {
+	if name, ok := req["name"].(string); ok {
+		p.Name = name
+	}
+	if desc, ok := req["description"].(string); ok {
+		p.Description = desc
+	}
+	if lead, ok := req["lead_id"].(string); ok {
+		p.LeadID = lead
+	}
+	if status, ok := req["status"].(string); ok {
+		if !db.ValidProjectStatus(status) {
+			return fmt.Errorf("invalid project status %q", status)
+		}
+		p.Status = status
+	}
+	date, ok, err := optionalDate(req, "target_date")
+	if err != nil {
+		return err
+	}
+	if ok {
+		p.TargetDate = date
+	}
+	return nil
+}
+
+// findProject loads a project, writing a 404 or 500 response when it
+// cannot.
+func (st *stores) findProject(w http.ResponseWriter, id string) (*db.Project, bool) {
+	project, err := st.projectRepo.GetByID(id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to get project: %v", err), http.StatusInternalServerError)
+		return nil, false
+	}
+	if project == nil {
+		http.Error(w, "project not found", http.StatusNotFound)
+		return nil, false
+	}
+	return project, true
+}
+
+// handleProjectIssues lists the issues in a project as JSON, CSV or
+// Markdown.
+func (s *Server) handleProjectIssues(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	st := s.storesFor(r)
+	format, ok := tabularFormat(w, r)
+	if !ok {
+		return
+	}
+	project, ok := st.findProject(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	issues, err := st.issueRepo.ListByProject(project.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list issues: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if format != "" {
+		writeIssueTable(w, r, format, issues)
+		return
+	}
+	if issues == nil {
+		issues = []*db.Issue{}
+	}
+	jsonResponse(w, issues)
+}
+
+// handleProjectMilestones lists and creates the milestones of a project.
+func (s *Server) handleProjectMilestones(w http.ResponseWriter, r *http.Request) {
+	st := s.storesFor(r)
+	project, ok := st.findProject(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		milestones, err := st.milestoneRepo.ListByProject(project.ID)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to list milestones: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if milestones == nil {
+			milestones = []*db.Milestone{}
+		}
+		jsonResponse(w, milestones)
+
+	case http.MethodPost:
+		var req map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		milestone := &db.Milestone{
+			ID:        fmt.Sprintf("milestone_%d", time.Now().UnixNano()),
+			ProjectID: project.ID,
+		}
+		if err := applyMilestoneFields(milestone, req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if milestone.Name == "" {
+			http.Error(w, "milestone name is required", http.StatusBadRequest)
+			return
+		}
+
+		if err := st.milestoneRepo.Create(milestone); err != nil {
+			http.Error(w, fmt.Sprintf("failed to create milestone: %v", err), http.StatusInternalServerError)
+			return
+		}
+		jsonResponse(w, milestone)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleMilestone reads, updates and deletes a milestone. Deleting a
+// milestone leaves its issues in the project.
+func (s *Server) handleMilestone(w http.ResponseWriter, r *http.Request) {
+	st := s.storesFor(r)
+	milestone, ok := st.findMilestone(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		jsonResponse(w, milestone)
+
+	case http.MethodPut:
+		var req map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+		if err := applyMilestoneFields(milestone, req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := st.milestoneRepo.Update(milestone); err != nil {
+			http.Error(w, fmt.Sprintf("failed to update milestone: %v", err), http.StatusInternalServerError)
+			return
+		}
+		jsonResponse(w, milestone)
+
+	case http.MethodDelete:
+		if err := st.milestoneRepo.Delete(milestone.ID); err != nil {
+			http.Error(w, fmt.Sprintf("failed to delete milestone: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// applyMilestoneFields copies the editable fields present in req onto m.
+func applyMilestoneFields(m *db.Milestone, req map[string]interface{}) error {
+	if name, ok := req["name"].(string); ok {
+		m.Name = name
+	}
+	if desc, ok := req["description"].(string); ok {
+		m.Description = desc
+	}
+	date, ok, err := optionalDate(req, "target_date")
+	if err != nil {
+		return err
+	}
+	if ok {
+		m.TargetDate = date
+	}
+	return nil
+}
+
+// findMilestone loads a milestone, writing a 404 or 500 response when it
+// cannot.
+func (st *stores) findMilestone(w http.ResponseWriter, id string) (*db.Milestone, bool) {
+	milestone, err := st.milestoneRepo.GetByID(id)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to get milestone: %v", err), http.StatusInternalServerError)
+		return nil, false
+	}
+	if milestone == nil {
+		http.Error(w, "milestone not found", http.StatusNotFound)
+		return nil, false
+	}
+	return milestone, true
+}
+
+// MilestoneProgress is the progress of one milestone within a project.
+type MilestoneProgress struct {
+	*db.Milestone
+	Progress *analytics.Progress `json:"progress"`
+}
+
+// ProjectProgress is the progress of a project and each of its
+// milestones.
+type ProjectProgress struct {
+	Project    *db.Project          `json:"project"`
+	Progress   *analytics.Progress  `json:"progress"`
+	Milestones []*MilestoneProgress `json:"milestones"`
+}
+
+// throughputWindow reads window_days= (default 28), writing a 400 response
+// when it is invalid.
+func throughputWindow(w http.ResponseWriter, r *http.Request) (time.Duration, bool) {
+	v := r.URL.Query().Get("window_days")
+	if v == "" {
+		return analytics.DefaultThroughputWindow, true
+	}
+	days, err := strconv.Atoi(v)
+	if err != nil || days <= 0 {
+		http.Error(w, "window_days must be a positive integer", http.StatusBadRequest)
+		return 0, false
+	}
+	return time.Duration(days) * 24 * time.Hour, true
+}
+
+// handleProjectProgress reports issue and point completion for a project
+// and its milestones, with completion dates projected from the throughput
+// over the last window_days.
+func (s *Server) handleProjectProgress(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	st := s.storesFor(r)
+	window, ok := throughputWindow(w, r)
+	if !ok {
+		return
+	}
+	project, ok := st.findProject(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	issues, err := st.issueRepo.ListByProject(project.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list issues: %v", err), http.StatusInternalServerError)
+		return
+	}
+	milestones, err := st.milestoneRepo.ListByProject(project.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list milestones: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	byMilestone := make(map[string][]*db.Issue)
+	for _, issue := range issues {
+		if issue.MilestoneID != "" {
+			byMilestone[issue.MilestoneID] = append(byMilestone[issue.MilestoneID], issue)
+		}
+	}
+
+	result := &ProjectProgress{
+		Project:    project,
+		Progress:   analytics.CalculateProgress(issues, project.TargetDate, now, window),
+		Milestones: make([]*MilestoneProgress, 0, len(milestones)),
+	}
+	for _, m := range milestones {
+		result.Milestones = append(result.Milestones, &MilestoneProgress{
+			Milestone: m,
+			Progress:  analytics.CalculateProgress(byMilestone[m.ID], m.TargetDate, now, window),
+		})
+	}
+
+	jsonResponse(w, result)
+}
+
+// handleMilestoneProgress reports progress for a single milestone.
+func (s *Server) handleMilestoneProgress(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	st := s.storesFor(r)
+	window, ok := throughputWindow(w, r)
+	if !ok {
+		return
+	}
+	milestone, ok := st.findMilestone(w, r.PathValue("id"))
+	if !ok {
+		return
+	}
+
+	issues, err := st.issueRepo.ListByMilestone(milestone.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list issues: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	jsonResponse(w, &MilestoneProgress{
+		Milestone: milestone,
+		Progress:  analytics.CalculateProgress(issues, milestone.TargetDate, time.Now(), window),
+	})
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pulse/pm/internal/analytics"
+	"github.com/pulse/pm/internal/db"
+)
+
+// handleQualityMetrics reports bug rate, reopen rate and escaped bugs for
+// a workspace overall, per label and per cycle over the last cycles=
+// cycles (default 6). Bugs and escaped bugs are identified by the
+// workspace's bugLabel and escapedLabel settings.
+func (s *Server) handleQualityMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	st := s.storesFor(r)
+	lastCycles := analytics.DefaultQualityCycles
+	if v := r.URL.Query().Get("cycles"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, "cycles must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		lastCycles = n
+	}
+
+	workspaceID := r.URL.Query().Get("workspace_id")
+	if workspaceID == "" {
+		workspaceID = "default"
+	}
+	settings, ok := st.workspaceSettings(w, workspaceID)
+	if !ok {
+		return
+	}
+
+	issues, err := st.issueRepo.List(workspaceID, "", 0, 0)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list issues: %v", err), http.StatusInternalServerError)
+		return
+	}
+	cycles, err := st.cycleRepo.List(workspaceID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list cycles: %v", err), http.StatusInternalServerError)
+		return
+	}
+	events, err := db.NewEventRepository(st.db).ListByWorkspace(workspaceID, time.Time{})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list history: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	jsonResponse(w, analytics.CalculateQuality(issues, analytics.NewHistory(events), cycles,
+		settings.BugLabelName(), settings.EscapedLabelName(), lastCycles))
+}
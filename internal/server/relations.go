@@ -0,0 +1,116 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+// relationBlockedBy is accepted when creating a relation as the reverse
+// of blocks; it is stored as a blocks relation from the other issue.
+const relationBlockedBy = "blocked_by"
+
+// handleIssueRelations lists and creates relations of an issue.
+func (s *Server) handleIssueRelations(w http.ResponseWriter, r *http.Request) {
+	st := s.storesFor(r)
+	workspaceID := r.URL.Query().Get("workspace_id")
+	issue, err := st.findIssue(r.PathValue("id"), workspaceID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to get issue: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if issue == nil {
+		http.Error(w, "issue not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		relations, err := st.relationRepo.ListByIssue(issue.ID)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to list relations: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if relations == nil {
+			relations = []*db.Relation{}
+		}
+		jsonResponse(w, relations)
+
+	case http.MethodPost:
+		var req struct {
+			RelatedIssueID string `json:"related_issue_id"`
+			Type           string `json:"type"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request", http.StatusBadRequest)
+			return
+		}
+
+		related, err := st.findIssue(req.RelatedIssueID, issue.WorkspaceID)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to get related issue: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if related == nil {
+			http.Error(w, "related issue not found", http.StatusBadRequest)
+			return
+		}
+
+		rel := &db.Relation{
+			ID:             fmt.Sprintf("rel_%d", time.Now().UnixNano()),
+			IssueID:        issue.ID,
+			RelatedIssueID: related.ID,
+			Type:           req.Type,
+		}
+		if req.Type == relationBlockedBy {
+			rel.IssueID, rel.RelatedIssueID = related.ID, issue.ID
+			rel.Type = db.RelationBlocks
+		}
+
+		if err := st.relationRepo.Create(rel); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, db.ErrInvalidRelation) {
+				status = http.StatusBadRequest
+			}
+			http.Error(w, fmt.Sprintf("failed to create relation: %v", err), status)
+			return
+		}
+		jsonResponse(w, rel)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleRelation reads and deletes a single relation.
+func (s *Server) handleRelation(w http.ResponseWriter, r *http.Request) {
+	st := s.storesFor(r)
+	rel, err := st.relationRepo.GetByID(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to get relation: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if rel == nil {
+		http.Error(w, "relation not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		jsonResponse(w, rel)
+
+	case http.MethodDelete:
+		if err := st.relationRepo.Delete(rel.ID); err != nil {
+			http.Error(w, fmt.Sprintf("failed to delete relation: %v", err), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/pulse/pm/internal/db"
+	"github.com/pulse/pm/internal/report"
+)
+
+// tabularFormat returns the format= query parameter when it selects CSV or
+// Markdown output, or "" for the default JSON response. An unsupported
+// value is reported to the client and ok is false.
+func tabularFormat(w http.ResponseWriter, r *http.Request) (format string, ok bool) {
+	format = r.URL.Query().Get("format")
+	switch format {
+	case "", report.FormatJSON:
+		return "", true
+	case report.FormatCSV, report.FormatMarkdown:
+		return format, true
+	default:
+		http.Error(w, fmt.Sprintf("unsupported format %q", format), http.StatusBadRequest)
+		return "", false
+	}
+}
+
+// writeIssueTable renders issues in the requested format using the
+// columns= query parameter.
+func writeIssueTable(w http.ResponseWriter, r *http.Request, format string, issues []*db.Issue) {
+	columns, err := report.ParseColumns(r.URL.Query().Get("columns"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", report.ContentType(format))
+	report.Write(w, format, report.IssueTable(issues, columns))
+}
+
+// handleCycleReport summarises a cycle as JSON, CSV or Markdown.
+func (s *Server) handleCycleReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	st := s.storesFor(r)
+	format, ok := tabularFormat(w, r)
+	if !ok {
+		return
+	}
+	columns, err := report.ParseColumns(r.URL.Query().Get("columns"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cycle, err := st.cycleRepo.GetByID(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to get cycle: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if cycle == nil {
+		http.Error(w, "cycle not found", http.StatusNotFound)
+		return
+	}
+
+	issues, err := st.issueRepo.ListByCycle(cycle.ID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list cycle issues: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	rep := report.BuildCycleReport(cycle, issues)
+	if format == "" {
+		jsonResponse(w, rep)
+		return
+	}
+
+	w.Header().Set("Content-Type", report.ContentType(format))
+	rep.Write(w, format, columns)
+}
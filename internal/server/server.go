@@ -4,34 +4,85 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/pulse/pm/internal/analytics"
 	"github.com/pulse/pm/internal/db"
+	"github.com/pulse/pm/internal/telemetry"
 )
 
 // Server represents the Pulse web server
 type Server struct {
-	addr             string
-	mux              *http.ServeMux
-	server           *http.Server
-	db               *db.DB
-	workspaceRepo    *db.WorkspaceRepository
-	issueRepo        *db.IssueRepository
-	cycleRepo        *db.CycleRepository
+	*stores
+
+	addr      string
+	mux       *http.ServeMux
+	server    *http.Server
+	events    *eventBroker
+	telemetry *serverMetrics
+	logger    *slog.Logger
+	tracer    trace.Tracer
+	tracing   bool
+
+	snapshotDir      string
+	snapshotInterval time.Duration
+	snapshotRetain   int
+	adminToken       string
+
+	breachCheckInterval time.Duration
+	autoCloseInterval   time.Duration
+}
+
+// Config holds the settings used to construct a Server.
+type Config struct {
+	// Addr is the address to listen on.
+	Addr string
+	// DataDir holds the SQLite database and other local state.
+	DataDir string
+	// DatabaseURL selects a PostgreSQL database instead of SQLite.
+	DatabaseURL string
+	// SnapshotDir is where backups are written; defaults to
+	// DataDir/snapshots.
+	SnapshotDir string
+	// SnapshotInterval enables scheduled snapshots when non-zero.
+	SnapshotInterval time.Duration
+	// SnapshotRetain is how many snapshots to keep; zero keeps all.
+	SnapshotRetain int
+	// AdminToken enables the /api/admin routes for requests that present
+	// it as a bearer token. The routes answer 404 when it is empty.
+	AdminToken string
+	// BreachCheckInterval is how often missed deadlines are recorded;
+	// defaults to DefaultBreachCheckInterval, negative disables.
+	BreachCheckInterval time.Duration
+	// AutoCloseInterval is how often stale backlog issues are canceled in
+	// workspaces that set autoCloseDays; defaults to
+	// DefaultAutoCloseInterval, negative disables.
+	AutoCloseInterval time.Duration
+	// Logger receives access logs and server events; defaults to
+	// slog.Default().
+	Logger *slog.Logger
+	// TracerProvider, when set, traces each request and the database
+	// statements it runs.
+	TracerProvider trace.TracerProvider
 }
 
 // NewServer creates a new Pulse server
-func NewServer(addr, dataDir string) (*Server, error) {
+func NewServer(cfg Config) (*Server, error) {
 	// Ensure data directory exists
-	if err := os.MkdirAll(dataDir, 0755); err != nil {
+	if err := os.MkdirAll(cfg.DataDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create data dir: %w", err)
 	}
 
-	database, err := db.New(dataDir)
+	database, err := db.Open(cfg.DataDir, cfg.DatabaseURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -40,29 +91,129 @@ func NewServer(addr, dataDir string) (*Server, error) {
 		return nil, fmt.Errorf("failed to run migrations: %w", err)
 	}
 
+	snapshotDir := cfg.SnapshotDir
+	if snapshotDir == "" {
+		snapshotDir = filepath.Join(cfg.DataDir, "snapshots")
+	}
+
 	s := &Server{
-		addr:             addr,
+		addr:             cfg.Addr,
 		mux:              http.NewServeMux(),
-		db:               database,
-		workspaceRepo:    db.NewWorkspaceRepository(database),
-		issueRepo:        db.NewIssueRepository(database),
-		cycleRepo:        db.NewCycleRepository(database),
+		events:           newEventBroker(),
+		logger:           cfg.Logger,
+		tracing:          cfg.TracerProvider != nil,
+		snapshotDir:      snapshotDir,
+		snapshotInterval: cfg.SnapshotInterval,
+		snapshotRetain:   cfg.SnapshotRetain,
+		adminToken:       cfg.AdminToken,
+
+		breachCheckInterval: cfg.BreachCheckInterval,
+		autoCloseInterval:   cfg.AutoCloseInterval,
+	}
+	if s.breachCheckInterval == 0 {
+		s.breachCheckInterval = DefaultBreachCheckInterval
+	}
+	if s.autoCloseInterval == 0 {
+		s.autoCloseInterval = DefaultAutoCloseInterval
 	}
+	if s.logger == nil {
+		s.logger = slog.Default()
+	}
+	tp := cfg.TracerProvider
+	if tp == nil {
+		tp = noop.NewTracerProvider()
+	}
+	s.tracer = tp.Tracer(telemetry.Instrumentation)
+	s.telemetry = newServerMetrics(s.logger)
+	database.ObserveQueries(s.telemetry.observeQuery)
+	s.stores = newStores(database)
+	s.telemetry.registry.MustRegister(databaseCollector{s})
 	s.registerRoutes()
 	return s, nil
 }
 
+// stores are the repositories the server reads and writes through, all
+// over one database connection.
+type stores struct {
+	db            *db.DB
+	workspaceRepo db.WorkspaceStore
+	issueRepo     db.IssueStore
+	cycleRepo     db.CycleStore
+	commentRepo   db.CommentStore
+	relationRepo  db.RelationStore
+	projectRepo   db.ProjectStore
+	milestoneRepo db.MilestoneStore
+	capacityRepo  db.CapacityStore
+	metricsRepo   db.MetricsStore
+}
+
+func newStores(database *db.DB) *stores {
+	return &stores{
+		db:            database,
+		workspaceRepo: db.NewWorkspaceRepository(database),
+		issueRepo:     db.NewIssueRepository(database),
+		cycleRepo:     db.NewCycleRepository(database),
+		commentRepo:   db.NewCommentRepository(database),
+		relationRepo:  db.NewRelationRepository(database),
+		projectRepo:   db.NewProjectRepository(database),
+		milestoneRepo: db.NewMilestoneRepository(database),
+		capacityRepo:  db.NewCapacityRepository(database),
+		metricsRepo:   db.NewMetricsRepository(database),
+	}
+}
+
+// storesFor returns stores whose statements are bound to the request's
+// context, so they are canceled, traced and attributed along with it.
+// Background work uses the server's own stores.
+func (s *Server) storesFor(r *http.Request) *stores {
+	return newStores(s.db.WithContext(r.Context()))
+}
+
 func (s *Server) registerRoutes() {
 	// API routes
 	s.mux.HandleFunc("/api/health", s.handleHealth)
 	s.mux.HandleFunc("/api/workspaces", s.handleWorkspaces)
 	s.mux.HandleFunc("/api/workspaces/", s.handleWorkspace)
+	s.mux.HandleFunc("/api/workspaces/{id}/export", s.handleWorkspaceExport)
+	s.mux.HandleFunc("/api/workspaces/{id}/columns", s.handleWorkspaceColumns)
 	s.mux.HandleFunc("/api/issues", s.handleIssues)
 	s.mux.HandleFunc("/api/issues/", s.handleIssue)
+	s.mux.HandleFunc("/api/issues/bulk", s.handleBulkIssues)
+	s.mux.HandleFunc("/api/issues/{id}/comments", s.handleIssueComments)
+	s.mux.HandleFunc("/api/issues/{id}/children", s.handleIssueChildren)
+	s.mux.HandleFunc("/api/issues/{id}/tree", s.handleIssueTree)
+	s.mux.HandleFunc("/api/issues/{id}/relations", s.handleIssueRelations)
+	s.mux.HandleFunc("/api/relations/{id}", s.handleRelation)
 	s.mux.HandleFunc("/api/cycles", s.handleCycles)
 	s.mux.HandleFunc("/api/cycles/", s.handleCycle)
+	s.mux.HandleFunc("/api/cycles/{id}/report", s.handleCycleReport)
+	s.mux.HandleFunc("/api/cycles/{id}/burndown", s.handleCycleBurndown)
+	s.mux.HandleFunc("/api/cycles/{id}/capacity", s.handleCycleCapacity)
+	s.mux.HandleFunc("/api/cycles/{id}/capacity/{member}", s.handleMemberCapacity)
+	s.mux.HandleFunc("/api/cycles/{id}/plan", s.handleCyclePlan)
+	s.mux.HandleFunc("/api/cycles/{id}/balance", s.handleCycleBalance)
+	s.mux.HandleFunc("/api/projects", s.handleProjects)
+	s.mux.HandleFunc("/api/projects/{id}", s.handleProject)
+	s.mux.HandleFunc("/api/projects/{id}/issues", s.handleProjectIssues)
+	s.mux.HandleFunc("/api/projects/{id}/milestones", s.handleProjectMilestones)
+	s.mux.HandleFunc("/api/projects/{id}/progress", s.handleProjectProgress)
+	s.mux.HandleFunc("/api/milestones/{id}", s.handleMilestone)
+	s.mux.HandleFunc("/api/milestones/{id}/progress", s.handleMilestoneProgress)
+	s.mux.HandleFunc("/api/timeline", s.handleTimeline)
+	s.mux.HandleFunc("/metrics", s.handlePrometheus)
 	s.mux.HandleFunc("/api/metrics", s.handleMetrics)
+	s.mux.HandleFunc("/api/metrics/sla", s.handleSLAMetrics)
+	s.mux.HandleFunc("/api/metrics/aging", s.handleAgingMetrics)
+	s.mux.HandleFunc("/api/metrics/quality", s.handleQualityMetrics)
+	s.mux.HandleFunc("/api/metrics/wip", s.handleWIPMetrics)
+	s.mux.HandleFunc("/api/metrics/cfd", s.handleCFD)
+	s.mux.HandleFunc("/api/metrics/throughput", s.handleThroughput)
+	s.mux.HandleFunc("/api/forecast", s.handleForecast)
 	s.mux.HandleFunc("/api/search", s.handleSearch)
+	s.mux.HandleFunc("/api/events", s.handleEvents)
+	s.mux.HandleFunc("/api/admin/backup", s.requireAdmin(s.handleAdminBackup))
+	s.mux.HandleFunc("/api/admin/backups", s.requireAdmin(s.handleAdminBackups))
+	s.mux.HandleFunc("/api/admin/restore", s.requireAdmin(s.handleAdminRestore))
 
 	// Web UI
 	s.mux.HandleFunc("/", s.handleWebUI)
@@ -78,9 +229,10 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleWorkspaces(w http.ResponseWriter, r *http.Request) {
+	st := s.storesFor(r)
 	switch r.Method {
 	case http.MethodGet:
-		workspaces, err := s.workspaceRepo.List()
+		workspaces, err := st.workspaceRepo.List()
 		if err != nil {
 			http.Error(w, fmt.Sprintf("failed to list workspaces: %v", err), http.StatusInternalServerError)
 			return
@@ -105,7 +257,7 @@ func (s *Server) handleWorkspaces(w http.ResponseWriter, r *http.Request) {
 			Settings:    req.Settings,
 		}
 
-		if err := s.workspaceRepo.Create(ws); err != nil {
+		if err := st.workspaceRepo.Create(ws); err != nil {
 			http.Error(w, fmt.Sprintf("failed to create workspace: %v", err), http.StatusInternalServerError)
 			return
 		}
@@ -115,9 +267,10 @@ func (s *Server) handleWorkspaces(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleWorkspace(w http.ResponseWriter, r *http.Request) {
+	st := s.storesFor(r)
 	id := filepath.Base(r.URL.Path)
 
-	ws, err := s.workspaceRepo.GetByID(id)
+	ws, err := st.workspaceRepo.GetByID(id)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("failed to get workspace: %v", err), http.StatusInternalServerError)
 		return
@@ -148,7 +301,7 @@ func (s *Server) handleWorkspace(w http.ResponseWriter, r *http.Request) {
 			ws.Settings = settings
 		}
 
-		if err := s.workspaceRepo.Update(ws); err != nil {
+		if err := st.workspaceRepo.Update(ws); err != nil {
 			http.Error(w, fmt.Sprintf("failed to update workspace: %v", err), http.StatusInternalServerError)
 			return
 		}
@@ -156,7 +309,7 @@ func (s *Server) handleWorkspace(w http.ResponseWriter, r *http.Request) {
 		jsonResponse(w, ws)
 
 	case http.MethodDelete:
-		if err := s.workspaceRepo.Delete(id); err != nil {
+		if err := st.workspaceRepo.Delete(id); err != nil {
 			http.Error(w, fmt.Sprintf("failed to delete workspace: %v", err), http.StatusInternalServerError)
 			return
 		}
@@ -165,8 +318,14 @@ func (s *Server) handleWorkspace(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleIssues(w http.ResponseWriter, r *http.Request) {
+	st := s.storesFor(r)
 	switch r.Method {
 	case http.MethodGet:
+		format, ok := tabularFormat(w, r)
+		if !ok {
+			return
+		}
+
 		workspaceID := r.URL.Query().Get("workspace_id")
 		status := r.URL.Query().Get("status")
 
@@ -174,11 +333,15 @@ func (s *Server) handleIssues(w http.ResponseWriter, r *http.Request) {
 		fmt.Sscanf(r.URL.Query().Get("limit"), "%d", &limit)
 		fmt.Sscanf(r.URL.Query().Get("offset"), "%d", &offset)
 
-		issues, err := s.issueRepo.List(workspaceID, status, limit, offset)
+		issues, err := st.issueRepo.List(workspaceID, status, limit, offset)
 		if err != nil {
 			http.Error(w, fmt.Sprintf("failed to list issues: %v", err), http.StatusInternalServerError)
 			return
 		}
+		if format != "" {
+			writeIssueTable(w, r, format, issues)
+			return
+		}
 		jsonResponse(w, issues)
 
 	case http.MethodPost:
@@ -193,6 +356,11 @@ func (s *Server) handleIssues(w http.ResponseWriter, r *http.Request) {
 			Estimate    int      `json:"estimate"`
 			CycleID     string   `json:"cycle_id"`
 			ParentID    string   `json:"parent_id"`
+			ProjectID   string   `json:"project_id"`
+			MilestoneID string   `json:"milestone_id"`
+			StartDate   string   `json:"start_date"`
+			DueDate     string   `json:"due_date"`
+			Branch      string   `json:"branch"`
 		}
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			http.Error(w, "invalid request", http.StatusBadRequest)
@@ -200,7 +368,7 @@ func (s *Server) handleIssues(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Verify workspace exists
-		ws, err := s.workspaceRepo.GetByID(req.WorkspaceID)
+		ws, err := st.workspaceRepo.GetByID(req.WorkspaceID)
 		if err != nil {
 			http.Error(w, fmt.Sprintf("failed to verify workspace: %v", err), http.StatusInternalServerError)
 			return
@@ -226,21 +394,38 @@ func (s *Server) handleIssues(w http.ResponseWriter, r *http.Request) {
 			Estimate:    req.Estimate,
 			CycleID:     req.CycleID,
 			ParentID:    req.ParentID,
+			ProjectID:   req.ProjectID,
+			MilestoneID: req.MilestoneID,
+			Branch:      req.Branch,
+		}
+		for _, d := range []struct {
+			value string
+			dest  **time.Time
+		}{{req.StartDate, &issue.StartDate}, {req.DueDate, &issue.DueDate}} {
+			if d.value == "" {
+				continue
+			}
+			t, err := parseDate(d.value)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			*d.dest = &t
 		}
 
-		if err := s.issueRepo.Create(issue); err != nil {
-			http.Error(w, fmt.Sprintf("failed to create issue: %v", err), http.StatusInternalServerError)
+		if err := st.issueRepo.Create(issue); err != nil {
+			http.Error(w, fmt.Sprintf("failed to create issue: %v", err), issueErrorStatus(err))
 			return
 		}
 
+		s.publishIssue(EventIssueCreated, issue)
 		jsonResponse(w, issue)
 	}
 }
 
 func (s *Server) handleIssue(w http.ResponseWriter, r *http.Request) {
-	id := filepath.Base(r.URL.Path)
-
-	issue, err := s.issueRepo.GetByID(id)
+	st := s.storesFor(r)
+	issue, err := st.findIssue(filepath.Base(r.URL.Path), r.URL.Query().Get("workspace_id"))
 	if err != nil {
 		http.Error(w, fmt.Sprintf("failed to get issue: %v", err), http.StatusInternalServerError)
 		return
@@ -249,6 +434,7 @@ func (s *Server) handleIssue(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "issue not found", http.StatusNotFound)
 		return
 	}
+	id := issue.ID
 
 	switch r.Method {
 	case http.MethodGet:
@@ -285,6 +471,25 @@ func (s *Server) handleIssue(w http.ResponseWriter, r *http.Request) {
 		if parentID, ok := req["parent_id"].(string); ok {
 			issue.ParentID = parentID
 		}
+		if projectID, ok := req["project_id"].(string); ok {
+			issue.ProjectID = projectID
+		}
+		if milestoneID, ok := req["milestone_id"].(string); ok {
+			issue.MilestoneID = milestoneID
+		}
+		for field, dest := range map[string]**time.Time{"start_date": &issue.StartDate, "due_date": &issue.DueDate} {
+			date, ok, err := optionalDate(req, field)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if ok {
+				*dest = date
+			}
+		}
+		if branch, ok := req["branch"].(string); ok {
+			issue.Branch = branch
+		}
 		if labels, ok := req["labels"].([]interface{}); ok {
 			issue.Labels = make([]string, len(labels))
 			for i, l := range labels {
@@ -292,19 +497,16 @@ func (s *Server) handleIssue(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
-		if err := s.issueRepo.Update(issue); err != nil {
-			http.Error(w, fmt.Sprintf("failed to update issue: %v", err), http.StatusInternalServerError)
+		if err := st.issueRepo.Update(issue); err != nil {
+			http.Error(w, fmt.Sprintf("failed to update issue: %v", err), issueErrorStatus(err))
 			return
 		}
 
-		jsonResponse(w, issue)
+		s.publishIssue(EventIssueUpdated, issue)
+		st.issueWithWIP(w, issue)
 
 	case http.MethodDelete:
-		if err := s.issueRepo.Delete(id); err != nil {
-			http.Error(w, fmt.Sprintf("failed to delete issue: %v", err), http.StatusInternalServerError)
-			return
-		}
-		w.WriteHeader(http.StatusNoContent)
+		s.deleteIssue(w, r, issue)
 
 	case http.MethodPatch:
 		// Handle status-only updates
@@ -316,22 +518,72 @@ func (s *Server) handleIssue(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		if err := s.issueRepo.UpdateStatus(id, req.Status); err != nil {
-			http.Error(w, fmt.Sprintf("failed to update status: %v", err), http.StatusInternalServerError)
+		if err := st.issueRepo.UpdateStatus(id, req.Status); err != nil {
+			http.Error(w, fmt.Sprintf("failed to update status: %v", err), issueErrorStatus(err))
 			return
 		}
 
-		issue, _ := s.issueRepo.GetByID(id)
-		jsonResponse(w, issue)
+		issue, _ := st.issueRepo.GetByID(id)
+		if issue == nil {
+			jsonResponse(w, issue)
+			return
+		}
+		s.publishIssue(EventIssueUpdated, issue)
+		st.issueWithWIP(w, issue)
+	}
+}
+
+// findIssue looks up an issue by ID or by key such as PUL-42. Keys are
+// matched against every workspace using that prefix unless workspaceID
+// narrows the search; an ambiguous key is reported as an error.
+func (st *stores) findIssue(ref, workspaceID string) (*db.Issue, error) {
+	issue, err := st.issueRepo.GetByID(ref)
+	if err != nil || issue != nil {
+		return issue, err
+	}
+
+	prefix, number, ok := db.ParseIssueKey(ref)
+	if !ok {
+		return nil, nil
+	}
+
+	workspaces, err := st.workspaceRepo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var found *db.Issue
+	for _, ws := range workspaces {
+		if workspaceID != "" && ws.ID != workspaceID {
+			continue
+		}
+		settings, err := ws.ParseSettings()
+		if err != nil || settings.KeyPrefix() != prefix {
+			continue
+		}
+		issue, err := st.issueRepo.GetByNumber(ws.ID, number)
+		if err != nil {
+			return nil, err
+		}
+		if issue == nil {
+			continue
+		}
+		if found != nil {
+			return nil, fmt.Errorf("%s matches issues in several workspaces; pass workspace_id", ref)
+		}
+		found = issue
 	}
+
+	return found, nil
 }
 
 func (s *Server) handleCycles(w http.ResponseWriter, r *http.Request) {
+	st := s.storesFor(r)
 	workspaceID := r.URL.Query().Get("workspace_id")
 
 	switch r.Method {
 	case http.MethodGet:
-		cycles, err := s.cycleRepo.List(workspaceID)
+		cycles, err := st.cycleRepo.List(workspaceID)
 		if err != nil {
 			http.Error(w, fmt.Sprintf("failed to list cycles: %v", err), http.StatusInternalServerError)
 			return
@@ -367,7 +619,7 @@ func (s *Server) handleCycles(w http.ResponseWriter, r *http.Request) {
 			cycle.EndDate = &t
 		}
 
-		if err := s.cycleRepo.Create(cycle); err != nil {
+		if err := st.cycleRepo.Create(cycle); err != nil {
 			http.Error(w, fmt.Sprintf("failed to create cycle: %v", err), http.StatusInternalServerError)
 			return
 		}
@@ -377,9 +629,10 @@ func (s *Server) handleCycles(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleCycle(w http.ResponseWriter, r *http.Request) {
+	st := s.storesFor(r)
 	id := filepath.Base(r.URL.Path)
 
-	cycle, err := s.cycleRepo.GetByID(id)
+	cycle, err := st.cycleRepo.GetByID(id)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("failed to get cycle: %v", err), http.StatusInternalServerError)
 		return
@@ -407,7 +660,7 @@ func (s *Server) handleCycle(w http.ResponseWriter, r *http.Request) {
 			cycle.Status = status
 		}
 
-		if err := s.cycleRepo.Update(cycle); err != nil {
+		if err := st.cycleRepo.Update(cycle); err != nil {
 			http.Error(w, fmt.Sprintf("failed to update cycle: %v", err), http.StatusInternalServerError)
 			return
 		}
@@ -415,7 +668,7 @@ func (s *Server) handleCycle(w http.ResponseWriter, r *http.Request) {
 		jsonResponse(w, cycle)
 
 	case http.MethodDelete:
-		if err := s.cycleRepo.Delete(id); err != nil {
+		if err := st.cycleRepo.Delete(id); err != nil {
 			http.Error(w, fmt.Sprintf("failed to delete cycle: %v", err), http.StatusInternalServerError)
 			return
 		}
@@ -423,37 +676,44 @@ func (s *Server) handleCycle(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleMetrics summarises a workspace's issues. With group_by= it breaks
+// the delivery metrics down by assignee, label, priority or project instead.
 func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	st := s.storesFor(r)
+	if r.URL.Query().Get("group_by") != "" {
+		s.handleMetricsBreakdown(w, r)
+		return
+	}
+
 	workspaceID := r.URL.Query().Get("workspace_id")
 	if workspaceID == "" {
 		workspaceID = "default"
 	}
 
 	// Get issue counts by status
-	statusCounts, err := s.issueRepo.CountByStatus(workspaceID)
+	statusCounts, err := st.issueRepo.CountByStatus(workspaceID)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("failed to count issues: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	// Calculate velocity metrics
-	var totalPoints, completedPoints int
-	issues, _ := s.issueRepo.List(workspaceID, "", 0, 0)
-	for _, issue := range issues {
-		totalPoints += issue.Estimate
-		if issue.Status == "done" {
-			completedPoints += issue.Estimate
+	bugLabel := db.DefaultBugLabel
+	if ws, err := st.workspaceRepo.GetByID(workspaceID); err == nil && ws != nil {
+		if settings, err := ws.ParseSettings(); err == nil {
+			bugLabel = settings.BugLabelName()
 		}
 	}
 
-	var bugs int
-	for _, issue := range issues {
-		for _, label := range issue.Labels {
-			if label == "bug" {
-				bugs++
-				break
-			}
-		}
+	// Sum points and bugs in the database
+	totals, err := st.metricsRepo.Totals(workspaceID, bugLabel)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to total issues: %v", err), http.StatusInternalServerError)
+		return
+	}
+	wip, err := st.metricsRepo.WIP(workspaceID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to measure WIP: %v", err), http.StatusInternalServerError)
+		return
 	}
 
 	totalIssues := 0
@@ -468,33 +728,71 @@ func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
 
 	metrics := map[string]interface{}{
 		"workspace_id":      workspaceID,
-		"total_issues":     totalIssues,
-		"backlog_count":    statusCounts["backlog"],
-		"todo_count":       statusCounts["todo"],
+		"total_issues":      totalIssues,
+		"backlog_count":     statusCounts["backlog"],
+		"todo_count":        statusCounts["todo"],
 		"in_progress_count": statusCounts["in_progress"],
-		"done_count":       statusCounts["done"],
-		"total_points":     totalPoints,
-		"completed_points": completedPoints,
+		"done_count":        statusCounts["done"],
+		"total_points":      totals.TotalPoints,
+		"completed_points":  totals.CompletedPoints,
 		"completion_rate":   completionRate,
-		"bug_count":        bugs,
+		"bug_count":         totals.Bugs,
+		"wip_violations":    wip.Violations,
 	}
 
 	jsonResponse(w, metrics)
 }
 
 func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
-	query := r.URL.Query().Get("q")
+	st := s.storesFor(r)
 	workspaceID := r.URL.Query().Get("workspace_id")
 	if workspaceID == "" {
 		workspaceID = "default"
 	}
 
+	format, ok := tabularFormat(w, r)
+	if !ok {
+		return
+	}
+
+	issues, err := st.searchIssues(workspaceID, r.URL.Query().Get("q"), r.URL.Query())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to search issues: %v", err), searchErrorStatus(err))
+		return
+	}
+	if format != "" {
+		writeIssueTable(w, r, format, issues)
+		return
+	}
+
+	var results []interface{}
+	for _, issue := range issues {
+		results = append(results, map[string]interface{}{
+			"type":      "issue",
+			"id":        issue.ID,
+			"title":     issue.Title,
+			"status":    issue.Status,
+			"labels":    issue.Labels,
+			"estimate":  issue.Estimate,
+			"workspace": workspaceID,
+		})
+	}
+
+	jsonResponse(w, results)
+}
+
+// searchIssues returns the issues in a workspace matching a search query.
+// The query may be free text or a single status:, label:, assignee: or
+// is: filter; params supplies the same filters as individual values.
+// is:overdue and is:at-risk select open issues by their due date and SLA.
+func (st *stores) searchIssues(workspaceID, query string, params url.Values) ([]*db.Issue, error) {
 	// Parse filters from query
 	statusFilter := ""
 	labelFilter := ""
 	assigneeFilter := ""
+	isFilter := ""
 
-	// Handle filter prefixes: status:, label:, assignee:
+	// Handle filter prefixes: status:, label:, assignee:, is:
 	if query != "" {
 		// Check for status: filter
 		if strings.HasPrefix(query, "status:") {
@@ -506,29 +804,59 @@ func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 		} else if strings.HasPrefix(query, "assignee:") {
 			assigneeFilter = strings.TrimPrefix(query, "assignee:")
 			query = ""
+		} else if strings.HasPrefix(query, "is:") {
+			isFilter = strings.TrimPrefix(query, "is:")
+			query = ""
 		}
 	}
 
 	// Also check individual query params
 	if statusFilter == "" {
-		statusFilter = r.URL.Query().Get("status")
+		statusFilter = params.Get("status")
 	}
 	if labelFilter == "" {
-		labelFilter = r.URL.Query().Get("label")
+		labelFilter = params.Get("label")
 	}
 	if assigneeFilter == "" {
-		assigneeFilter = r.URL.Query().Get("assignee")
+		assigneeFilter = params.Get("assignee")
+	}
+	if isFilter == "" {
+		isFilter = params.Get("is")
+	}
+
+	var slaState string
+	var settings *db.WorkspaceSettings
+	if isFilter != "" {
+		switch isFilter {
+		case "overdue":
+			slaState = analytics.SLAOverdue
+		case "at-risk":
+			slaState = analytics.SLAAtRisk
+		default:
+			return nil, fmt.Errorf("%w: unknown filter is:%s (use is:overdue or is:at-risk)", errInvalidQuery, isFilter)
+		}
+
+		ws, err := st.workspaceRepo.GetByID(workspaceID)
+		if err != nil {
+			return nil, err
+		}
+		settings = &db.WorkspaceSettings{}
+		if ws != nil {
+			if settings, err = ws.ParseSettings(); err != nil {
+				return nil, err
+			}
+		}
 	}
+	now := time.Now()
 
 	// Get all issues for workspace
-	issues, err := s.issueRepo.List(workspaceID, "", 0, 0)
+	issues, err := st.issueRepo.List(workspaceID, "", 0, 0)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("failed to search issues: %v", err), http.StatusInternalServerError)
-		return
+		return nil, err
 	}
 
 	// Filter issues
-	var results []interface{}
+	var results []*db.Issue
 	for _, issue := range issues {
 		matches := true
 
@@ -563,20 +891,17 @@ func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 			matches = false
 		}
 
+		// Due date and SLA filter
+		if slaState != "" && analytics.SLAState(issue, settings, now) != slaState {
+			matches = false
+		}
+
 		if matches {
-			results = append(results, map[string]interface{}{
-				"type":      "issue",
-				"id":        issue.ID,
-				"title":     issue.Title,
-				"status":    issue.Status,
-				"labels":    issue.Labels,
-				"estimate":  issue.Estimate,
-				"workspace": workspaceID,
-			})
+			results = append(results, issue)
 		}
 	}
 
-	jsonResponse(w, results)
+	return results, nil
 }
 
 func (s *Server) handleWebUI(w http.ResponseWriter, r *http.Request) {
@@ -1156,17 +1481,26 @@ func webUIHTML() string {
 func (s *Server) Start(ctx context.Context) error {
 	s.server = &http.Server{
 		Addr:    s.addr,
-		Handler: s.mux,
+		Handler: s.instrument(s.mux),
 	}
 
 	go func() {
-		fmt.Printf("Pulse server starting on %s\n", s.addr)
-		fmt.Printf("Database: %s\n", s.db.Path())
+		s.logger.Info("server starting", "addr", s.addr, "database", s.db.Path(), "tracing", s.tracing)
 		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			fmt.Printf("Server error: %v\n", err)
+			s.logger.Error("server failed", "error", err)
 		}
 	}()
 
+	if s.snapshotInterval > 0 {
+		go s.runSnapshots(ctx, s.snapshotInterval)
+	}
+	if s.breachCheckInterval > 0 {
+		go s.runBreachChecks(ctx, s.breachCheckInterval)
+	}
+	if s.autoCloseInterval > 0 {
+		go s.runAutoClose(ctx, s.autoCloseInterval)
+	}
+
 	<-ctx.Done()
 	return s.server.Shutdown(ctx)
 }
@@ -1206,4 +1540,8 @@ type Column struct {
 	Name  string `json:"name"`
 	Order int    `json:"order"`
 	Color string `json:"color"`
+	// Count is the issues in the column and WIPLimit its limit, if any.
+	Count    int  `json:"count"`
+	WIPLimit int  `json:"wip_limit,omitempty"`
+	Exceeded bool `json:"exceeded"`
 }
@@ -0,0 +1,31 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestStatementsUseRequestContext(t *testing.T) {
+	s := newTestServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec := httptest.NewRecorder()
+	s.mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/workspaces", nil).WithContext(ctx))
+	if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), context.Canceled.Error()) {
+		t.Errorf("canceled request: %d %s", rec.Code, rec.Body)
+	}
+
+	// The server's own stores are not bound to any request.
+	rec = httptest.NewRecorder()
+	s.mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/workspaces", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("request after a canceled one: %d %s", rec.Code, rec.Body)
+	}
+	if _, err := s.workspaceRepo.List(); err != nil {
+		t.Errorf("background list: %v", err)
+	}
+}
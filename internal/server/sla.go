@@ -0,0 +1,184 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pulse/pm/internal/analytics"
+	"github.com/pulse/pm/internal/db"
+)
+
+// errInvalidQuery marks a search query the server cannot interpret.
+var errInvalidQuery = errors.New("invalid query")
+
+// searchErrorStatus maps a search error to an HTTP status.
+func searchErrorStatus(err error) int {
+	if errors.Is(err, errInvalidQuery) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// DefaultBreachCheckInterval is how often the server looks for missed
+// deadlines when Config.BreachCheckInterval is unset.
+const DefaultBreachCheckInterval = 24 * time.Hour
+
+// runBreachChecks records missed deadlines now and then every interval
+// until ctx is cancelled.
+func (s *Server) runBreachChecks(ctx context.Context, interval time.Duration) {
+	check := func() {
+		n, err := s.checkBreaches(time.Now())
+		if err != nil {
+			s.logger.Error("SLA breach check failed", "error", err)
+			return
+		}
+		if n > 0 {
+			s.logger.Info("SLA breach check", "breaches", n)
+		}
+	}
+
+	check()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			check()
+		}
+	}
+}
+
+// checkBreaches adds a breach event to the history of every issue that
+// has missed a deadline since the last check, dated at the deadline, and
+// returns how many were recorded.
+func (s *Server) checkBreaches(now time.Time) (int, error) {
+	workspaces, err := s.workspaceRepo.List()
+	if err != nil {
+		return 0, err
+	}
+
+	recorded := 0
+	for _, ws := range workspaces {
+		settings, err := ws.ParseSettings()
+		if err != nil {
+			continue
+		}
+		issues, err := s.issueRepo.List(ws.ID, "", 0, 0)
+		if err != nil {
+			return recorded, err
+		}
+
+		breaches := analytics.FindBreaches(issues, settings, now)
+		if len(breaches) == 0 {
+			continue
+		}
+
+		events := db.NewEventRepository(s.db)
+		existing, err := events.ListByField(ws.ID, db.FieldBreach)
+		if err != nil {
+			return recorded, err
+		}
+		seen := make(map[string]bool, len(existing))
+		for _, e := range existing {
+			value := e.NewValue
+			if !strings.Contains(value, "@") {
+				// Older events hold only the source and are dated at
+				// the deadline.
+				value = breachValue(value, e.CreatedAt)
+			}
+			seen[e.IssueID+"/"+value] = true
+		}
+
+		for _, b := range breaches {
+			// A deadline moved and missed again is a new breach.
+			value := breachValue(b.Source, b.At)
+			if seen[b.IssueID+"/"+value] {
+				continue
+			}
+			err := events.Record(&db.IssueEvent{
+				IssueID:     b.IssueID,
+				WorkspaceID: b.WorkspaceID,
+				Field:       db.FieldBreach,
+				NewValue:    value,
+				CreatedAt:   b.At,
+			})
+			if err != nil {
+				return recorded, err
+			}
+			recorded++
+		}
+	}
+
+	return recorded, nil
+}
+
+// breachValue is the value of a breach event: the deadline's source and
+// time, e.g. due_date@2026-03-06T00:00:00Z.
+func breachValue(source string, at time.Time) string {
+	return source + "@" + at.UTC().Format(time.RFC3339)
+}
+
+// handleSLAMetrics reports SLA attainment overall and per label for issues
+// resolved in the last days= days (default 30), with the open issues at
+// risk or overdue.
+func (s *Server) handleSLAMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	st := s.storesFor(r)
+	days := 30
+	if v := r.URL.Query().Get("days"); v != "" {
+		d, err := strconv.Atoi(v)
+		if err != nil || d <= 0 {
+			http.Error(w, "days must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		days = d
+	}
+
+	workspaceID := workspaceParam(r)
+	settings, ok := st.workspaceSettings(w, workspaceID)
+	if !ok {
+		return
+	}
+
+	issues, err := st.issueRepo.List(workspaceID, "", 0, 0)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list issues: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	jsonResponse(w, analytics.CalculateSLAAttainment(issues, settings, now.AddDate(0, 0, -days), now))
+}
+
+// workspaceSettings loads a workspace's settings, writing an error
+// response when it cannot.
+func (st *stores) workspaceSettings(w http.ResponseWriter, workspaceID string) (*db.WorkspaceSettings, bool) {
+	ws, err := st.workspaceRepo.GetByID(workspaceID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to get workspace: %v", err), http.StatusInternalServerError)
+		return nil, false
+	}
+	if ws == nil {
+		http.Error(w, "workspace not found", http.StatusNotFound)
+		return nil, false
+	}
+	settings, err := ws.ParseSettings()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	return settings, true
+}
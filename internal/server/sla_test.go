@@ -0,0 +1,84 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+func TestMovedDueDateBreachedAgain(t *testing.T) {
+	s := newTestServer(t)
+	if err := s.workspaceRepo.Create(&db.Workspace{ID: "ws", Name: "SLA"}); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+	due := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	issue := &db.Issue{ID: "late", WorkspaceID: "ws", Title: "Late", Status: "todo", DueDate: &due}
+	if err := s.issueRepo.Create(issue); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(want int) {
+		t.Helper()
+		n, err := s.checkBreaches(now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != want {
+			t.Errorf("recorded %d breaches, want %d", n, want)
+		}
+	}
+	check(1)
+	check(0)
+
+	// Moving the due date and missing it again is a second breach.
+	moved := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+	issue.DueDate = &moved
+	if err := s.issueRepo.Update(issue); err != nil {
+		t.Fatal(err)
+	}
+	check(1)
+	check(0)
+
+	events, err := db.NewEventRepository(s.db).ListByField("ws", db.FieldBreach)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := make(map[string]bool)
+	for _, e := range events {
+		values[e.NewValue] = true
+	}
+	for _, want := range []string{"due_date@2026-03-11T00:00:00Z", "due_date@2026-03-16T00:00:00Z"} {
+		if !values[want] {
+			t.Errorf("no breach event %q among %v", want, values)
+		}
+	}
+}
+
+func TestBreachEventsWithoutDeadlineNotRepeated(t *testing.T) {
+	s := newTestServer(t)
+	if err := s.workspaceRepo.Create(&db.Workspace{ID: "ws", Name: "SLA"}); err != nil {
+		t.Fatal(err)
+	}
+	due := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	if err := s.issueRepo.Create(&db.Issue{ID: "late", WorkspaceID: "ws", Title: "Late", Status: "todo", DueDate: &due}); err != nil {
+		t.Fatal(err)
+	}
+	// Recorded before events carried the deadline.
+	err := db.NewEventRepository(s.db).Record(&db.IssueEvent{
+		IssueID:     "late",
+		WorkspaceID: "ws",
+		Field:       db.FieldBreach,
+		NewValue:    "due_date",
+		CreatedAt:   due.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := s.checkBreaches(time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC))
+	if err != nil || n != 0 {
+		t.Errorf("checkBreaches = %d, %v; want the old event to count", n, err)
+	}
+}
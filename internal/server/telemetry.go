@@ -0,0 +1,231 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/pulse/pm/internal/telemetry"
+)
+
+// serverMetrics are the operational metrics exposed on /metrics.
+type serverMetrics struct {
+	registry *prometheus.Registry
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	queries  *prometheus.HistogramVec
+	handler  http.Handler
+}
+
+// newServerMetrics registers the server's metrics; scrape errors are
+// reported to logger.
+func newServerMetrics(logger *slog.Logger) *serverMetrics {
+	m := &serverMetrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "pulse_http_requests_total",
+			Help: "HTTP requests served, by route pattern, method and status code.",
+		}, []string{"route", "method", "code"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "pulse_http_request_duration_seconds",
+			Help:    "Time to serve HTTP requests, by route pattern and method.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+		queries: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "pulse_db_query_duration_seconds",
+			Help:    "Time to run database statements, by leading keyword.",
+			Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"op"}),
+	}
+	m.registry.MustRegister(m.requests, m.latency, m.queries)
+	m.handler = promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{
+		ErrorLog:      slog.NewLogLogger(logger.Handler(), slog.LevelError),
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+	return m
+}
+
+// observeQuery records the duration of one database statement.
+func (m *serverMetrics) observeQuery(op string, d time.Duration) {
+	m.queries.WithLabelValues(op).Observe(d.Seconds())
+}
+
+// instrument gives each request an ID, traces it when a tracer is set,
+// and records it in the metrics and the access log. Requests are labeled
+// with the route pattern they matched rather than their path so IDs do
+// not multiply the series.
+func (s *Server) instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		id := r.Header.Get("X-Request-ID")
+		if !telemetry.ValidRequestID(id) {
+			id = telemetry.NewRequestID()
+		}
+		w.Header().Set("X-Request-ID", id)
+		ctx := telemetry.WithRequestID(r.Context(), id)
+		ctx = propagation.TraceContext{}.Extract(ctx, propagation.HeaderCarrier(r.Header))
+		ctx, span := s.tracer.Start(ctx, r.Method, trace.WithSpanKind(trace.SpanKindServer))
+		r = r.WithContext(ctx)
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		elapsed := time.Since(start)
+
+		route := r.Pattern
+		if route == "" {
+			route = "unmatched"
+		}
+		s.telemetry.requests.WithLabelValues(route, r.Method, strconv.Itoa(rec.status)).Inc()
+		s.telemetry.latency.WithLabelValues(route, r.Method).Observe(elapsed.Seconds())
+
+		var failure error
+		if rec.status >= http.StatusInternalServerError {
+			failure = errors.New(strings.TrimSpace(rec.body.String()))
+		}
+		span.SetName(r.Method + " " + route)
+		span.SetAttributes(
+			attribute.String("http.request.method", r.Method),
+			attribute.String("http.route", route),
+			attribute.String("url.path", r.URL.Path),
+			attribute.Int("http.response.status_code", rec.status),
+			attribute.String("request.id", id),
+		)
+		telemetry.EndSpan(span, failure)
+
+		attrs := []any{
+			"request_id", id,
+			"method", r.Method,
+			"path", r.URL.Path,
+			"route", route,
+			"status", rec.status,
+			"bytes", rec.bytes,
+			"duration", elapsed,
+			"remote", r.RemoteAddr,
+		}
+		if sc := span.SpanContext(); sc.IsValid() {
+			attrs = append(attrs, "trace_id", sc.TraceID().String())
+		}
+		level := slog.LevelInfo
+		switch {
+		case failure != nil:
+			level = slog.LevelError
+			attrs = append(attrs, "error", failure.Error())
+		case rec.status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		s.logger.Log(ctx, level, "request", attrs...)
+	})
+}
+
+// maxErrorBody bounds how much of a failed response is kept for the
+// access log and trace.
+const maxErrorBody = 1024
+
+// statusRecorder remembers the status code and size of a response, and
+// the start of its body when it is a server error.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	bytes       int
+	body        bytes.Buffer
+}
+
+func (rec *statusRecorder) WriteHeader(code int) {
+	if !rec.wroteHeader {
+		rec.status = code
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	if rec.status >= http.StatusInternalServerError && rec.body.Len() < maxErrorBody {
+		rec.body.Write(b[:min(len(b), maxErrorBody-rec.body.Len())])
+	}
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += n
+	return n, err
+}
+
+// Flush keeps event streams working through the recorder.
+func (rec *statusRecorder) Flush() {
+	if f, ok := rec.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+var (
+	dbMaxOpenDesc = prometheus.NewDesc("pulse_db_max_open_connections",
+		"Maximum number of open database connections.", nil, nil)
+	dbOpenDesc = prometheus.NewDesc("pulse_db_open_connections",
+		"Open database connections, in use or idle.", nil, nil)
+	dbInUseDesc = prometheus.NewDesc("pulse_db_in_use_connections",
+		"Database connections in use.", nil, nil)
+	dbIdleDesc = prometheus.NewDesc("pulse_db_idle_connections",
+		"Idle database connections.", nil, nil)
+	dbWaitCountDesc = prometheus.NewDesc("pulse_db_wait_count_total",
+		"Times a statement waited for a free connection.", nil, nil)
+	dbWaitDurationDesc = prometheus.NewDesc("pulse_db_wait_duration_seconds_total",
+		"Time spent waiting for a free connection.", nil, nil)
+	issuesDesc = prometheus.NewDesc("pulse_issues",
+		"Issues by workspace and workflow state.", []string{"workspace_id", "status"}, nil)
+)
+
+// databaseCollector reads connection pool statistics and the issues in
+// each workflow state of every workspace at scrape time.
+type databaseCollector struct {
+	s *Server
+}
+
+func (c databaseCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{dbMaxOpenDesc, dbOpenDesc, dbInUseDesc, dbIdleDesc, dbWaitCountDesc, dbWaitDurationDesc, issuesDesc} {
+		ch <- d
+	}
+}
+
+func (c databaseCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.s.db.Stats()
+	ch <- prometheus.MustNewConstMetric(dbMaxOpenDesc, prometheus.GaugeValue, float64(stats.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(dbOpenDesc, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(dbInUseDesc, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(dbIdleDesc, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(dbWaitCountDesc, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(dbWaitDurationDesc, prometheus.CounterValue, stats.WaitDuration.Seconds())
+
+	counts, err := c.s.metricsRepo.CountByStatus()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(issuesDesc, err)
+		return
+	}
+	for _, n := range counts {
+		ch <- prometheus.MustNewConstMetric(issuesDesc, prometheus.GaugeValue, float64(n.Count), n.WorkspaceID, n.Status)
+	}
+}
+
+// handlePrometheus serves the operational metrics in the Prometheus
+// exposition format. Unlike /api/metrics it covers the whole instance.
+func (s *Server) handlePrometheus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.telemetry.handler.ServeHTTP(w, r)
+}
@@ -0,0 +1,281 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pulse/pm/internal/db"
+	"github.com/pulse/pm/internal/telemetry"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	s, err := NewServer(Config{
+		DataDir:             t.TempDir(),
+		BreachCheckInterval: -1,
+		AutoCloseInterval:   -1,
+		Logger:              slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func labels(m *dto.Metric) map[string]string {
+	l := make(map[string]string)
+	for _, p := range m.GetLabel() {
+		l[p.GetName()] = p.GetValue()
+	}
+	return l
+}
+
+func TestPrometheusScrape(t *testing.T) {
+	s := newTestServer(t)
+	h := s.instrument(s.mux)
+
+	// An ID with a quote, a backslash and a newline must be escaped in
+	// the exposition and come back intact.
+	const workspaceID = "ws \"a\"\\b\nc"
+	if err := s.workspaceRepo.Create(&db.Workspace{ID: workspaceID, Name: "Escapes"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.issueRepo.Create(&db.Issue{ID: "issue_1", WorkspaceID: workspaceID, Title: "One", Status: "todo"}); err != nil {
+		t.Fatal(err)
+	}
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/health", nil))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("content type %q", ct)
+	}
+	body := rec.Body.String()
+	for _, line := range []string{
+		"# HELP pulse_http_requests_total HTTP requests served, by route pattern, method and status code.",
+		"# TYPE pulse_http_requests_total counter",
+		"# TYPE pulse_http_request_duration_seconds histogram",
+		"# TYPE pulse_db_query_duration_seconds histogram",
+		"# TYPE pulse_db_open_connections gauge",
+		"# TYPE pulse_db_wait_count_total counter",
+		"# HELP pulse_issues Issues by workspace and workflow state.",
+		"# TYPE pulse_issues gauge",
+		`pulse_issues{status="todo",workspace_id="ws \"a\"\\b\nc"} 1`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("missing line %q", line)
+		}
+	}
+
+	parser := expfmt.NewTextParser(model.LegacyValidation)
+	families, err := parser.TextToMetricFamilies(strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("parse exposition: %v\n%s", err, body)
+	}
+
+	issues := families["pulse_issues"]
+	if issues == nil {
+		t.Fatal("no pulse_issues family")
+	}
+	found := false
+	for _, m := range issues.GetMetric() {
+		l := labels(m)
+		if l["workspace_id"] == workspaceID && l["status"] == "todo" {
+			found = true
+			if v := m.GetGauge().GetValue(); v != 1 {
+				t.Errorf("pulse_issues = %v, want 1", v)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("no pulse_issues sample for %q", workspaceID)
+	}
+
+	requests := families["pulse_http_requests_total"]
+	if requests.GetType() != dto.MetricType_COUNTER {
+		t.Fatalf("pulse_http_requests_total type %v", requests.GetType())
+	}
+	found = false
+	for _, m := range requests.GetMetric() {
+		l := labels(m)
+		if l["route"] == "/api/health" && l["method"] == http.MethodGet && l["code"] == "200" {
+			found = m.GetCounter().GetValue() == 1
+		}
+	}
+	if !found {
+		t.Errorf("no single request counted for /api/health: %v", requests)
+	}
+
+	latency := families["pulse_http_request_duration_seconds"]
+	if latency.GetType() != dto.MetricType_HISTOGRAM || len(latency.GetMetric()) == 0 {
+		t.Fatalf("pulse_http_request_duration_seconds = %v", latency)
+	}
+	// The exposition lists the +Inf bucket after the configured bounds.
+	if n, want := len(latency.GetMetric()[0].GetHistogram().GetBucket()), len(prometheus.DefBuckets)+1; n != want {
+		t.Errorf("%d latency buckets, want %d", n, want)
+	}
+	if families["pulse_db_query_duration_seconds"].GetType() != dto.MetricType_HISTOGRAM {
+		t.Error("no database query histogram")
+	}
+}
+
+func TestPrometheusMethodNotAllowed(t *testing.T) {
+	s := newTestServer(t)
+	rec := httptest.NewRecorder()
+	s.instrument(s.mux).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status %d, want 405", rec.Code)
+	}
+}
+
+// collector stands in for an OpenTelemetry collector, keeping the spans
+// posted to /v1/traces.
+type collector struct {
+	mu       sync.Mutex
+	requests []*coltracepb.ExportTraceServiceRequest
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/traces" {
+		http.NotFound(w, r)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req := &coltracepb.ExportTraceServiceRequest{}
+	if err := proto.Unmarshal(body, req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.mu.Lock()
+	c.requests = append(c.requests, req)
+	c.mu.Unlock()
+
+	resp, _ := proto.Marshal(&coltracepb.ExportTraceServiceResponse{})
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Write(resp)
+}
+
+// spans returns the spans received, with the service named by the
+// resource they came with.
+func (c *collector) spans() (spans []*tracepb.Span, services []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, req := range c.requests {
+		for _, rs := range req.GetResourceSpans() {
+			for _, a := range rs.GetResource().GetAttributes() {
+				if a.GetKey() == "service.name" {
+					services = append(services, a.GetValue().GetStringValue())
+				}
+			}
+			for _, ss := range rs.GetScopeSpans() {
+				spans = append(spans, ss.GetSpans()...)
+			}
+		}
+	}
+	return spans, services
+}
+
+func TestTracesExportedToCollector(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	tp, err := telemetry.NewTracerProvider(context.Background(), "pulse-test", srv.URL, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewServer(Config{
+		DataDir:             t.TempDir(),
+		BreachCheckInterval: -1,
+		AutoCloseInterval:   -1,
+		Logger:              logger,
+		TracerProvider:      tp,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	const traceID, parentID = "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+	req := httptest.NewRequest(http.MethodGet, "/api/workspaces", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-"+parentID+"-01")
+	rec := httptest.NewRecorder()
+	s.instrument(s.mux).ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	if err := tp.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	spans, services := c.spans()
+	if len(services) == 0 {
+		t.Fatal("collector received no spans")
+	}
+	for _, svc := range services {
+		if svc != "pulse-test" {
+			t.Errorf("service.name = %q, want pulse-test", svc)
+		}
+	}
+
+	var root *tracepb.Span
+	for _, span := range spans {
+		if span.GetKind() == tracepb.Span_SPAN_KIND_SERVER {
+			root = span
+		}
+	}
+	if root == nil {
+		t.Fatalf("no server span among %d spans", len(spans))
+	}
+	if got := hex.EncodeToString(root.GetTraceId()); got != traceID {
+		t.Errorf("trace id = %s, want %s", got, traceID)
+	}
+	if got := hex.EncodeToString(root.GetParentSpanId()); got != parentID {
+		t.Errorf("parent span id = %s, want %s", got, parentID)
+	}
+	if root.GetName() != "GET /api/workspaces" {
+		t.Errorf("server span name = %q", root.GetName())
+	}
+
+	queries := 0
+	for _, span := range spans {
+		if span.GetKind() != tracepb.Span_SPAN_KIND_CLIENT {
+			continue
+		}
+		queries++
+		if !strings.HasPrefix(span.GetName(), "db.") {
+			t.Errorf("client span %q is not a database statement", span.GetName())
+		}
+		if !bytes.Equal(span.GetTraceId(), root.GetTraceId()) || !bytes.Equal(span.GetParentSpanId(), root.GetSpanId()) {
+			t.Errorf("database span %q is not a child of the request span", span.GetName())
+		}
+	}
+	if queries == 0 {
+		t.Error("no database spans exported")
+	}
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pulse/pm/internal/analytics"
+	"github.com/pulse/pm/internal/db"
+	"github.com/pulse/pm/internal/report"
+)
+
+// handleTimeline schedules a workspace, or one project with project_id=,
+// returning issues, projects and milestones with their dependency edges,
+// slack and critical path. A project timeline only schedules the
+// project's own issues, so blockers outside it are left out. format=csv
+// or md lists the items as a table.
+func (s *Server) handleTimeline(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	st := s.storesFor(r)
+	format, ok := tabularFormat(w, r)
+	if !ok {
+		return
+	}
+
+	workspaceID := r.URL.Query().Get("workspace_id")
+	if workspaceID == "" {
+		workspaceID = "default"
+	}
+
+	var (
+		issues     []*db.Issue
+		projects   []*db.Project
+		milestones []*db.Milestone
+		err        error
+	)
+	if projectID := r.URL.Query().Get("project_id"); projectID != "" {
+		project, ok := st.findProject(w, projectID)
+		if !ok {
+			return
+		}
+		workspaceID = project.WorkspaceID
+		projects = []*db.Project{project}
+		if issues, err = st.issueRepo.ListByProject(project.ID); err == nil {
+			milestones, err = st.milestoneRepo.ListByProject(project.ID)
+		}
+	} else {
+		if issues, err = st.issueRepo.List(workspaceID, "", 0, 0); err == nil {
+			if projects, err = st.projectRepo.List(workspaceID); err == nil {
+				milestones, err = st.milestoneRepo.ListByWorkspace(workspaceID)
+			}
+		}
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to load timeline: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	relations, err := st.relationRepo.ListByWorkspace(workspaceID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to list relations: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	timeline := analytics.BuildTimeline(issues, relations, projects, milestones, time.Now())
+	if format != "" {
+		w.Header().Set("Content-Type", report.ContentType(format))
+		report.Write(w, format, report.TimelineTable(timeline))
+		return
+	}
+	jsonResponse(w, timeline)
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+// issueWIP is an issue together with the WIP limits its workflow state
+// and assignee are over.
+type issueWIP struct {
+	*db.Issue
+	WIPViolations []db.WIPUsage `json:"wip_violations,omitempty"`
+}
+
+// issueWithWIP responds with an updated issue, reporting any WIP limits it
+// leaves exceeded. Limits that are not enforced only warn, so the update
+// has already succeeded.
+func (st *stores) issueWithWIP(w http.ResponseWriter, issue *db.Issue) {
+	violations, err := st.metricsRepo.WIPViolations(issue)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to check WIP limits: %v", err), http.StatusInternalServerError)
+		return
+	}
+	jsonResponse(w, issueWIP{Issue: issue, WIPViolations: violations})
+}
+
+// handleWIPMetrics reports the issues in each workflow state and in
+// progress for each assignee against the workspace's WIP limits, and which
+// limits are exceeded.
+func (s *Server) handleWIPMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	st := s.storesFor(r)
+	report, err := st.metricsRepo.WIP(workspaceParam(r))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to measure WIP: %v", err), http.StatusInternalServerError)
+		return
+	}
+	jsonResponse(w, report)
+}
+
+// handleWorkspaceColumns lists the board columns of a workspace with the
+// issues in each and its WIP limit.
+func (s *Server) handleWorkspaceColumns(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	st := s.storesFor(r)
+	workspaceID := r.PathValue("id")
+	if _, ok := st.workspaceSettings(w, workspaceID); !ok {
+		return
+	}
+	report, err := st.metricsRepo.WIP(workspaceID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to measure WIP: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	usage := make(map[string]db.WIPUsage, len(report.Statuses))
+	for _, u := range report.Statuses {
+		usage[u.Key] = u
+	}
+	columns := defaultColumns()
+	for i := range columns {
+		u := usage[columns[i].ID]
+		columns[i].Count = u.Count
+		columns[i].WIPLimit = u.Limit
+		columns[i].Exceeded = u.Exceeded()
+	}
+	jsonResponse(w, columns)
+}
@@ -0,0 +1,61 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Instrumentation names the spans Pulse creates.
+const Instrumentation = "github.com/pulse/pm"
+
+// NewTracerProvider exports the spans of the named service to an
+// OpenTelemetry collector using OTLP over HTTP. endpoint is the collector's
+// base URL, e.g. http://localhost:4318, or its full traces URL. Export
+// failures are logged to logger. Shut the provider down to flush the spans
+// still pending.
+func NewTracerProvider(ctx context.Context, service, endpoint string, logger *slog.Logger) (*sdktrace.TracerProvider, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if !strings.HasSuffix(endpoint, "/v1/traces") {
+		endpoint = strings.TrimRight(endpoint, "/") + "/v1/traces"
+	}
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logger.Warn("trace export failed", "error", err)
+	}))
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", service))),
+	), nil
+}
+
+// StartSpan starts a child of the span in ctx using the same provider. When
+// ctx is not being traced the span records nothing.
+func StartSpan(ctx context.Context, name string, kind trace.SpanKind) (context.Context, trace.Span) {
+	return trace.SpanFromContext(ctx).TracerProvider().Tracer(Instrumentation).
+		Start(ctx, name, trace.WithSpanKind(kind))
+}
+
+// EndSpan ends span, marking it failed when err is not nil.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+// key is a single keypress. Named keys set name; printable keys set r.
+type key struct {
+	name string
+	r    rune
+}
+
+// Named keys.
+const (
+	keyUp        = "up"
+	keyDown      = "down"
+	keyLeft      = "left"
+	keyRight     = "right"
+	keyEnter     = "enter"
+	keyEscape    = "esc"
+	keyBackspace = "backspace"
+	keyTab       = "tab"
+	keyBackTab   = "backtab"
+	keyCtrlC     = "ctrl-c"
+)
+
+var escapeSequences = map[string]string{
+	"\x1b[A": keyUp,
+	"\x1b[B": keyDown,
+	"\x1b[C": keyRight,
+	"\x1b[D": keyLeft,
+	"\x1bOA": keyUp,
+	"\x1bOB": keyDown,
+	"\x1bOC": keyRight,
+	"\x1bOD": keyLeft,
+	"\x1b[Z": keyBackTab,
+}
+
+// readKeys decodes keypresses from a terminal in raw mode and sends them
+// to keys until r fails.
+func readKeys(r io.Reader, keys chan<- key) {
+	buf := make([]byte, 256)
+	for {
+		n, err := r.Read(buf)
+		if err != nil {
+			close(keys)
+			return
+		}
+		for _, k := range parseKeys(buf[:n]) {
+			keys <- k
+		}
+	}
+}
+
+// parseKeys splits one read into keys. A lone ESC byte is the escape key;
+// ESC followed by more bytes is a sequence, unknown ones are dropped.
+func parseKeys(b []byte) []key {
+	var keys []key
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == 0x1b:
+			if len(b) == 1 {
+				keys = append(keys, key{name: keyEscape})
+				return keys
+			}
+			n := 2
+			if len(b) >= 3 && (b[1] == '[' || b[1] == 'O') {
+				n = 3
+			}
+			if name, ok := escapeSequences[string(b[:n])]; ok {
+				keys = append(keys, key{name: name})
+			} else if b[1] != '[' && b[1] != 'O' {
+				// ESC followed by an ordinary key: treat as escape, then the key.
+				keys = append(keys, key{name: keyEscape})
+				n = 1
+			}
+			b = b[n:]
+		case c == '\r' || c == '\n':
+			keys = append(keys, key{name: keyEnter})
+			b = b[1:]
+		case c == 0x7f || c == 0x08:
+			keys = append(keys, key{name: keyBackspace})
+			b = b[1:]
+		case c == '\t':
+			keys = append(keys, key{name: keyTab})
+			b = b[1:]
+		case c == 0x03:
+			keys = append(keys, key{name: keyCtrlC})
+			b = b[1:]
+		case c < 0x20:
+			b = b[1:]
+		default:
+			r, size := utf8.DecodeRune(b)
+			keys = append(keys, key{r: r})
+			b = b[size:]
+		}
+	}
+	return keys
+}
@@ -0,0 +1,286 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pulse/pm/internal/db"
+)
+
+const (
+	styleReset   = "\x1b[0m"
+	styleBold    = "\x1b[1m"
+	styleDim     = "\x1b[2m"
+	styleReverse = "\x1b[7m"
+)
+
+const helpLine = "c create  / search  j/k move  ←/→ column  n/p status  space assign  e estimate  D due  l label  enter open  v view  q quit"
+
+// priorityMarks are the one-letter priority markers shown on cards.
+var priorityMarks = map[int]string{0: "-", 1: "U", 2: "H", 3: "M", 4: "L"}
+
+// render redraws the whole screen.
+func (a *app) render() {
+	var lines []string
+
+	lines = append(lines, a.header())
+
+	bodyHeight := a.height - 3
+	switch {
+	case a.detail != nil:
+		lines = append(lines, a.renderDetail(bodyHeight)...)
+	case a.view == viewBoard:
+		lines = append(lines, a.renderBoard(bodyHeight)...)
+	default:
+		lines = append(lines, a.renderList(bodyHeight)...)
+	}
+	for len(lines) < a.height-2 {
+		lines = append(lines, "")
+	}
+
+	lines = append(lines, a.statusLine(), a.footer())
+
+	a.out.WriteString("\x1b[H")
+	for i, line := range lines {
+		a.out.WriteString(line)
+		a.out.WriteString(styleReset + "\x1b[K")
+		if i < len(lines)-1 {
+			a.out.WriteString("\r\n")
+		}
+	}
+	a.out.WriteString("\x1b[J")
+	a.out.Flush()
+}
+
+func (a *app) header() string {
+	live := styleDim + "○ offline" + styleReset
+	if a.live {
+		live = "● live"
+	}
+
+	text := fmt.Sprintf(" Pulse · %s · %d issues", a.opts.WorkspaceID, len(a.visible()))
+	if a.filter != "" {
+		text += fmt.Sprintf(" matching %q", a.filter)
+	}
+
+	gap := a.width - utf8.RuneCountInString(text) - 10
+	if gap < 1 {
+		gap = 1
+	}
+	return styleBold + text + styleReset + strings.Repeat(" ", gap) + live
+}
+
+func (a *app) statusLine() string {
+	if a.message == "" {
+		return ""
+	}
+	return " " + truncate(a.message, a.width-1)
+}
+
+func (a *app) footer() string {
+	if a.prompt != nil {
+		return fmt.Sprintf(" %s: %s█", a.prompt.label, string(a.prompt.value))
+	}
+	if a.detail != nil {
+		return styleDim + truncate(" esc close  n/p status  space assign  e estimate  D due  l label", a.width) + styleReset
+	}
+	return styleDim + truncate(" "+helpLine, a.width) + styleReset
+}
+
+// renderBoard lays out one column per status with the cursor in the
+// selected column.
+func (a *app) renderBoard(height int) []string {
+	columns := len(db.Statuses)
+	width := a.width / columns
+	if width < 8 {
+		width = 8
+	}
+
+	lines := make([]string, height)
+
+	cards := make([][]*db.Issue, columns)
+	for c := range db.Statuses {
+		cards[c] = a.columnIssues(c)
+	}
+
+	cursor := a.cursor()
+	for c, status := range db.Statuses {
+		title := fmt.Sprintf(" %s (%d)", strings.ToUpper(strings.ReplaceAll(status, "_", " ")), len(cards[c]))
+		cell := pad(truncate(title, width-1), width)
+		if c == a.column {
+			cell = styleBold + cell + styleReset
+		} else {
+			cell = styleDim + cell + styleReset
+		}
+		lines[0] += cell
+
+		offset := 0
+		if c == a.column && cursor >= height-1 {
+			offset = cursor - (height - 2)
+		}
+
+		for row := 1; row < height; row++ {
+			i := offset + row - 1
+			if i >= len(cards[c]) {
+				lines[row] += strings.Repeat(" ", width)
+				continue
+			}
+			issue := cards[c][i]
+			cell := pad(truncate(" "+cardText(issue), width-1), width-1) + " "
+			if c == a.column && i == cursor {
+				cell = styleReverse + cell + styleReset
+			}
+			lines[row] += cell
+		}
+	}
+
+	return lines
+}
+
+// renderList shows every visible issue as a table row.
+func (a *app) renderList(height int) []string {
+	lines := []string{styleBold + fmt.Sprintf(" %-9s %-12s %-3s %-4s %-14s %s", "KEY", "STATUS", "PRI", "EST", "ASSIGNEE", "TITLE") + styleReset}
+
+	issues := a.visible()
+	cursor := a.cursor()
+	offset := 0
+	if cursor >= height-1 {
+		offset = cursor - (height - 2)
+	}
+
+	for i := offset; i < len(issues) && len(lines) < height; i++ {
+		issue := issues[i]
+		line := fmt.Sprintf(" %-9s %-12s %-3s %-4s %-14s %s",
+			issue.Key,
+			issue.Status,
+			priorityMarks[issue.Priority],
+			estimateText(issue.Estimate),
+			truncate(issue.AssigneeID, 14),
+			issue.Title+labelText(issue.Labels),
+		)
+		line = pad(truncate(line, a.width), a.width)
+		if i == cursor {
+			line = styleReverse + line + styleReset
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// renderDetail shows the open issue with its description and comments.
+func (a *app) renderDetail(height int) []string {
+	issue := a.detail
+	width := a.width - 4
+
+	lines := []string{
+		"",
+		styleBold + "  [" + priorityMarks[issue.Priority] + "] " + truncate(issue.Title, width-4) + styleReset,
+		"  " + styleDim + issue.Key + styleReset,
+		"",
+		"  Status:    " + issue.Status,
+		"  Priority:  " + db.PriorityName(issue.Priority),
+		"  Assignee:  " + issue.AssigneeID,
+		"  Estimate:  " + strconv.Itoa(issue.Estimate),
+		"  Due:       " + dueText(issue.DueDate),
+		"  Labels:    " + strings.Join(issue.Labels, ", "),
+		"  Cycle:     " + issue.CycleID,
+		"",
+	}
+
+	for _, line := range wrap(issue.Description, width) {
+		lines = append(lines, "  "+line)
+	}
+
+	if len(a.comments) > 0 {
+		lines = append(lines, "", styleBold+"  Comments"+styleReset)
+	}
+	for _, c := range a.comments {
+		author := c.AuthorID
+		if author == "" {
+			author = "anonymous"
+		}
+		lines = append(lines, "", "  "+styleDim+author+" · "+c.CreatedAt.Local().Format("2006-01-02 15:04")+styleReset)
+		for _, line := range wrap(c.Body, width) {
+			lines = append(lines, "  "+line)
+		}
+	}
+
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	return lines
+}
+
+func cardText(issue *db.Issue) string {
+	text := priorityMarks[issue.Priority] + " "
+	if issue.Estimate > 0 {
+		text += strconv.Itoa(issue.Estimate) + " "
+	}
+	return text + issue.Title
+}
+
+func dueText(due *time.Time) string {
+	if due == nil {
+		return ""
+	}
+	return due.UTC().Format("2006-01-02")
+}
+
+func estimateText(e int) string {
+	if e == 0 {
+		return "-"
+	}
+	return strconv.Itoa(e)
+}
+
+func labelText(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	return "  [" + strings.Join(labels, ", ") + "]"
+}
+
+// truncate shortens s to at most n runes, marking the cut with an
+// ellipsis.
+func truncate(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:n-1]) + "…"
+}
+
+// pad right-pads s with spaces to n runes.
+func pad(s string, n int) string {
+	if c := utf8.RuneCountInString(s); c < n {
+		return s + strings.Repeat(" ", n-c)
+	}
+	return s
+}
+
+// wrap breaks text into lines of at most width runes at word boundaries.
+func wrap(text string, width int) []string {
+	var lines []string
+	for _, para := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			if line != "" && utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) > width {
+				lines = append(lines, line)
+				line = ""
+			}
+			if line != "" {
+				line += " "
+			}
+			line += word
+		}
+		lines = append(lines, truncate(line, width))
+	}
+	return lines
+}
@@ -0,0 +1,562 @@
+// Package tui implements a full-screen, keyboard-driven issue board for
+// terminals. It talks to a Pulse server over the REST API and follows the
+// server's event stream to stay current.
+package tui
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pulse/pm/internal/client"
+	"github.com/pulse/pm/internal/db"
+	"golang.org/x/term"
+)
+
+// Options configures a TUI session.
+type Options struct {
+	Client      *client.Client
+	WorkspaceID string
+	// User is the assignee ID used by "assign to me".
+	User string
+}
+
+type view int
+
+const (
+	viewBoard view = iota
+	viewList
+)
+
+// prompt is a single-line text input shown in the footer.
+type prompt struct {
+	label  string
+	value  []rune
+	submit func(string)
+}
+
+// app holds the TUI state. It is owned by the Run loop goroutine.
+type app struct {
+	opts   Options
+	out    *bufio.Writer
+	width  int
+	height int
+
+	issues   []*db.Issue
+	filter   string
+	view     view
+	column   int
+	selected string // ID of the selected issue
+
+	detail   *db.Issue
+	comments []*db.Comment
+	prompt   *prompt
+
+	message string
+	live    bool
+	quit    bool
+}
+
+// Run takes over the terminal until the user quits or ctx is cancelled.
+func Run(ctx context.Context, opts Options) error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return fmt.Errorf("pulse tui needs an interactive terminal")
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("failed to enter raw mode: %w", err)
+	}
+	defer term.Restore(fd, state)
+
+	a := &app{opts: opts, out: bufio.NewWriter(os.Stdout)}
+	a.resize()
+
+	// Alternate screen, hidden cursor.
+	a.out.WriteString("\x1b[?1049h\x1b[?25l")
+	defer func() {
+		a.out.WriteString("\x1b[?25h\x1b[?1049l")
+		a.out.Flush()
+	}()
+
+	if err := a.reload(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	keys := make(chan key, 16)
+	go readKeys(os.Stdin, keys)
+
+	events := make(chan client.Event, 64)
+	live := make(chan bool, 4)
+	go a.follow(ctx, events, live)
+
+	tick := time.NewTicker(time.Second)
+	defer tick.Stop()
+
+	for !a.quit {
+		a.render()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case k, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			a.handleKey(k)
+		case e := <-events:
+			a.apply(e)
+		case connected := <-live:
+			if connected && !a.live {
+				// Catch up on anything missed while disconnected.
+				if err := a.reload(); err != nil {
+					a.message = err.Error()
+				}
+			}
+			a.live = connected
+		case <-tick.C:
+			a.resize()
+		}
+	}
+
+	return nil
+}
+
+// follow keeps a subscription to the event stream open, reconnecting
+// with a short delay whenever it drops.
+func (a *app) follow(ctx context.Context, events chan<- client.Event, live chan<- bool) {
+	for {
+		live <- true
+		a.opts.Client.Subscribe(ctx, a.opts.WorkspaceID, func(e client.Event) {
+			events <- e
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		live <- false
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(3 * time.Second):
+		}
+	}
+}
+
+func (a *app) resize() {
+	w, h, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || w <= 0 || h <= 0 {
+		w, h = 80, 24
+	}
+	a.width, a.height = w, h
+}
+
+// reload fetches every issue in the workspace.
+func (a *app) reload() error {
+	issues, err := a.opts.Client.ListIssues(client.IssueFilter{WorkspaceID: a.opts.WorkspaceID})
+	if err != nil {
+		return err
+	}
+	a.issues = issues
+	return nil
+}
+
+// apply folds a server event into the local state.
+func (a *app) apply(e client.Event) {
+	switch e.Type {
+	case "issue.created", "issue.updated":
+		if e.Issue != nil {
+			a.upsert(e.Issue)
+		}
+	case "issue.deleted":
+		a.remove(e.IssueID)
+	case "comment.created":
+		if a.detail != nil && e.Comment != nil && e.IssueID == a.detail.ID {
+			for _, c := range a.comments {
+				if c.ID == e.Comment.ID {
+					return
+				}
+			}
+			a.comments = append(a.comments, e.Comment)
+		}
+	}
+}
+
+func (a *app) upsert(issue *db.Issue) {
+	if a.detail != nil && a.detail.ID == issue.ID {
+		a.detail = issue
+	}
+	for i, existing := range a.issues {
+		if existing.ID == issue.ID {
+			a.issues[i] = issue
+			return
+		}
+	}
+	a.issues = append(a.issues, issue)
+}
+
+func (a *app) remove(id string) {
+	for i, issue := range a.issues {
+		if issue.ID == id {
+			a.issues = append(a.issues[:i], a.issues[i+1:]...)
+			break
+		}
+	}
+	if a.detail != nil && a.detail.ID == id {
+		a.detail = nil
+		a.message = "Issue was deleted"
+	}
+}
+
+// visible returns the issues matching the search filter, most urgent
+// first. Issues without a priority sort after low.
+func (a *app) visible() []*db.Issue {
+	filter := strings.ToLower(a.filter)
+
+	var result []*db.Issue
+	for _, issue := range a.issues {
+		if filter == "" || matches(issue, filter) {
+			result = append(result, issue)
+		}
+	}
+
+	rank := func(p int) int {
+		if p == 0 {
+			return 5
+		}
+		return p
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if rank(result[i].Priority) != rank(result[j].Priority) {
+			return rank(result[i].Priority) < rank(result[j].Priority)
+		}
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+	return result
+}
+
+func matches(issue *db.Issue, filter string) bool {
+	if strings.Contains(strings.ToLower(issue.Title), filter) || strings.Contains(strings.ToLower(issue.Key), filter) {
+		return true
+	}
+	for _, l := range issue.Labels {
+		if strings.Contains(strings.ToLower(l), filter) {
+			return true
+		}
+	}
+	return false
+}
+
+// columnIssues returns the visible issues in a board column.
+func (a *app) columnIssues(column int) []*db.Issue {
+	var result []*db.Issue
+	for _, issue := range a.visible() {
+		if issue.Status == db.Statuses[column] {
+			result = append(result, issue)
+		}
+	}
+	return result
+}
+
+// current returns the issues the cursor moves through in this view.
+func (a *app) current() []*db.Issue {
+	if a.view == viewBoard {
+		return a.columnIssues(a.column)
+	}
+	return a.visible()
+}
+
+// cursor returns the index of the selected issue in the current view,
+// falling back to the first issue when the selection is not visible.
+func (a *app) cursor() int {
+	for i, issue := range a.current() {
+		if issue.ID == a.selected {
+			return i
+		}
+	}
+	return 0
+}
+
+// selectedIssue returns the issue under the cursor, if any.
+func (a *app) selectedIssue() *db.Issue {
+	if a.detail != nil {
+		return a.detail
+	}
+	issues := a.current()
+	if len(issues) == 0 {
+		return nil
+	}
+	return issues[a.cursor()]
+}
+
+func (a *app) moveCursor(delta int) {
+	issues := a.current()
+	if len(issues) == 0 {
+		return
+	}
+	i := a.cursor() + delta
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(issues) {
+		i = len(issues) - 1
+	}
+	a.selected = issues[i].ID
+}
+
+func (a *app) moveColumn(delta int) {
+	a.column = (a.column + delta + len(db.Statuses)) % len(db.Statuses)
+	if issues := a.current(); len(issues) > 0 {
+		a.selected = issues[0].ID
+	}
+}
+
+func (a *app) handleKey(k key) {
+	if a.prompt != nil {
+		a.handlePromptKey(k)
+		return
+	}
+
+	a.message = ""
+
+	if a.detail != nil {
+		switch {
+		case k.name == keyEscape, k.name == keyEnter, k.r == 'q':
+			a.detail = nil
+			return
+		case k.name == keyCtrlC:
+			a.quit = true
+			return
+		}
+	}
+
+	switch {
+	case k.name == keyCtrlC, k.r == 'q':
+		a.quit = true
+	case k.name == keyEscape:
+		a.filter = ""
+	case k.r == 'j', k.name == keyDown:
+		a.moveCursor(1)
+	case k.r == 'k', k.name == keyUp:
+		a.moveCursor(-1)
+	case k.name == keyLeft, k.name == keyBackTab:
+		a.moveColumn(-1)
+	case k.name == keyRight, k.name == keyTab:
+		a.moveColumn(1)
+	case k.r == 'v':
+		if a.view == viewBoard {
+			a.view = viewList
+		} else {
+			a.view = viewBoard
+		}
+	case k.r == 'r':
+		if err := a.reload(); err != nil {
+			a.message = err.Error()
+		}
+	case k.r == 'c':
+		a.startCreate()
+	case k.r == '/':
+		a.prompt = &prompt{label: "Search", value: []rune(a.filter), submit: func(s string) {
+			a.filter = strings.TrimSpace(s)
+		}}
+	case k.r == 'n':
+		a.shiftStatus(1)
+	case k.r == 'p':
+		a.shiftStatus(-1)
+	case k.r == ' ':
+		a.assignToMe()
+	case k.r == 'e':
+		a.editEstimate()
+	case k.r == 'D':
+		a.editDueDate()
+	case k.r == 'l':
+		a.addLabel()
+	case k.name == keyEnter:
+		a.openDetail()
+	}
+}
+
+func (a *app) handlePromptKey(k key) {
+	p := a.prompt
+	switch {
+	case k.name == keyEscape, k.name == keyCtrlC:
+		a.prompt = nil
+	case k.name == keyEnter:
+		a.prompt = nil
+		p.submit(string(p.value))
+	case k.name == keyBackspace:
+		if len(p.value) > 0 {
+			p.value = p.value[:len(p.value)-1]
+		}
+	case k.name == "" && k.r != 0:
+		p.value = append(p.value, k.r)
+	}
+}
+
+// startCreate prompts for a title and creates an issue in the selected
+// board column, or the backlog in list view.
+func (a *app) startCreate() {
+	status := "backlog"
+	if a.view == viewBoard {
+		status = db.Statuses[a.column]
+	}
+
+	a.prompt = &prompt{label: "New issue in " + status, submit: func(title string) {
+		title = strings.TrimSpace(title)
+		if title == "" {
+			return
+		}
+		issue, err := a.opts.Client.CreateIssue(&db.Issue{
+			WorkspaceID: a.opts.WorkspaceID,
+			Title:       title,
+			Status:      status,
+		})
+		if err != nil {
+			a.message = err.Error()
+			return
+		}
+		a.upsert(issue)
+		a.selected = issue.ID
+		a.message = "Created " + issue.Key
+	}}
+}
+
+// shiftStatus moves the selected issue to the next or previous status.
+func (a *app) shiftStatus(delta int) {
+	issue := a.selectedIssue()
+	if issue == nil {
+		return
+	}
+
+	i := statusIndex(issue.Status) + delta
+	if i < 0 || i >= len(db.Statuses) {
+		return
+	}
+
+	updated, err := a.opts.Client.MoveIssue(issue.ID, db.Statuses[i])
+	if err != nil {
+		a.message = err.Error()
+		return
+	}
+	a.upsert(updated)
+	a.selected = updated.ID
+	if a.view == viewBoard && a.detail == nil {
+		a.column = i
+	}
+}
+
+// assignToMe assigns the selected issue to the configured user, or
+// unassigns it when it is already theirs.
+func (a *app) assignToMe() {
+	issue := a.selectedIssue()
+	if issue == nil {
+		return
+	}
+	if a.opts.User == "" {
+		a.message = "Set PULSE_USER or \"user\" in the config file to assign issues to yourself"
+		return
+	}
+
+	assignee := a.opts.User
+	if issue.AssigneeID == a.opts.User {
+		assignee = ""
+	}
+	a.update(issue, map[string]interface{}{"assignee_id": assignee})
+}
+
+func (a *app) editEstimate() {
+	issue := a.selectedIssue()
+	if issue == nil {
+		return
+	}
+
+	a.prompt = &prompt{label: "Estimate", value: []rune(strconv.Itoa(issue.Estimate)), submit: func(s string) {
+		estimate, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || estimate < 0 {
+			a.message = fmt.Sprintf("Invalid estimate %q", s)
+			return
+		}
+		a.update(issue, map[string]interface{}{"estimate": estimate})
+	}}
+}
+
+func (a *app) editDueDate() {
+	issue := a.selectedIssue()
+	if issue == nil {
+		return
+	}
+
+	a.prompt = &prompt{label: "Due (YYYY-MM-DD)", value: []rune(dueText(issue.DueDate)), submit: func(s string) {
+		s = strings.TrimSpace(s)
+		if s != "" {
+			if _, err := time.Parse("2006-01-02", s); err != nil {
+				a.message = fmt.Sprintf("Invalid due date %q", s)
+				return
+			}
+		}
+		a.update(issue, map[string]interface{}{"due_date": s})
+	}}
+}
+
+func (a *app) addLabel() {
+	issue := a.selectedIssue()
+	if issue == nil {
+		return
+	}
+
+	a.prompt = &prompt{label: "Add label", submit: func(s string) {
+		label := strings.TrimSpace(s)
+		if label == "" {
+			return
+		}
+		for _, l := range issue.Labels {
+			if l == label {
+				return
+			}
+		}
+		labels := append(append([]string{}, issue.Labels...), label)
+		a.update(issue, map[string]interface{}{"labels": labels})
+	}}
+}
+
+func (a *app) update(issue *db.Issue, fields map[string]interface{}) {
+	updated, err := a.opts.Client.UpdateIssue(issue.ID, fields)
+	if err != nil {
+		a.message = err.Error()
+		return
+	}
+	a.upsert(updated)
+}
+
+func (a *app) openDetail() {
+	issue := a.selectedIssue()
+	if issue == nil {
+		return
+	}
+
+	comments, err := a.opts.Client.ListComments(issue.ID)
+	if err != nil {
+		a.message = err.Error()
+		return
+	}
+	a.detail = issue
+	a.comments = comments
+}
+
+func statusIndex(status string) int {
+	for i, s := range db.Statuses {
+		if s == status {
+			return i
+		}
+	}
+	return 0
+}